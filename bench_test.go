@@ -0,0 +1,132 @@
+package replayReader_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/replaytest"
+)
+
+//Number of fields of each kind in the benchmarked packets
+const benchFields = 256
+
+//Returns the first packet of a replay holding one packet with the data of p.
+func benchPacket(b *testing.B, p *replaytest.PacketBuilder, options ...replayReader.Option) *replayReader.Packet {
+	replay := replaytest.NewReplay().AddPacket(0, p).Replay(options...)
+	var packet replayReader.Packet
+	if !replay.Next(&packet) {
+		b.Fatal(replay.Error())
+	}
+	return &packet
+}
+
+//Reads the fields of packet with read, from the beginning of the packet every time.
+func benchRead(b *testing.B, packet *replayReader.Packet, size int, read func(p *replayReader.Packet) error) {
+	b.SetBytes(int64(size * benchFields))
+	b.ReportAllocs()
+	for b.Loop() {
+		packet.Data.Seek(0, io.SeekStart)
+		for range benchFields {
+			if err := read(packet); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkReadShort(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for i := range benchFields {
+		p.Short(int16(i))
+	}
+	benchRead(b, benchPacket(b, p), 2, func(p *replayReader.Packet) error {
+		_, err := p.ReadShort()
+		return err
+	})
+}
+
+func BenchmarkReadInt(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for i := range benchFields {
+		p.Int(int32(i))
+	}
+	benchRead(b, benchPacket(b, p), 4, func(p *replayReader.Packet) error {
+		_, err := p.ReadInt()
+		return err
+	})
+}
+
+func BenchmarkReadIntLittleEndian(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for i := range benchFields {
+		p.Int(int32(i))
+	}
+	benchRead(b, benchPacket(b, p, replayReader.WithLittleEndian()), 4, func(p *replayReader.Packet) error {
+		_, err := p.ReadInt()
+		return err
+	})
+}
+
+func BenchmarkReadLong(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for i := range benchFields {
+		p.Long(int64(i))
+	}
+	benchRead(b, benchPacket(b, p), 8, func(p *replayReader.Packet) error {
+		_, err := p.ReadLong()
+		return err
+	})
+}
+
+func BenchmarkReadFloat(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for i := range benchFields {
+		p.Float(float32(i))
+	}
+	benchRead(b, benchPacket(b, p), 4, func(p *replayReader.Packet) error {
+		_, err := p.ReadFloat()
+		return err
+	})
+}
+
+func BenchmarkReadDouble(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for i := range benchFields {
+		p.Double(float64(i))
+	}
+	benchRead(b, benchPacket(b, p), 8, func(p *replayReader.Packet) error {
+		_, err := p.ReadDouble()
+		return err
+	})
+}
+
+func BenchmarkReadVarInt(b *testing.B) {
+	p := &replaytest.PacketBuilder{}
+	for range benchFields {
+		p.VarInt(1 << 20)
+	}
+	benchRead(b, benchPacket(b, p), 3, func(p *replayReader.Packet) error {
+		_, _, err := p.ReadVarInt()
+		return err
+	})
+}
+
+func BenchmarkNext(b *testing.B) {
+	builder := replaytest.NewReplay()
+	for i := range benchFields {
+		builder.AddPacket(i, replaytest.NewPacket(0x02).Long(int64(i)))
+	}
+	file := builder.Bytes()
+	b.SetBytes(int64(len(file)))
+	b.ReportAllocs()
+	for b.Loop() {
+		replay := replayReader.NewReplayFromBytes(file)
+		var packet replayReader.Packet
+		for replay.Next(&packet) {
+		}
+		if err := replay.Error(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
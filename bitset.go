@@ -0,0 +1,52 @@
+package replayReader
+
+import "encoding/binary"
+
+//BitSet is a set of bits, like the sections that have light data or the messages a chat acknowledgement covers.
+//Bit i is bit i%64 of the Long i/64.
+type BitSet []uint64
+
+//Reports whether bit i is set. Bits past the end of the set aren't.
+func (b BitSet) Get(i int) bool {
+	if i < 0 || i/64 >= len(b) {
+		return false
+	}
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+//Returns the number of bits the set holds, including unset ones.
+func (b BitSet) Len() int {
+	return len(b) * 64
+}
+
+//Reads a BitSet sent as a VarInt number of Longs followed by the Longs, like 1.17 and later do. Len: len bytes
+//The length is checked like the length of a byte array.
+func (p *Packet) ReadBitSet() (result BitSet, len int, err error) {
+	count, countLen, err := p.ReadVarInt()
+	if err != nil {
+		return nil, countLen, err
+	}
+	data, dataLen, err := p.readByteArray(count*8, "bitset length")
+	if err != nil {
+		return nil, countLen + dataLen, err
+	}
+	result = make(BitSet, count)
+	for i := range result {
+		result[i] = binary.BigEndian.Uint64(data[i*8:])
+	}
+	return result, countLen + dataLen, nil
+}
+
+//Reads a BitSet of a size known in advance, sent as bits/8 bytes rounded up, with bit i in bit i%8 of byte i/8.
+//Chat acknowledgements send their 20 bits this way. Len: len bytes
+func (p *Packet) ReadFixedBitSet(bits int) (result BitSet, len int, err error) {
+	data, dataLen, err := p.readByteArray((bits+7)/8, "bitset length")
+	if err != nil {
+		return nil, dataLen, err
+	}
+	result = make(BitSet, (bits+63)/64)
+	for i, b := range data {
+		result[i/8] |= uint64(b) << (i % 8 * 8)
+	}
+	return result, dataLen, nil
+}
@@ -0,0 +1,178 @@
+package replayReader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"unicode/utf16"
+)
+
+//PacketBuilder builds the data of a packet, in the encoding the Read methods of Packet decode.
+//Every Write method appends to the packet and returns the builder, so calls can be chained.
+//Its zero value is an empty packet.
+type PacketBuilder struct {
+	data []byte
+}
+
+//Returns a PacketBuilder whose packet starts with the packet ID id.
+func NewPacketBuilder(id int) *PacketBuilder {
+	b := &PacketBuilder{}
+	return b.WriteVarInt(id)
+}
+
+//Returns the data of the packet built so far.
+func (b *PacketBuilder) Bytes() []byte {
+	return bytes.Clone(b.data)
+}
+
+//Returns the length of the packet built so far.
+func (b *PacketBuilder) Len() int {
+	return len(b.data)
+}
+
+//Empties the packet, keeping its memory for the next one.
+func (b *PacketBuilder) Reset() {
+	b.data = b.data[:0]
+}
+
+//Returns a Packet with time holding the data built so far, so it can be read back.
+func (b *PacketBuilder) Packet(time int) Packet {
+	data := b.Bytes()
+	return Packet{Time: time, Len: len(data), Data: bytes.NewReader(data), data: data}
+}
+
+//Writes an unsigned byte to the packet. Len: 1 byte
+func (b *PacketBuilder) WriteuByte(v byte) *PacketBuilder {
+	b.data = append(b.data, v)
+	return b
+}
+
+//Writes a signed byte to the packet. Len: 1 byte
+func (b *PacketBuilder) WriteSignedByte(v int8) *PacketBuilder {
+	return b.WriteuByte(byte(v))
+}
+
+//Writes a short to the packet. Len: 2 bytes
+func (b *PacketBuilder) WriteShort(v int16) *PacketBuilder {
+	return b.WriteuShort(uint16(v))
+}
+
+//Writes an unsigned short to the packet. Len: 2 bytes
+func (b *PacketBuilder) WriteuShort(v uint16) *PacketBuilder {
+	b.data = binary.BigEndian.AppendUint16(b.data, v)
+	return b
+}
+
+//Writes an Integer to the packet. Len: 4 bytes
+func (b *PacketBuilder) WriteInt(v int32) *PacketBuilder {
+	b.data = binary.BigEndian.AppendUint32(b.data, uint32(v))
+	return b
+}
+
+//Writes a Long to the packet. Len: 8 bytes
+func (b *PacketBuilder) WriteLong(v int64) *PacketBuilder {
+	b.data = binary.BigEndian.AppendUint64(b.data, uint64(v))
+	return b
+}
+
+//Writes a Float to the packet. Len: 4 bytes
+func (b *PacketBuilder) WriteFloat(v float32) *PacketBuilder {
+	b.data = binary.BigEndian.AppendUint32(b.data, math.Float32bits(v))
+	return b
+}
+
+//Writes a Double-precision Float to the packet. Len: 8 bytes
+func (b *PacketBuilder) WriteDouble(v float64) *PacketBuilder {
+	b.data = binary.BigEndian.AppendUint64(b.data, math.Float64bits(v))
+	return b
+}
+
+//Writes a Boolean to the packet. Len: 1 byte
+func (b *PacketBuilder) WriteBool(v bool) *PacketBuilder {
+	if v {
+		return b.WriteuByte(1)
+	}
+	return b.WriteuByte(0)
+}
+
+//Writes a Variable-length Integer to the packet. Negative values take 5 bytes, like in Java.
+func (b *PacketBuilder) WriteVarInt(v int) *PacketBuilder {
+	b.data = binary.AppendUvarint(b.data, uint64(uint32(v)))
+	return b
+}
+
+//Writes a Variable-length Long to the packet. Negative values take 10 bytes, like in Java.
+func (b *PacketBuilder) WriteVarLong(v int64) *PacketBuilder {
+	b.data = binary.AppendUvarint(b.data, uint64(v))
+	return b
+}
+
+//Writes a ZigZag-encoded Variable-length Integer to the packet, see Packet.ReadZigZagVarInt.
+func (b *PacketBuilder) WriteZigZagVarInt(v int32) *PacketBuilder {
+	b.data = binary.AppendUvarint(b.data, uint64(uint32(v<<1^v>>31)))
+	return b
+}
+
+//Writes a ZigZag-encoded Variable-length Long to the packet, see Packet.ReadZigZagVarLong.
+func (b *PacketBuilder) WriteZigZagVarLong(v int64) *PacketBuilder {
+	b.data = binary.AppendVarint(b.data, v)
+	return b
+}
+
+//Writes a string to the packet, prefixed with its length in bytes as a VarInt.
+func (b *PacketBuilder) WriteString(v string) *PacketBuilder {
+	b.WriteVarInt(len(v))
+	b.data = append(b.data, v...)
+	return b
+}
+
+//Writes a string to the packet as big-endian UTF-16, prefixed with its number of code units as a Short.
+func (b *PacketBuilder) WriteString16(v string) *PacketBuilder {
+	units := utf16.Encode([]rune(v))
+	b.WriteShort(int16(len(units)))
+	for _, unit := range units {
+		b.WriteuShort(unit)
+	}
+	return b
+}
+
+//Writes a UUID to the packet. Len: 16 bytes
+func (b *PacketBuilder) WriteUUID(v UUID) *PacketBuilder {
+	b.data = append(b.data, v[:]...)
+	return b
+}
+
+//Writes a BitSet to the packet, as a VarInt number of Longs followed by the Longs. Len: len bytes
+func (b *PacketBuilder) WriteBitSet(v BitSet) *PacketBuilder {
+	b.WriteVarInt(len(v))
+	for _, long := range v {
+		b.WriteLong(int64(long))
+	}
+	return b
+}
+
+//Writes a block position to the packet, in the layout used by protocol. Len: 8 bytes
+func (b *PacketBuilder) WritePosition(v Position, protocol int) *PacketBuilder {
+	return b.WriteLong(packPosition(v, protocol))
+}
+
+//Writes an angle in degrees to the packet, rounded to the nearest 1/256 of a turn. Len: 1 byte
+func (b *PacketBuilder) WriteAngle(degrees float64) *PacketBuilder {
+	return b.WriteuByte(byte(int(math.Round(degrees * 256 / 360))))
+}
+
+//Writes a coordinate in blocks to the packet as a fixed-point Int in 1/32 blocks, rounded to the nearest. Len: 4 bytes
+func (b *PacketBuilder) WriteFixedPoint(v float64) *PacketBuilder {
+	return b.WriteInt(int32(math.Round(v * FixedPointScale)))
+}
+
+//Writes a position change in blocks to the packet as a fixed-point Byte in 1/32 blocks, rounded to the nearest. Len: 1 byte
+func (b *PacketBuilder) WriteFixedPointByte(v float64) *PacketBuilder {
+	return b.WriteSignedByte(int8(math.Round(v * FixedPointScale)))
+}
+
+//Writes a byte array to the packet, without a length. Use WriteVarInt first for arrays prefixed with one.
+func (b *PacketBuilder) WriteuByteArray(v []byte) *PacketBuilder {
+	b.data = append(b.data, v...)
+	return b
+}
@@ -0,0 +1,154 @@
+package replayReader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//ChatComponent is a JSON chat component, the formatted text of chat messages, titles, disconnect reasons and more.
+//The style fields are nil when the component doesn't set them, it then has the style of its parent.
+type ChatComponent struct {
+	Text          string          `json:"text"`
+	Translate     string          `json:"translate,omitempty"`
+	With          []ChatComponent `json:"with,omitempty"`
+	Extra         []ChatComponent `json:"extra,omitempty"`
+	Color         string          `json:"color,omitempty"`
+	Bold          *bool           `json:"bold,omitempty"`
+	Italic        *bool           `json:"italic,omitempty"`
+	Underlined    *bool           `json:"underlined,omitempty"`
+	Strikethrough *bool           `json:"strikethrough,omitempty"`
+	Obfuscated    *bool           `json:"obfuscated,omitempty"`
+	Insertion     string          `json:"insertion,omitempty"`
+	ClickEvent    *ClickEvent     `json:"clickEvent,omitempty"`
+	HoverEvent    *HoverEvent     `json:"hoverEvent,omitempty"`
+}
+
+//ClickEvent is what happens when a component is clicked, like open_url or run_command with Value.
+type ClickEvent struct {
+	Action string `json:"action"`
+	Value  string `json:"value"`
+}
+
+//HoverEvent is what is shown when the mouse is over a component, like show_text, show_item or show_entity.
+//Versions before 1.16 send Value, later ones Contents. They are kept as JSON, since what they hold depends on Action.
+type HoverEvent struct {
+	Action   string          `json:"action"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Contents json.RawMessage `json:"contents,omitempty"`
+}
+
+//Returns the text shown by a show_text hover event. ok is false for other actions.
+func (h *HoverEvent) Text() (text ChatComponent, ok bool) {
+	if h.Action != "show_text" {
+		return text, false
+	}
+	raw := h.Contents
+	if raw == nil {
+		raw = h.Value
+	}
+	return text, json.Unmarshal(raw, &text) == nil
+}
+
+//ChatError is returned by ReadChat for a string that isn't a JSON chat component.
+//Err is the error of the JSON decoder. It matches Err and ErrCorrupt.
+type ChatError struct {
+	Packet int
+	Offset int64
+	Err    error
+}
+
+func (e *ChatError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: invalid chat component: %v", e.Packet, e.Offset, e.Err)
+}
+
+func (e *ChatError) Unwrap() []error {
+	return []error{e.Err, ErrCorrupt}
+}
+
+//Reads a JSON chat component from the packet. Len: len bytes
+func (p *Packet) ReadChat() (result ChatComponent, len int, err error) {
+	message, length, err := p.ReadString()
+	if err != nil {
+		return result, length, err
+	}
+	result, err = ParseChat(message)
+	if err != nil {
+		return result, length, &ChatError{Packet: p.Index, Offset: p.errorOffset(length), Err: err}
+	}
+	return result, length, nil
+}
+
+//Parses a JSON chat component, like the Message of a chat event.
+func ParseChat(message string) (ChatComponent, error) {
+	var component ChatComponent
+	err := json.Unmarshal([]byte(message), &component)
+	return component, err
+}
+
+//Besides objects, components can be sent as plain strings, numbers and booleans, which are their text,
+//or as arrays, where the first element is the parent of the others.
+func (c *ChatComponent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return fmt.Errorf("empty chat component")
+	}
+	switch data[0] {
+	case '{':
+		type component ChatComponent
+		return json.Unmarshal(data, (*component)(c))
+	case '[':
+		var list []ChatComponent
+		err := json.Unmarshal(data, &list)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return fmt.Errorf("empty chat component list")
+		}
+		*c = list[0]
+		c.Extra = append(c.Extra, list[1:]...)
+		return nil
+	case '"':
+		*c = ChatComponent{}
+		return json.Unmarshal(data, &c.Text)
+	case 'n':
+		return fmt.Errorf("null chat component")
+	}
+	var value any
+	err := json.Unmarshal(data, &value)
+	*c = ChatComponent{Text: fmt.Sprint(value)}
+	return err
+}
+
+//Returns the text of the component and its children without formatting.
+//Translated components are shown as their key followed by their arguments, since the translations aren't known.
+func (c ChatComponent) PlainText() string {
+	var builder strings.Builder
+	c.writePlainText(&builder)
+	return builder.String()
+}
+
+//Returns the plain texts of the translation arguments of the component.
+func (c ChatComponent) Arguments() []string {
+	arguments := make([]string, len(c.With))
+	for i, argument := range c.With {
+		arguments[i] = argument.PlainText()
+	}
+	return arguments
+}
+
+func (c ChatComponent) writePlainText(builder *strings.Builder) {
+	builder.WriteString(c.Text)
+	if c.Translate != "" {
+		builder.WriteString(c.Translate)
+		for _, argument := range c.With {
+			builder.WriteByte(' ')
+			argument.writePlainText(builder)
+		}
+	}
+	for _, extra := range c.Extra {
+		extra.writePlainText(builder)
+	}
+}
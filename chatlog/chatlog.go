@@ -0,0 +1,142 @@
+//Package chatlog extracts the chat of a replay as timestamped lines, telling who sent each of them.
+package chatlog
+
+import (
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//Kind tells where a Line was shown.
+type Kind int
+
+const (
+	KindPlayer Kind = iota
+	KindSystem
+	KindActionBar
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindPlayer:
+		return "player"
+	case KindSystem:
+		return "system"
+	case KindActionBar:
+		return "action bar"
+	}
+	return "unknown"
+}
+
+//Line is a message shown at Time milliseconds.
+//For messages of players, Sender and SenderName tell who sent them, as far as the replay tells it,
+//and Text is the message without the name of the sender when it can be told apart.
+//Otherwise Text is the plain text of Component, which is what was shown.
+type Line struct {
+	Time       int
+	Kind       Kind
+	Sender     replayReader.UUID
+	SenderName string
+	Text       string
+	Component  replayReader.ChatComponent
+}
+
+//Returns the line the way the chat shows it, like <Steve> hello.
+func (l Line) String() string {
+	if l.Kind == KindPlayer && l.SenderName != "" {
+		return "<" + l.SenderName + "> " + l.Text
+	}
+	return l.Text
+}
+
+//ChatExtractor reads the chat lines of a replay one after the other.
+//The names of senders come from the tab list, which the server fills with Player Info packets.
+//Before 1.19 the server formats player messages itself, so the sender is also found in chat.type.text messages.
+type ChatExtractor struct {
+	events *events.EventReader
+	names  map[replayReader.UUID]string
+}
+
+//Returns a ChatExtractor reading replay, recorded with protocol, from its current position.
+func NewChatExtractor(replay *replayReader.Replay, protocol int) *ChatExtractor {
+	return &ChatExtractor{
+		events: events.NewEventReader(replay, protocol, events.ChatEvent{}, events.PlayerChatEvent{}, events.PlayerJoinEvent{}),
+		names:  make(map[replayReader.UUID]string),
+	}
+}
+
+//Returns the next chat line of the replay. It returns false at the end of the replay or after an error,
+//e.Error() tells which one happened.
+func (e *ChatExtractor) Next() (Line, bool) {
+	for {
+		event, ok := e.events.Next()
+		if !ok {
+			return Line{}, false
+		}
+		switch event := event.(type) {
+		case events.PlayerJoinEvent:
+			for _, player := range event.Players {
+				e.names[player.UUID] = player.Name
+			}
+		case events.ChatEvent:
+			return e.chatLine(event), true
+		case events.PlayerChatEvent:
+			return e.playerChatLine(event), true
+		}
+	}
+}
+
+//Returns the error that stopped Next, or nil if it got to the end of the replay.
+func (e *ChatExtractor) Error() error {
+	return e.events.Error()
+}
+
+//Reads every remaining chat line of replay, recorded with protocol.
+func Extract(replay *replayReader.Replay, protocol int) ([]Line, error) {
+	extractor := NewChatExtractor(replay, protocol)
+	var lines []Line
+	for {
+		line, ok := extractor.Next()
+		if !ok {
+			return lines, extractor.Error()
+		}
+		lines = append(lines, line)
+	}
+}
+
+func (e *ChatExtractor) chatLine(event events.ChatEvent) Line {
+	line := Line{Time: event.Time, Kind: Kind(event.Position), Sender: event.Sender}
+	line.Component, line.Text = parse(event.Message)
+	if line.Kind != KindPlayer {
+		return line
+	}
+	line.SenderName = e.names[event.Sender]
+	//The vanilla format of player messages, which older versions show as <name> message
+	if line.Component.Translate == "chat.type.text" && len(line.Component.With) == 2 {
+		arguments := line.Component.Arguments()
+		if line.SenderName == "" {
+			line.SenderName = arguments[0]
+		}
+		line.Text = arguments[1]
+	}
+	return line
+}
+
+func (e *ChatExtractor) playerChatLine(event events.PlayerChatEvent) Line {
+	line := Line{Time: event.Time, Kind: KindPlayer, Sender: event.Sender}
+	line.Component, line.Text = parse(event.Message)
+	line.SenderName = e.names[event.Sender]
+	if line.SenderName == "" {
+		_, line.SenderName = parse(event.Name)
+	}
+	return line
+}
+
+//Parses a JSON chat component, and returns it with its plain text.
+//Messages that aren't valid components are shown as they are.
+func parse(message string) (replayReader.ChatComponent, string) {
+	component, err := replayReader.ParseChat(message)
+	if err != nil {
+		return replayReader.ChatComponent{Text: message}, message
+	}
+	return component, component.PlainText()
+}
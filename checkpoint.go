@@ -0,0 +1,185 @@
+package replayReader
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//Cursor is a position between two packets of a Replay. It can be saved and used to continue reading from the same place later.
+//Packet is the Index of the next packet, Offset is the position of its header in the file.
+//Time is the Time of the packet before it.
+//Compressed tells, for ModeSetCompression replays, whether the packets after it have the compression framing.
+//It is nil while the Set Compression packet wasn't found and the login isn't over.
+type Cursor struct {
+	Packet     int   `json:"packet"`
+	Offset     int64 `json:"offset"`
+	Time       int   `json:"time"`
+	Compressed *bool `json:"compressed,omitempty"`
+}
+
+//Returns the Cursor pointing at the packet the next Next() returns.
+func (r *Replay) Cursor() Cursor {
+	cursor := Cursor{Packet: r.packets, Offset: r.offset, Time: r.lastTime}
+	if r.compressed != compressionUnknown {
+		compressed := r.compressed == compressionOn
+		cursor.Compressed = &compressed
+	}
+	return cursor
+}
+
+//Makes the Replay continue reading from cursor. It only works if the replay file is an io.Seeker.
+//The file has to be the same one the Cursor was taken from, the Replay can be a new one.
+func (r *Replay) SeekCursor(cursor Cursor) error {
+	compressed := r.compressed
+	err := r.rewind()
+	if err != nil {
+		return err
+	}
+	//Packets after the start are in the same part of the login as when the Cursor was taken.
+	//Cursors saved without it only know it if they were taken from this Replay.
+	switch {
+	case cursor.Packet == 0:
+	case cursor.Compressed != nil && *cursor.Compressed:
+		r.compressed = compressionOn
+	case cursor.Compressed != nil:
+		r.compressed = compressionOff
+	default:
+		r.compressed = compressed
+	}
+	_, err = r.replayFile.(io.Seeker).Seek(cursor.Offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	r.packets = cursor.Packet
+	r.offset = cursor.Offset
+	r.lastTime = cursor.Time
+	return nil
+}
+
+//Returns the Cursor as an opaque string.
+func (c Cursor) Token() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+//Parses a token made by Cursor.Token.
+func ParseCursor(token string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+//Checkpoint is the content of a checkpoint file: where a job was in its Replay, and the state it had there.
+type Checkpoint struct {
+	Cursor string          `json:"cursor"`
+	State  json.RawMessage `json:"state"`
+	Saved  time.Time       `json:"saved"`
+}
+
+//Writes cursor and state, which is marshalled to JSON, to the checkpoint file at path.
+//The file is replaced atomically, so a crash while saving leaves the previous checkpoint intact.
+func SaveCheckpoint(path string, cursor Cursor, state any) error {
+	stateData, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(Checkpoint{Cursor: cursor.Token(), State: stateData, Saved: time.Now()})
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+//Reads the checkpoint file at path, unmarshals its state into state and returns its Cursor.
+//If there is no checkpoint file, the error matches os.ErrNotExist.
+func LoadCheckpoint(path string, state any) (Cursor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Cursor{}, err
+	}
+	var checkpoint Checkpoint
+	err = json.Unmarshal(data, &checkpoint)
+	if err != nil {
+		return Cursor{}, err
+	}
+	cursor, err := ParseCursor(checkpoint.Cursor)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if state != nil && len(checkpoint.State) > 0 {
+		err = json.Unmarshal(checkpoint.State, state)
+	}
+	return cursor, err
+}
+
+//Job processes every packet of a Replay, saving a checkpoint to CheckpointPath every Interval,
+//so it can continue where it was after being restarted.
+//State is the state of the job, a pointer to something that can be marshalled to JSON. It is saved with every checkpoint.
+//Process is called with every packet and should update State.
+type Job struct {
+	Replay         *Replay
+	CheckpointPath string
+	Interval       time.Duration
+	State          any
+	Process        func(p *Packet) error
+}
+
+//Runs the job. If there is a checkpoint, State is loaded from it and the Replay continues from its Cursor.
+//The checkpoint file is removed once the job is done.
+func (j *Job) Run() error {
+	cursor, err := LoadCheckpoint(j.CheckpointPath, j.State)
+	if err == nil {
+		err = j.Replay.SeekCursor(cursor)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	lastSave := time.Now()
+	var p Packet
+	for j.Replay.Next(&p) {
+		err = j.Process(&p)
+		if err != nil {
+			return err
+		}
+		if time.Since(lastSave) >= j.Interval {
+			err = SaveCheckpoint(j.CheckpointPath, j.Replay.Cursor(), j.State)
+			if err != nil {
+				return err
+			}
+			lastSave = time.Now()
+		}
+	}
+	if err := j.Replay.Error(); err != nil {
+		return err
+	}
+	err = os.Remove(j.CheckpointPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package replayReader
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+//Checksums can be stored in two ways:
+//In a sidecar file, which holds the checksum of every packet of an unchanged replay file.
+//Or in the replay itself, with checksum framing, where every header holds the checksum of its packet after the length.
+//Both use the CRC32 (IEEE) of the packet's data.
+
+//Magic number at the start of checksum sidecar files.
+var checksumMagic = [4]byte{'R', 'R', 'C', 'K'}
+
+//Version of the checksum sidecar format.
+const checksumVersion = 1
+
+//Returned by ReadChecksums when r doesn't hold a checksum sidecar file.
+var ErrInvalidChecksumFile = errors.New("not a checksum file")
+
+//Returns the checksum of the data of a packet.
+func Checksum(data []byte) uint32 {
+	return crc32.ChecksumIEEE(data)
+}
+
+//Makes Next verify every packet against sums, which were made by ComputeChecksums or read with ReadChecksums.
+//Packets without a checksum in sums are an error too, since they were added after the checksums were made.
+func WithChecksums(sums []uint32) Option {
+	return func(r *Replay) {
+		r.config.checksums = sums
+	}
+}
+
+//Makes Next read replays with checksum framing, where the header of every packet has a checksum after its length.
+//Every packet is verified against it.
+func WithChecksumFraming() Option {
+	return func(r *Replay) {
+		r.config.checksumFraming = true
+	}
+}
+
+//Checks data against the checksum of the current packet. framed is the checksum read from the header, if there is one.
+func (r *Replay) verifyChecksum(data []byte, framed uint32) error {
+	if !r.config.checksumFraming && r.config.checksums == nil {
+		return nil
+	}
+	actual := Checksum(data)
+	if r.config.checksumFraming && actual != framed {
+		return &ChecksumError{Packet: r.packets, Offset: r.offset, Expected: framed, Actual: actual}
+	}
+	if r.config.checksums != nil {
+		if r.packets >= len(r.config.checksums) {
+			return &ChecksumError{Packet: r.packets, Offset: r.offset, Actual: actual}
+		}
+		if expected := r.config.checksums[r.packets]; actual != expected {
+			return &ChecksumError{Packet: r.packets, Offset: r.offset, Expected: expected, Actual: actual}
+		}
+	}
+	return nil
+}
+
+//Reads every packet of the replay file in r and returns their checksums.
+func ComputeChecksums(r io.Reader) ([]uint32, error) {
+	replay := NewReplayFromReader(r)
+	var sums []uint32
+	var p Packet
+	for replay.Next(&p) {
+		sums = append(sums, Checksum(p.Bytes()))
+	}
+	return sums, replay.Error()
+}
+
+//Writes sums to w as a checksum sidecar file.
+func WriteChecksums(w io.Writer, sums []uint32) error {
+	buffered := bufio.NewWriter(w)
+	buffered.Write(checksumMagic[:])
+	buffered.WriteByte(checksumVersion)
+	binary.Write(buffered, binary.BigEndian, uint32(len(sums)))
+	err := binary.Write(buffered, binary.BigEndian, sums)
+	if err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+//Reads a checksum sidecar file written by WriteChecksums.
+func ReadChecksums(r io.Reader) ([]uint32, error) {
+	header := make([]byte, 9)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	if [4]byte(header[:4]) != checksumMagic || header[4] != checksumVersion {
+		return nil, ErrInvalidChecksumFile
+	}
+	count := binary.BigEndian.Uint32(header[5:])
+	sums := make([]uint32, 0, min(count, payloadChunk))
+	buffered := bufio.NewReader(r)
+	for i := uint32(0); i < count; i++ {
+		var sum uint32
+		err = binary.Read(buffered, binary.BigEndian, &sum)
+		if err == io.EOF {
+			return sums, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return sums, err
+		}
+		sums = append(sums, sum)
+	}
+	return sums, nil
+}
+
+//Copies the replay file in src to dst, adding the checksum of every packet to its header.
+//The result has to be read with WithChecksumFraming.
+func AddChecksumFraming(dst io.Writer, src io.Reader) error {
+	replay := NewReplayFromReader(src)
+	buffered := bufio.NewWriter(dst)
+	header := make([]byte, headerLen+4)
+	var p Packet
+	for replay.Next(&p) {
+		data := p.Bytes()
+		binary.BigEndian.PutUint32(header, uint32(p.Time))
+		binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+		binary.BigEndian.PutUint32(header[8:], Checksum(data))
+		buffered.Write(header)
+		_, err := buffered.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+	if err := replay.Error(); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
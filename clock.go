@@ -0,0 +1,147 @@
+package replayReader
+
+import (
+	"sync"
+	"time"
+)
+
+//Clock tells the time to the parts of the package that wait for it, like Player.
+//Using a manual or scaled Clock makes them deterministic in tests, or faster than real time.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+//Timer sends the time on C() once, after its duration has passed on its Clock.
+type Timer interface {
+	C() <-chan time.Time
+	//Stops the Timer. It returns false if the Timer already fired or was stopped.
+	Stop() bool
+}
+
+//RealClock is the wall clock of the system.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+//ManualClock only moves when Advance or Set is called. Its timers fire as soon as the clock passes them.
+//It is safe for concurrent use.
+type ManualClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+//Returns a ManualClock showing start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	timer := &manualTimer{clock: c, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- c.now
+		return timer
+	}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+//Moves the clock forward by d, firing every timer it passes.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+//Moves the clock to now, firing every timer it passes.
+func (c *ManualClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+	waiting := c.timers[:0]
+	for _, timer := range c.timers {
+		if !timer.deadline.After(now) {
+			timer.c <- now
+			continue
+		}
+		waiting = append(waiting, timer)
+	}
+	c.timers = waiting
+}
+
+//Returns the number of timers that haven't fired yet. Tests can use it to wait until something is waiting on the clock.
+func (c *ManualClock) Waiting() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+type manualTimer struct {
+	clock    *ManualClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+//ScaledClock runs factor times as fast as the Clock it is based on, starting from the time that Clock showed when it was made.
+type ScaledClock struct {
+	base   Clock
+	factor float64
+	start  time.Time
+}
+
+//Returns a Clock running factor times as fast as base. factor has to be more than 0.
+func NewScaledClock(base Clock, factor float64) *ScaledClock {
+	return &ScaledClock{base: base, factor: factor, start: base.Now()}
+}
+
+func (c *ScaledClock) Now() time.Time {
+	elapsed := c.base.Now().Sub(c.start)
+	return c.start.Add(time.Duration(float64(elapsed) * c.factor))
+}
+
+func (c *ScaledClock) NewTimer(d time.Duration) Timer {
+	return c.base.NewTimer(time.Duration(float64(d) / c.factor))
+}
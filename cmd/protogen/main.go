@@ -0,0 +1,365 @@
+//Protogen generates Go structs for the packets of a protocol version from a protocol.json of
+//PrismarineJS/minecraft-data, with Decode methods built on replayReader.Packet.DecodeProtocol.
+//Use it from a go:generate directive, one package per protocol version:
+//
+//	//go:generate go run github.com/bela333/replayReader/cmd/protogen -in minecraft-data/data/pc/1.20.1/protocol.json -protocol 763 -package v763 -out packets.go
+//
+//Fields of types Decode can't read, like switches, bitfields, NBT and entity metadata, end the generated struct:
+//the rest of the packet is kept in a Rest field, so the fields before them can still be used.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "protocol.json of minecraft-data to read")
+	out := flag.String("out", "", "Go file to write, standard output if empty")
+	protocol := flag.Int("protocol", 0, "protocol version of the packets")
+	packageName := flag.String("package", "packets", "name of the generated package")
+	state := flag.String("state", "play", "connection state of the packets: handshaking, status, login, configuration or play")
+	direction := flag.String("direction", "toClient", "direction of the packets: toClient or toServer")
+	flag.Parse()
+	if *in == "" || *protocol == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	source, err := generate(data, options{
+		source:      filepath.Base(*in),
+		protocol:    *protocol,
+		packageName: *packageName,
+		state:       *state,
+		direction:   *direction,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(source)
+		return
+	}
+	err = os.WriteFile(*out, source, 0o644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type options struct {
+	source      string
+	protocol    int
+	packageName string
+	state       string
+	direction   string
+}
+
+//packet is a packet of the state and direction, with the name of its type.
+type packet struct {
+	id       int
+	name     string
+	typeName string
+}
+
+type generator struct {
+	types   map[string]json.RawMessage
+	output  bytes.Buffer
+	structs bytes.Buffer
+}
+
+func generate(data []byte, options options) ([]byte, error) {
+	var file map[string]json.RawMessage
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{types: make(map[string]json.RawMessage)}
+	var global map[string]json.RawMessage
+	if raw, ok := file["types"]; ok {
+		err = json.Unmarshal(raw, &global)
+		if err != nil {
+			return nil, fmt.Errorf("types: %w", err)
+		}
+	}
+	var state map[string]struct {
+		Types map[string]json.RawMessage `json:"types"`
+	}
+	raw, ok := file[options.state]
+	if !ok {
+		return nil, fmt.Errorf("no state %q", options.state)
+	}
+	err = json.Unmarshal(raw, &state)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", options.state, err)
+	}
+	direction, ok := state[options.direction]
+	if !ok {
+		return nil, fmt.Errorf("no direction %q in state %q", options.direction, options.state)
+	}
+	for name, definition := range global {
+		g.types[name] = definition
+	}
+	for name, definition := range direction.Types {
+		g.types[name] = definition
+	}
+	packets, err := g.packets()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(&g.output, "// Code generated by protogen from %s; DO NOT EDIT.\n\n", options.source)
+	bound := strings.TrimPrefix(strings.ToLower(options.direction), "to") + "-bound"
+	fmt.Fprintf(&g.output, "//Package %s has the %s %s packets of protocol %d.\n", options.packageName, bound, options.state, options.protocol)
+	fmt.Fprintf(&g.output, "package %s\n\nimport \"github.com/bela333/replayReader\"\n\n", options.packageName)
+	fmt.Fprintf(&g.output, "//Protocol version of the packets.\nconst Protocol = %d\n\n", options.protocol)
+	g.output.WriteString("//Packet IDs.\nconst (\n")
+	for _, packet := range packets {
+		fmt.Fprintf(&g.output, "%sID = 0x%02X\n", goName(packet.name), packet.id)
+	}
+	g.output.WriteString(")\n\n")
+
+	for _, packet := range packets {
+		name := goName(packet.name)
+		err = g.container(name, g.types[packet.typeName], fmt.Sprintf("the %s packet", packet.name), true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", packet.name, err)
+		}
+		fmt.Fprintf(&g.structs, "//Decodes the packet from the position of p after its ID.\n")
+		fmt.Fprintf(&g.structs, "func (v *%s) Decode(p *replayReader.Packet) error {\nreturn p.DecodeProtocol(v, Protocol)\n}\n\n", name)
+	}
+	g.output.Write(g.structs.Bytes())
+
+	g.output.WriteString("//Decodes the packet with id from the position of p after its ID. It returns nil for IDs without a packet.\n")
+	g.output.WriteString("func Decode(p *replayReader.Packet, id int) (any, error) {\nswitch id {\n")
+	for _, packet := range packets {
+		name := goName(packet.name)
+		fmt.Fprintf(&g.output, "case %sID:\nv := &%s{}\nreturn v, v.Decode(p)\n", name, name)
+	}
+	g.output.WriteString("}\nreturn nil, nil\n}\n")
+	return format.Source(g.output.Bytes())
+}
+
+//Reads the packet IDs and names from the mapper of the packet type, and the types from its switch.
+func (g *generator) packets() ([]packet, error) {
+	var definition []json.RawMessage
+	err := json.Unmarshal(g.types["packet"], &definition)
+	if err != nil || len(definition) != 2 {
+		return nil, fmt.Errorf("no packet container")
+	}
+	var fields []struct {
+		Name string          `json:"name"`
+		Type json.RawMessage `json:"type"`
+	}
+	err = json.Unmarshal(definition[1], &fields)
+	if err != nil || len(fields) != 2 {
+		return nil, fmt.Errorf("packet container doesn't have a name and params")
+	}
+	var mapper []json.RawMessage
+	var mappings struct {
+		Mappings map[string]string `json:"mappings"`
+	}
+	if json.Unmarshal(fields[0].Type, &mapper) != nil || len(mapper) != 2 || json.Unmarshal(mapper[1], &mappings) != nil {
+		return nil, fmt.Errorf("packet name isn't a mapper")
+	}
+	var params []json.RawMessage
+	var cases struct {
+		Fields map[string]string `json:"fields"`
+	}
+	if json.Unmarshal(fields[1].Type, &params) != nil || len(params) != 2 || json.Unmarshal(params[1], &cases) != nil {
+		return nil, fmt.Errorf("packet params aren't a switch")
+	}
+	var packets []packet
+	for idString, name := range mappings.Mappings {
+		id, err := strconv.ParseInt(idString, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("packet ID %q: %w", idString, err)
+		}
+		typeName, ok := cases.Fields[name]
+		if !ok {
+			continue
+		}
+		packets = append(packets, packet{id: int(id), name: name, typeName: typeName})
+	}
+	sort.Slice(packets, func(i, j int) bool { return packets[i].id < packets[j].id })
+	return packets, nil
+}
+
+//Go types and mc tags of the native types Decode can read.
+var natives = map[string]struct{ goType, tag string }{
+	"varint":     {"int32", "varint"},
+	"varlong":    {"int64", "varlong"},
+	"u8":         {"uint8", ""},
+	"i8":         {"int8", ""},
+	"u16":        {"uint16", ""},
+	"i16":        {"int16", ""},
+	"i32":        {"int32", ""},
+	"i64":        {"int64", ""},
+	"f32":        {"float32", ""},
+	"f64":        {"float64", ""},
+	"bool":       {"bool", ""},
+	"string":     {"string", ""},
+	"UUID":       {"replayReader.UUID", ""},
+	"position":   {"replayReader.Position", ""},
+	"restBuffer": {"[]byte", "rest"},
+}
+
+//unsupported is returned for types Decode can't read.
+type unsupported struct {
+	description string
+}
+
+func (e *unsupported) Error() string {
+	return "unsupported type " + e.description
+}
+
+//Writes the struct called name for the container definition, described as what.
+//For packets, a field of a type that can't be generated ends the struct with a Rest field, otherwise it is an error.
+func (g *generator) container(name string, definition json.RawMessage, what string, packet bool) error {
+	var container []json.RawMessage
+	var fields []struct {
+		Name      string          `json:"name"`
+		Type      json.RawMessage `json:"type"`
+		Anonymous bool            `json:"anon"`
+	}
+	if json.Unmarshal(definition, &container) != nil || len(container) != 2 || string(container[0]) != `"container"` ||
+		json.Unmarshal(container[1], &fields) != nil {
+		return &unsupported{description: string(definition)}
+	}
+	var body bytes.Buffer
+	for _, field := range fields {
+		fieldName := goName(field.Name)
+		goType, tag, err := g.fieldType(name+fieldName, field.Type, fmt.Sprintf("a part of %s of %s", fieldName, name))
+		if err == nil && field.Anonymous {
+			err = &unsupported{description: "of anonymous field"}
+		}
+		if _, ok := err.(*unsupported); ok && packet {
+			fmt.Fprintf(&body, "//The rest of the packet, from %s on, which has an %v.\nRest []byte `mc:\"rest\"`\n", field.Name, err)
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if goType == "" {
+			continue
+		}
+		fmt.Fprintf(&body, "%s %s", fieldName, goType)
+		if tag != "" {
+			fmt.Fprintf(&body, " `mc:%q`", tag)
+		}
+		body.WriteByte('\n')
+	}
+	fmt.Fprintf(&g.structs, "//%s is %s.\ntype %s struct {\n%s}\n\n", name, what, name, body.Bytes())
+	return nil
+}
+
+//Returns the Go type and mc tag of a field. An empty Go type means the field isn't sent, like void.
+//Containers become structs called name, described as what.
+func (g *generator) fieldType(name string, raw json.RawMessage, what string) (goType string, tag string, err error) {
+	var typeName string
+	if json.Unmarshal(raw, &typeName) == nil {
+		if typeName == "void" {
+			return "", "", nil
+		}
+		if native, ok := natives[typeName]; ok {
+			return native.goType, native.tag, nil
+		}
+		definition, ok := g.types[typeName]
+		if !ok || string(definition) == `"native"` {
+			return "", "", &unsupported{description: typeName}
+		}
+		return g.fieldType(name, definition, what)
+	}
+
+	var compound []json.RawMessage
+	if json.Unmarshal(raw, &compound) != nil || len(compound) != 2 {
+		return "", "", &unsupported{description: string(raw)}
+	}
+	var kind string
+	json.Unmarshal(compound[0], &kind)
+	var args struct {
+		CountType string          `json:"countType"`
+		Type      json.RawMessage `json:"type"`
+	}
+	switch kind {
+	case "pstring", "buffer":
+		if json.Unmarshal(compound[1], &args) != nil || args.CountType != "varint" {
+			return "", "", &unsupported{description: kind + " without a VarInt length"}
+		}
+		if kind == "pstring" {
+			return "string", "", nil
+		}
+		return "[]byte", "", nil
+	case "option":
+		inner, tag, err := g.fieldType(name, compound[1], what)
+		if err != nil || inner == "" {
+			return "", "", err
+		}
+		return "*" + inner, tag, nil
+	case "array":
+		if json.Unmarshal(compound[1], &args) != nil || args.CountType != "varint" {
+			return "", "", &unsupported{description: "array without a VarInt count"}
+		}
+		inner, tag, err := g.fieldType(name, args.Type, what)
+		if err != nil || inner == "" {
+			return "", "", err
+		}
+		if inner == "[]byte" && tag == "" {
+			//Decode reads []byte itself, so nested byte arrays get an element type Decode treats as a slice
+			return "", "", &unsupported{description: "array of byte arrays"}
+		}
+		return "[]" + inner, tag, nil
+	case "mapper":
+		var mapper struct {
+			Type json.RawMessage `json:"type"`
+		}
+		if json.Unmarshal(compound[1], &mapper) != nil {
+			return "", "", &unsupported{description: "mapper"}
+		}
+		return g.fieldType(name, mapper.Type, what)
+	case "container":
+		err := g.container(name, raw, what, false)
+		if err != nil {
+			return "", "", err
+		}
+		return name, "", nil
+	}
+	return "", "", &unsupported{description: kind}
+}
+
+//Turns a name of minecraft-data, like spawn_entity or entityId, into an exported Go name.
+func goName(name string) string {
+	var builder strings.Builder
+	upper := true
+	for _, c := range name {
+		switch {
+		case c == '_' || c == '-' || c == ' ' || c == '.':
+			upper = true
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			if upper && c >= 'a' && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			if builder.Len() == 0 && c >= '0' && c <= '9' {
+				builder.WriteByte('X')
+			}
+			builder.WriteRune(c)
+			upper = false
+		}
+	}
+	if builder.Len() == 0 {
+		return "Field"
+	}
+	return builder.String()
+}
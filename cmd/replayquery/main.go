@@ -0,0 +1,127 @@
+//Replayquery lists the packets of a replay that match a query expression, see the query package. For example
+//
+//	replayquery 'name == "chat_message" && time > 60000' recording.mcpr
+//
+//prints the index, time, state, ID, name and length of every chat message after the first minute.
+//The fields of the events decoded from play packets can be used too, like entity_id in (12, 40) or block_state == 1.
+//The replay can be a .mcpr archive or a recording.tmcpr. Its protocol version is detected, unless -protocol is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/mcpr"
+	"github.com/bela333/replayReader/protocol"
+	"github.com/bela333/replayReader/query"
+)
+
+func main() {
+	version := flag.Int("protocol", 0, "protocol version the replay was recorded with, detected if 0")
+	count := flag.Bool("count", false, "only print the number of matching packets")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: replayquery [flags] expression replay")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	err := run(flag.Arg(0), flag.Arg(1), *version, *count)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//Prints the packets of the replay at path matching expression, or their number if count is set.
+//The fields of the events decoded from play packets, like entity_id, can be used in expression.
+func run(expression string, path string, version int, count bool) error {
+	q, err := query.Compile(expression)
+	if err != nil {
+		return err
+	}
+	replay, detected, closer, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer closer()
+	if version == 0 {
+		version, err = detected()
+		if err != nil {
+			return err
+		}
+	}
+
+	reader := protocol.NewReader(replay, version)
+	matches := 0
+	var p protocol.Packet
+	for reader.Next(&p) {
+		fields := query.ProtocolPacketFields(&p, version, eventFields(&p, version))
+		if !q.Match(fields) {
+			continue
+		}
+		matches++
+		if !count {
+			name := protocol.PacketName(version, p.State, p.ID)
+			fmt.Printf("%d\t%d\t%s\t0x%02X\t%s\t%d\n", p.Index, p.Time, p.State, p.ID, name, p.Len)
+		}
+	}
+	if err := reader.Error(); err != nil {
+		return err
+	}
+	if count {
+		fmt.Println(matches)
+	}
+	return nil
+}
+
+//Returns the Fields of the events decoded from p, which are only decoded when a field is looked up.
+//Packets that aren't play packets have none.
+func eventFields(p *protocol.Packet, version int) query.Fields {
+	var fields query.Fields
+	return query.FieldsFunc(func(name string) (any, bool) {
+		if p.State != protocol.StatePlay {
+			return nil, false
+		}
+		if fields == nil {
+			//Decoding starts after the ID, wherever the other fields left the packet
+			position, err := p.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, false
+			}
+			decoded, _ := events.DecodeAll(&p.Packet, events.Context{Header: events.Header{Time: p.Time, Packet: p.Index, ID: p.ID}, Protocol: version})
+			p.Seek(position, io.SeekStart)
+			values := make([]any, len(decoded))
+			for i, event := range decoded {
+				values[i] = event
+			}
+			fields = query.StructFields(values...)
+		}
+		return fields.Field(name)
+	})
+}
+
+//Opens the replay at path, an .mcpr archive or a replay file, and returns it with a function detecting its protocol version
+//and one closing it.
+func open(path string) (*replayReader.Replay, func() (int, error), func() error, error) {
+	if strings.EqualFold(filepath.Ext(path), ".mcpr") {
+		archive, err := mcpr.Open(path)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return archive.Replay, archive.DetectProtocol, archive.Close, nil
+	}
+	replay, err := replayReader.NewReplayFromFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return replay, replay.DetectProtocol, replay.Close, nil
+}
@@ -0,0 +1,57 @@
+package replayReader
+
+import (
+	"errors"
+	"fmt"
+)
+
+//PacketError is an error that happened while decoding a packet.
+//Packet is the number of the packet in the replay, starting at 0, and Time is its Time.
+type PacketError struct {
+	Packet int
+	Time   int
+	Err    error
+}
+
+func (e *PacketError) Error() string {
+	return fmt.Sprintf("packet %d at %dms: %v", e.Packet, e.Time, e.Err)
+}
+
+func (e *PacketError) Unwrap() error {
+	return e.Err
+}
+
+//ErrorReport lists the packets that couldn't be decoded by Collect.
+//Packets is the number of packets that were read, including the ones in Errors.
+type ErrorReport struct {
+	Packets int
+	Errors  []*PacketError
+}
+
+//Returns all errors in the report joined together, or nil if there are none.
+func (report *ErrorReport) Err() error {
+	if len(report.Errors) == 0 {
+		return nil
+	}
+	errs := make([]error, len(report.Errors))
+	for i, err := range report.Errors {
+		errs[i] = err
+	}
+	return errors.Join(errs...)
+}
+
+//Calls decode for every remaining packet in the Replay.
+//Errors returned by decode don't stop the iteration, they are collected into the returned ErrorReport instead.
+//Only errors of the Replay itself, like a broken file, stop it. They are returned as err.
+func (r *Replay) Collect(decode func(p *Packet) error) (report *ErrorReport, err error) {
+	report = &ErrorReport{}
+	var p Packet
+	for r.Next(&p) {
+		report.Packets++
+		decodeErr := decode(&p)
+		if decodeErr != nil {
+			report.Errors = append(report.Errors, &PacketError{Packet: p.Index, Time: p.Time, Err: decodeErr})
+		}
+	}
+	return report, r.Error()
+}
@@ -0,0 +1,45 @@
+package replayReader
+
+import (
+	"context"
+	"time"
+)
+
+//deadlineReader is a file whose reads can be interrupted, like a net.Conn or a pipe.
+type deadlineReader interface {
+	SetReadDeadline(t time.Time) error
+}
+
+//Works like Next, but gives up once ctx is done, and r.Error() then returns ctx.Err().
+//If the file of the Replay has a SetReadDeadline method, like a net.Conn, a read that is stuck waiting is interrupted too,
+//and the deadline of ctx is used as the deadline of the read. Other files are only checked before every packet.
+//A read that was interrupted leaves the file in the middle of a packet, so the Replay can't go on after that.
+func (r *Replay) NextContext(ctx context.Context, p *Packet) bool {
+	if err := ctx.Err(); err != nil {
+		r.error = err
+		return false
+	}
+	file, ok := r.replayFile.(deadlineReader)
+	if !ok || ctx.Done() == nil {
+		return r.Next(p)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		file.SetReadDeadline(deadline)
+	}
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		//A deadline in the past makes the read return right away
+		file.SetReadDeadline(time.Unix(1, 0))
+		close(interrupted)
+	})
+	success := r.Next(p)
+	if !stop() {
+		<-interrupted
+	}
+	file.SetReadDeadline(time.Time{})
+	if !success && ctx.Err() != nil {
+		r.error = ctx.Err()
+	}
+	return success
+}
@@ -0,0 +1,141 @@
+//Package cues extracts the sounds and particles of a replay, like explosions and firework boosts, to find moments in it.
+package cues
+
+import (
+	"math"
+	"strings"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/tracking"
+)
+
+//Filter tells which sounds and particles an Extractor keeps.
+//If Sounds, SoundIDs or Particles are set, only the sounds whose name is in Sounds or ID is in SoundIDs,
+//and the particles whose ID is in Particles, are kept. Otherwise every sound and particle is.
+//Names are compared without their minecraft: namespace, so explode matches minecraft:explode.
+//If Radius is more than 0, only what happens at most Radius blocks away from X, Y and Z is kept.
+//Start and End are the time window, in replay milliseconds. End 0 means the end of the replay.
+type Filter struct {
+	Sounds    []string
+	SoundIDs  []int
+	Particles []int
+	X, Y, Z   float64
+	Radius    float64
+	Start     int
+	End       int
+}
+
+func (f *Filter) inWindow(time int) bool {
+	return time >= f.Start && (f.End <= 0 || time <= f.End)
+}
+
+func (f *Filter) inRadius(x, y, z float64) bool {
+	if f.Radius <= 0 {
+		return true
+	}
+	return math.Sqrt((x-f.X)*(x-f.X)+(y-f.Y)*(y-f.Y)+(z-f.Z)*(z-f.Z)) <= f.Radius
+}
+
+func (f *Filter) any() bool {
+	return len(f.Sounds) == 0 && len(f.SoundIDs) == 0 && len(f.Particles) == 0
+}
+
+func (f *Filter) keepSound(event events.SoundEvent) bool {
+	if !f.inWindow(event.Time) || !f.inRadius(event.X, event.Y, event.Z) {
+		return false
+	}
+	if f.any() {
+		return true
+	}
+	name := strings.TrimPrefix(event.Name, "minecraft:")
+	for _, sound := range f.Sounds {
+		if event.Name != "" && strings.TrimPrefix(sound, "minecraft:") == name {
+			return true
+		}
+	}
+	for _, id := range f.SoundIDs {
+		if event.ID >= 0 && event.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) keepParticle(event events.ParticleEvent) bool {
+	if !f.inWindow(event.Time) || !f.inRadius(event.X, event.Y, event.Z) {
+		return false
+	}
+	if f.any() {
+		return true
+	}
+	for _, particle := range f.Particles {
+		if event.Particle == particle {
+			return true
+		}
+	}
+	return false
+}
+
+//Extractor keeps the sounds and particles that match its Filter.
+//Sounds played by an entity get the position the entity was at, which is known when Entities is set.
+//They are left out of a Filter with a Radius if it isn't.
+type Extractor struct {
+	Filter    Filter
+	Entities  *tracking.EntityTracker
+	Sounds    []events.SoundEvent
+	Particles []events.ParticleEvent
+}
+
+//Returns an Extractor keeping what matches filter. entities can be nil, see Extractor.
+func NewExtractor(filter Filter, entities *tracking.EntityTracker) *Extractor {
+	return &Extractor{Filter: filter, Entities: entities}
+}
+
+func (e *Extractor) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.SoundEvent) {
+		if event.EntityID >= 0 {
+			entity, ok := e.entity(event.EntityID)
+			if !ok && e.Filter.Radius > 0 {
+				return
+			}
+			event.X, event.Y, event.Z = entity.X, entity.Y, entity.Z
+		}
+		if e.Filter.keepSound(event) {
+			e.Sounds = append(e.Sounds, event)
+		}
+	})
+	events.Subscribe(bus, func(event events.ParticleEvent) {
+		if e.Filter.keepParticle(event) {
+			e.Particles = append(e.Particles, event)
+		}
+	})
+}
+
+func (e *Extractor) entity(id int) (tracking.Entity, bool) {
+	if e.Entities == nil {
+		return tracking.Entity{}, false
+	}
+	entity, ok := e.Entities.Entities[id]
+	if !ok {
+		return tracking.Entity{}, false
+	}
+	return *entity, true
+}
+
+//Reads replay, recorded with protocol, and returns the sounds and particles that match filter, ordered by Time.
+//It stops after the End of filter.
+func Extract(replay *replayReader.Replay, protocol int, filter Filter) ([]events.SoundEvent, []events.ParticleEvent, error) {
+	bus := events.NewBus(protocol)
+	entities := tracking.NewEntityTracker()
+	extractor := NewExtractor(filter, entities)
+	for _, tracker := range []tracking.Tracker{entities, extractor} {
+		tracker.Attach(bus)
+	}
+	end := -1
+	if filter.End > 0 {
+		end = filter.End
+	}
+	err := bus.RunUntil(replay, end)
+	return extractor.Sounds, extractor.Particles, err
+}
@@ -0,0 +1,331 @@
+package replayReader
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//DecodeError is returned by Decode when a field can't be read. Field is its path, like Entries[2].Name.
+type DecodeError struct {
+	Field string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s: %v", e.Field, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+//Fills the struct v points to from the packet, field by field, in the order they are declared.
+//The mc tag of a field tells how it is sent:
+//byte, ubyte, short, ushort, int, long, float, double, bool, varint, varlong, zigzag, zigzaglong,
+//string, string16, identifier, uuid, position, angle, fixed, fixedbyte, chat, bitset, bytes and rest.
+//VarInts are read with the 32-bit semantics of the protocol, like ReadVarInt32, so they can be negative.
+//Fields without a tag are read the way their Go type is: int8 as a byte, int16 as a short, int32 as an int, int64 as a long,
+//int as a VarInt, string as a string, and UUID, Position, ChatComponent and BitSet as themselves.
+//Pointers are optional fields prefixed with a Boolean, they are set to nil if the value isn't there.
+//Slices are prefixed with a VarInt count and their elements are read with the tag of the field.
+//The rest tag reads a slice up to the end of the packet instead, reading its elements the way their Go type is.
+//[]byte is read as a VarInt-prefixed byte array, or as the rest of the packet with the rest tag.
+//Structs are decoded field by field, fields tagged `mc:"-"` and unexported ones are left out.
+//Positions are read in the layout of 1.14 and later, use DecodeProtocol for older versions.
+func (p *Packet) Decode(v any) error {
+	return p.DecodeProtocol(v, ProtocolPositionXZY)
+}
+
+//Works like Decode, but reads positions the way protocol sends them.
+func (p *Packet) DecodeProtocol(v any, protocol int) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("replayReader: Decode needs a non-nil pointer, got %T", v)
+	}
+	d := packetDecoder{p: p, protocol: protocol}
+	return d.decode(value.Elem(), "", "")
+}
+
+type packetDecoder struct {
+	p        *Packet
+	protocol int
+}
+
+//decodeField is an exported field of a struct and its mc tag.
+type decodeField struct {
+	index  int
+	name   string
+	format string
+}
+
+//Fields of the struct types decoded so far, by type.
+var decodeFields sync.Map
+
+func fieldsOf(structType reflect.Type) []decodeField {
+	if fields, ok := decodeFields.Load(structType); ok {
+		return fields.([]decodeField)
+	}
+	var fields []decodeField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		format := field.Tag.Get("mc")
+		if !field.IsExported() || format == "-" {
+			continue
+		}
+		fields = append(fields, decodeField{index: i, name: field.Name, format: format})
+	}
+	decodeFields.Store(structType, fields)
+	return fields
+}
+
+var (
+	uuidType          = reflect.TypeFor[UUID]()
+	positionType      = reflect.TypeFor[Position]()
+	chatComponentType = reflect.TypeFor[ChatComponent]()
+	bitSetType        = reflect.TypeFor[BitSet]()
+)
+
+func (d *packetDecoder) decode(v reflect.Value, format string, path string) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		present, err := d.p.ReadBool()
+		if err != nil {
+			return &DecodeError{Field: fieldName(path), Err: err}
+		}
+		if !present {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem(), format, path)
+	case v.Kind() == reflect.Struct && format == "" && v.Type() != positionType && v.Type() != chatComponentType:
+		for _, field := range fieldsOf(v.Type()) {
+			fieldPath := field.name
+			if path != "" {
+				fieldPath = path + "." + field.name
+			}
+			err := d.decode(v.Field(field.index), field.format, fieldPath)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case v.Kind() == reflect.Slice && v.Type() != bitSetType && v.Type().Elem().Kind() != reflect.Uint8:
+		return d.decodeSlice(v, format, path)
+	}
+	if format == "" {
+		format = formatOf(v.Type())
+	}
+	value, err := d.read(format)
+	if err == nil {
+		err = assign(v, value, format)
+	}
+	if err != nil {
+		return &DecodeError{Field: fieldName(path), Err: err}
+	}
+	return nil
+}
+
+func (d *packetDecoder) decodeSlice(v reflect.Value, format string, path string) error {
+	if format == "rest" {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		for i := 0; d.p.Remaining() > 0; i++ {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			err := d.decode(v.Index(i), "", fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	count, _, err := d.p.ReadVarInt()
+	if err == nil {
+		//Every element takes at least a byte
+		err = d.p.checkLength(count, "array count")
+	}
+	if err != nil {
+		return &DecodeError{Field: fieldName(path), Err: err}
+	}
+	v.Set(reflect.MakeSlice(v.Type(), count, count))
+	for i := 0; i < count; i++ {
+		err := d.decode(v.Index(i), format, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
+
+//Returns the format of values of type t that don't have a tag.
+func formatOf(t reflect.Type) string {
+	switch t {
+	case uuidType:
+		return "uuid"
+	case positionType:
+		return "position"
+	case chatComponentType:
+		return "chat"
+	case bitSetType:
+		return "bitset"
+	}
+	switch t.Kind() {
+	case reflect.Int8:
+		return "byte"
+	case reflect.Uint8:
+		return "ubyte"
+	case reflect.Int16:
+		return "short"
+	case reflect.Uint16:
+		return "ushort"
+	case reflect.Int32:
+		return "int"
+	case reflect.Int64:
+		return "long"
+	case reflect.Int:
+		return "varint"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Bool:
+		return "bool"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		return "bytes"
+	}
+	return ""
+}
+
+//Reads a value in format. Integers are returned as int64, floats as float64.
+func (d *packetDecoder) read(format string) (any, error) {
+	p := d.p
+	switch format {
+	case "byte":
+		value, err := p.ReadByte()
+		return int64(value), err
+	case "ubyte":
+		value, err := p.ReaduByte()
+		return int64(value), err
+	case "short":
+		value, err := p.ReadShort()
+		return int64(value), err
+	case "ushort":
+		value, err := p.ReaduShort()
+		return int64(value), err
+	case "int":
+		value, err := p.ReadInt()
+		return int64(value), err
+	case "long":
+		return p.ReadLong()
+	case "varint":
+		value, _, err := p.ReadVarInt32()
+		return int64(value), err
+	case "varlong":
+		value, _, err := p.ReadVarLong64()
+		return value, err
+	case "zigzag":
+		value, _, err := p.ReadZigZagVarInt()
+		return int64(value), err
+	case "zigzaglong":
+		value, _, err := p.ReadZigZagVarLong()
+		return value, err
+	case "float":
+		value, err := p.ReadFloat()
+		return float64(value), err
+	case "double":
+		return p.ReadDouble()
+	case "fixed":
+		return p.ReadFixedPoint()
+	case "fixedbyte":
+		return p.ReadFixedPointByte()
+	case "angle":
+		return p.ReadAngle()
+	case "bool":
+		return p.ReadBool()
+	case "string":
+		value, _, err := p.ReadString()
+		return value, err
+	case "string16":
+		value, _, err := p.ReadString16()
+		return value, err
+	case "identifier":
+		value, _, err := p.ReadIdentifier()
+		return value, err
+	case "uuid":
+		return p.ReadUUID()
+	case "position":
+		return p.ReadPosition(d.protocol)
+	case "chat":
+		value, _, err := p.ReadChat()
+		return value, err
+	case "bitset":
+		value, _, err := p.ReadBitSet()
+		return value, err
+	case "bytes":
+		length, _, err := p.ReadVarInt()
+		if err != nil {
+			return nil, err
+		}
+		value, _, err := p.readByteArray(length, "array length")
+		return value, err
+	case "rest":
+		value, _, err := p.ReadRest()
+		return value, err
+	}
+	return nil, fmt.Errorf("replayReader: unknown mc tag %q", format)
+}
+
+//Stores a value returned by read into v.
+func assign(v reflect.Value, value any, format string) error {
+	mismatch := fmt.Errorf("replayReader: can't decode %s into %v", format, v.Type())
+	switch value := value.(type) {
+	case int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(value) {
+				return fmt.Errorf("%d overflows %v", value, v.Type())
+			}
+			v.SetInt(value)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value < 0 || v.OverflowUint(uint64(value)) {
+				return fmt.Errorf("%d overflows %v", value, v.Type())
+			}
+			v.SetUint(uint64(value))
+			return nil
+		}
+	case float64:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			v.SetFloat(value)
+			return nil
+		}
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(value)
+			return nil
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(value)
+			return nil
+		}
+	default:
+		result := reflect.ValueOf(value)
+		if result.Kind() == v.Kind() && result.Type().ConvertibleTo(v.Type()) {
+			v.Set(result.Convert(v.Type()))
+			return nil
+		}
+	}
+	return mismatch
+}
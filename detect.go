@@ -0,0 +1,124 @@
+package replayReader
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+//Returned by DetectProtocol when the start of the replay doesn't tell which protocol version it was recorded with.
+var ErrUnknownProtocol = errors.New("protocol version can't be detected")
+
+//Number of packets at the start of a replay DetectProtocol looks at.
+const detectSearch = 20
+
+//The versions DetectProtocol can tell apart without a handshake, oldest first, with the ID of their Join Game packet.
+var joinGameIDs = []struct{ protocol, id int }{
+	{47, 0x01},
+	{340, 0x23},
+	{578, 0x26},
+	{754, 0x24},
+	{763, 0x28},
+}
+
+//Returns the protocol version the replay was recorded with, found in its first packets.
+//A handshake packet tells it exactly. Without one, the layout of Login Success and the ID of the packet after it
+//are compared with the versions of 1.8.9, 1.12.2, 1.15.2, 1.16.5 and 1.20.1, so a version with the same packets,
+//like 1.16.4, is reported as the one of this list. If nothing matches, it returns ErrUnknownProtocol.
+//The replay is read from its start and is back at its start afterwards, so its file has to be an io.Seeker.
+//For .mcpr archives, the protocol in the metadata is more reliable, mcpr.Archive.DetectProtocol uses it first.
+func (r *Replay) DetectProtocol() (int, error) {
+	err := r.rewind()
+	if err != nil {
+		return 0, err
+	}
+	protocol, err := detectProtocol(r)
+	rewindErr := r.rewind()
+	if err == nil && rewindErr != nil {
+		return 0, rewindErr
+	}
+	return protocol, err
+}
+
+//Works like Replay.DetectProtocol, for a replay file that can't be seeked. It reads the start of file.
+func DetectProtocol(file io.Reader) (int, error) {
+	return detectProtocol(NewReplayFromReader(file))
+}
+
+func detectProtocol(r *Replay) (int, error) {
+	//The range of versions the Login Success packet fits, once it was found
+	oldest, newest := 0, -1
+	var p Packet
+	for i := 0; i < detectSearch && r.Next(&p); i++ {
+		id, _, err := p.ReadVarInt()
+		if err != nil {
+			continue
+		}
+		if newest >= oldest {
+			for _, version := range joinGameIDs {
+				if version.protocol >= oldest && version.protocol <= newest && version.id == id {
+					return version.protocol, nil
+				}
+			}
+			return 0, ErrUnknownProtocol
+		}
+		if protocol, ok := readHandshake(&p, id); ok {
+			return protocol, nil
+		}
+		if id == 0x02 {
+			oldest, newest = loginSuccessVersions(&p)
+		}
+	}
+	if err := r.Error(); err != nil {
+		return 0, err
+	}
+	return 0, ErrUnknownProtocol
+}
+
+//Reads the protocol version from p, if it is a Handshake packet.
+func readHandshake(p *Packet, id int) (int, bool) {
+	if id != 0x00 {
+		return 0, false
+	}
+	protocol, _, err := p.ReadVarInt()
+	if err != nil || protocol <= 0 {
+		return 0, false
+	}
+	address, _, err := p.ReadString()
+	if err != nil || len(address) == 0 || len(address) > 255 {
+		return 0, false
+	}
+	_, err = p.ReaduShort()
+	if err != nil {
+		return 0, false
+	}
+	nextState, _, err := p.ReadVarInt()
+	if err != nil || nextState < 1 || nextState > 3 || p.Remaining() != 0 {
+		return 0, false
+	}
+	return protocol, true
+}
+
+//Returns the range of versions a Login Success packet like p is sent by.
+//The UUID is a string until 1.16, and 1.19 added the properties of the player.
+func loginSuccessVersions(p *Packet) (oldest int, newest int) {
+	start, _ := p.Seek(0, io.SeekCurrent)
+	uuid, _, err := p.ReadString()
+	if err == nil && len(uuid) == 36 {
+		if _, err := ParseUUID(uuid); err == nil {
+			return 0, 734
+		}
+	}
+	p.Seek(start, io.SeekStart)
+	_, err = p.ReadUUID()
+	if err == nil {
+		_, _, err = p.ReadString()
+	}
+	if err != nil {
+		return 0, -1
+	}
+	if p.Remaining() == 0 {
+		return 735, 758
+	}
+	return 759, math.MaxInt
+}
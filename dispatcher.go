@@ -0,0 +1,72 @@
+package replayReader
+
+import "io"
+
+//Handler handles a packet with the packet ID id. p is positioned after the ID.
+type Handler func(id int, p *Packet) error
+
+//Dispatcher reads a replay and calls the handlers registered for the packet ID of every packet.
+//Handlers for the same ID are called in the order they were registered, each with the packet positioned after its ID,
+//so they don't have to know about each other. Packet IDs mean different things in different phases and protocols;
+//handlers that care have to tell them apart themselves.
+type Dispatcher struct {
+	handlers map[int][]Handler
+	others   []Handler
+	//If OnError is set, errors of handlers are passed to it and Run goes on. Otherwise Run stops with the error.
+	OnError func(err *PacketError)
+}
+
+//Returns a Dispatcher without handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[int][]Handler)}
+}
+
+//Makes d call handler for every packet with the packet ID id.
+func (d *Dispatcher) Handle(id int, handler Handler) {
+	d.handlers[id] = append(d.handlers[id], handler)
+}
+
+//Makes d call handler for every packet whose ID has no handler registered with Handle.
+func (d *Dispatcher) HandleOthers(handler Handler) {
+	d.others = append(d.others, handler)
+}
+
+//Reads every remaining packet of replay and calls its handlers.
+//A packet whose ID can't be read is reported like an error of a handler.
+func (d *Dispatcher) Run(replay *Replay) error {
+	var p Packet
+	for replay.Next(&p) {
+		id, _, err := p.ReadVarInt()
+		if err != nil {
+			if handled := d.fail(&p, err); handled != nil {
+				return handled
+			}
+			continue
+		}
+		handlers, ok := d.handlers[id]
+		if !ok {
+			handlers = d.others
+		}
+		start, _ := p.Seek(0, io.SeekCurrent)
+		for _, handler := range handlers {
+			p.Seek(start, io.SeekStart)
+			err = handler(id, &p)
+			if err != nil {
+				if handled := d.fail(&p, err); handled != nil {
+					return handled
+				}
+			}
+		}
+	}
+	return replay.Error()
+}
+
+//Handles an error of a handler. It returns the error if Run has to stop because of it.
+func (d *Dispatcher) fail(p *Packet, err error) error {
+	packetErr := &PacketError{Packet: p.Index, Time: p.Time, Err: err}
+	if d.OnError == nil {
+		return packetErr
+	}
+	d.OnError(packetErr)
+	return nil
+}
@@ -1,7 +1,230 @@
 package replayReader
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+//Every error the package returns because of a broken replay is one of the types below, or wraps one of them.
+//They can be told apart with errors.As, and most of them also match one of these values with errors.Is.
+//Packet is always the number of the packet in the replay, starting at 0.
+//Offset is always the position in the replay file the error was found at.
+//For packets that weren't returned by Next, it is the position in the packet's data instead.
 
 var (
-	VarIntTooBigError = errors.New("VarInt is too big")
+	//Matched by every error about the replay being broken, as opposed to the file not being readable.
+	//*ReplayError and *LimitError don't match it.
+	ErrCorrupt = errors.New("replay is corrupt")
+	//Matched by every *VarIntError.
+	ErrVarIntTooBig = errors.New("VarInt is too big")
+	//Returned by Next when the file ends in the middle of a packet. Matched by *FramingError.
+	ErrPacketTruncated = errors.New("packet is truncated")
+	//Matched by a *LimitError or *LengthError about the length of a packet.
+	ErrPacketTooLarge = errors.New("packet is too large")
+	//Matched by a *LimitError about the length of a string, and by an *InvalidStringError for a string that is too long.
+	ErrStringTooLong = errors.New("string is too long")
+	//Old name of ErrVarIntTooBig.
+	VarIntTooBigError = ErrVarIntTooBig
+	//Old name of ErrPacketTruncated.
+	ErrTruncatedPacket = ErrPacketTruncated
+	//Returned when a length read from the replay is negative or too big to be used. Matched by every *LengthError.
+	ErrInvalidLength = errors.New("invalid length")
+	//Returned when the Replay has to go back in its file, but the file isn't an io.Seeker.
+	ErrNotSeekable = errors.New("replay file is not seekable")
 )
+
+//FramingError is returned by Next when the time/length/data framing of a packet is broken.
+//Part is the part of the packet that was cut off, "header" or "data".
+//It matches ErrPacketTruncated, io.ErrUnexpectedEOF and ErrCorrupt.
+type FramingError struct {
+	Packet int
+	Offset int64
+	Part   string
+}
+
+func (e *FramingError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: %v in packet %s: %v", e.Packet, e.Offset, ErrPacketTruncated, e.Part, io.ErrUnexpectedEOF)
+}
+
+func (e *FramingError) Unwrap() []error {
+	return []error{ErrPacketTruncated, io.ErrUnexpectedEOF, ErrCorrupt}
+}
+
+//Turns an EOF in the middle of the current packet into a *FramingError.
+//part is the part of the packet that was cut off. Other errors are wrapped in a *ReplayError.
+func (r *Replay) truncated(err error, part string) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &FramingError{Packet: r.packets, Offset: r.offset, Part: part}
+	}
+	return &ReplayError{Packet: r.packets, Offset: r.offset, Stage: part, Err: err}
+}
+
+//ReplayError is returned by Next when the replay file itself can't be read, like when reading a network stream fails.
+//Stage is the part of the packet that was being read, "header" or "data", and Err is the error of the file.
+//It matches Err, so errors.Is and errors.As see through it.
+type ReplayError struct {
+	Packet int
+	Offset int64
+	Stage  string
+	Err    error
+}
+
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: reading packet %s: %v", e.Packet, e.Offset, e.Stage, e.Err)
+}
+
+func (e *ReplayError) Unwrap() error {
+	return e.Err
+}
+
+//VarIntError is returned when a VarInt or VarLong goes on for more bytes than it is allowed to.
+//Len is the number of bytes that were read. It matches ErrVarIntTooBig and ErrCorrupt.
+type VarIntError struct {
+	Packet int
+	Offset int64
+	Len    int
+}
+
+func (e *VarIntError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: %v, %d bytes long", e.Packet, e.Offset, ErrVarIntTooBig, e.Len)
+}
+
+func (e *VarIntError) Unwrap() []error {
+	return []error{ErrVarIntTooBig, ErrCorrupt}
+}
+
+//InvalidStringError is returned by ReadString in strict mode, when a string isn't allowed by the protocol.
+//Len is the length of the string in bytes. TooLong tells the string is too long, Reason says what is wrong with it.
+//It matches ErrCorrupt, and ErrStringTooLong if TooLong is set.
+type InvalidStringError struct {
+	Packet  int
+	Offset  int64
+	Len     int
+	TooLong bool
+	Reason  string
+}
+
+func (e *InvalidStringError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: invalid string of %d bytes: %s", e.Packet, e.Offset, e.Len, e.Reason)
+}
+
+func (e *InvalidStringError) Unwrap() []error {
+	if e.TooLong {
+		return []error{ErrStringTooLong, ErrCorrupt}
+	}
+	return []error{ErrCorrupt}
+}
+
+//LimitError is returned when a length read from the replay is over one of the Limits of the Replay.
+//Limit names the limit, Len is the length that was read and Max is the limit itself.
+//It matches ErrPacketTooLarge for the packet length and ErrStringTooLong for the length of a string.
+type LimitError struct {
+	Packet int
+	Offset int64
+	Limit  string
+	Len    int
+	Max    int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: %s %d is over the limit of %d", e.Packet, e.Offset, e.Limit, e.Len, e.Max)
+}
+
+func (e *LimitError) Unwrap() error {
+	switch e.Limit {
+	case "packet length":
+		return ErrPacketTooLarge
+	case "string length":
+		return ErrStringTooLong
+	}
+	return nil
+}
+
+//LengthError is returned when a length read from the replay can't be right,
+//because it is negative or longer than what is left of the packet or the file.
+//Field names the length, Len is its value and Remaining is the number of bytes that were left, or -1 if that is unknown.
+//It matches ErrInvalidLength and ErrCorrupt, io.ErrUnexpectedEOF if Len is longer than Remaining,
+//and ErrPacketTooLarge if it is the length of a packet.
+type LengthError struct {
+	Packet    int
+	Offset    int64
+	Field     string
+	Len       int64
+	Remaining int64
+}
+
+func (e *LengthError) Error() string {
+	if e.Len < 0 {
+		return fmt.Sprintf("packet %d at offset %d: %s: negative length %d", e.Packet, e.Offset, e.Field, e.Len)
+	}
+	return fmt.Sprintf("packet %d at offset %d: %s: length %d is longer than the %d bytes left", e.Packet, e.Offset, e.Field, e.Len, e.Remaining)
+}
+
+func (e *LengthError) Unwrap() []error {
+	errs := []error{ErrInvalidLength, ErrCorrupt}
+	if e.Len >= 0 {
+		errs = append(errs, io.ErrUnexpectedEOF)
+		if e.Field == "packet length" {
+			errs = append(errs, ErrPacketTooLarge)
+		}
+	}
+	return errs
+}
+
+//ChecksumError is returned by Next when the data of a packet doesn't match its checksum. It matches ErrCorrupt.
+type ChecksumError struct {
+	Packet   int
+	Offset   int64
+	Expected uint32
+	Actual   uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: checksum mismatch: expected %08x, got %08x", e.Packet, e.Offset, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error {
+	return ErrCorrupt
+}
+
+//CompressionError is returned by Next when a packet of a raw recording can't be decompressed.
+//Err is what went wrong. It matches Err and ErrCorrupt.
+type CompressionError struct {
+	Packet int
+	Offset int64
+	Err    error
+}
+
+func (e *CompressionError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: can't decompress packet: %v", e.Packet, e.Offset, e.Err)
+}
+
+func (e *CompressionError) Unwrap() []error {
+	return []error{e.Err, ErrCorrupt}
+}
+
+//ContainerError is returned when an entry of a replay archive is missing or can't be read.
+//Path is the path of the archive, Entry is the name of the entry in it, Err is what went wrong.
+type ContainerError struct {
+	Path  string
+	Entry string
+	Err   error
+}
+
+func (e *ContainerError) Error() string {
+	return fmt.Sprintf("%s: entry %s: %v", e.Path, e.Entry, e.Err)
+}
+
+func (e *ContainerError) Unwrap() error {
+	return e.Err
+}
+
+//Returns the position in the replay file of the byte that is consumed bytes before the current position in the packet.
+func (p *Packet) errorOffset(consumed int) int64 {
+	position, err := p.Data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return p.dataOffset + position - int64(consumed)
+}
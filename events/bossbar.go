@@ -0,0 +1,110 @@
+package events
+
+import "github.com/bela333/replayReader"
+
+func decodeBossBar(p *replayReader.Packet, context Context) (BossBarEvent, error) {
+	event := BossBarEvent{Header: context.Header}
+	var err error
+	event.UUID, err = p.ReadUUID()
+	if err != nil {
+		return event, err
+	}
+	event.Action, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	add := event.Action == BossBarAdd
+	if add || event.Action == BossBarTitle {
+		event.Title, _, err = p.ReadString()
+	}
+	if err == nil && (add || event.Action == BossBarHealth) {
+		var health float32
+		health, err = p.ReadFloat()
+		event.Health = float64(health)
+	}
+	if err == nil && (add || event.Action == BossBarStyle) {
+		event.Color, _, err = p.ReadVarInt()
+		if err == nil {
+			event.Division, _, err = p.ReadVarInt()
+		}
+	}
+	if err == nil && (add || event.Action == BossBarFlags) {
+		event.Flags, err = p.ReaduByte()
+	}
+	return event, err
+}
+
+func decodeWorldBorder(p *replayReader.Packet, context Context) (WorldBorderEvent, error) {
+	event := WorldBorderEvent{Header: context.Header}
+	ids := protocols[context.Protocol]
+	var err error
+	switch context.ID {
+	case ids.worldBorder:
+		event.Action, _, err = p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+	case ids.borderInit:
+		event.Action = BorderInitialize
+	case ids.borderCenter:
+		event.Action = BorderCenter
+	case ids.borderLerp:
+		event.Action = BorderLerp
+	case ids.borderSize:
+		event.Action = BorderSize
+	case ids.borderWarningTime:
+		event.Action = BorderWarningTime
+	case ids.borderWarningBlocks:
+		event.Action = BorderWarningBlocks
+	}
+	switch event.Action {
+	case BorderSize:
+		event.Diameter, err = p.ReadDouble()
+	case BorderLerp:
+		err = readBorderLerp(p, &event)
+	case BorderCenter:
+		err = readBorderCenter(p, &event)
+	case BorderInitialize:
+		err = readBorderCenter(p, &event)
+		if err == nil {
+			err = readBorderLerp(p, &event)
+		}
+		if err == nil {
+			event.PortalBoundary, _, err = p.ReadVarInt()
+		}
+		if err == nil {
+			event.WarningBlocks, _, err = p.ReadVarInt()
+		}
+		if err == nil {
+			event.WarningTime, _, err = p.ReadVarInt()
+		}
+	case BorderWarningTime:
+		event.WarningTime, _, err = p.ReadVarInt()
+	case BorderWarningBlocks:
+		event.WarningBlocks, _, err = p.ReadVarInt()
+	default:
+		return event, errSkip
+	}
+	return event, err
+}
+
+func readBorderCenter(p *replayReader.Packet, event *WorldBorderEvent) error {
+	var err error
+	event.X, err = p.ReadDouble()
+	if err == nil {
+		event.Z, err = p.ReadDouble()
+	}
+	return err
+}
+
+func readBorderLerp(p *replayReader.Packet, event *WorldBorderEvent) error {
+	var err error
+	event.OldDiameter, err = p.ReadDouble()
+	if err == nil {
+		event.Diameter, err = p.ReadDouble()
+	}
+	if err == nil {
+		event.Speed, _, err = p.ReadVarLong64()
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package events
+
+import (
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/protocol"
+)
+
+//decoder decodes events of one type. ids returns the play packet IDs the events are decoded from on protocol.
+type decoder struct {
+	ids    func(protocol int) []int
+	decode func(p *replayReader.Packet, context Context) (Event, error)
+}
+
+var decoders = map[reflect.Type]decoder{}
+
+//Registers the decoder of events of type E, so they can be subscribed to.
+//ids returns the play packet IDs E is decoded from on a protocol, decode is called with the packet positioned after its ID.
+//It should be called from init functions, it isn't safe for concurrent use.
+func RegisterDecoder[E Event](ids func(protocol int) []int, decode func(p *replayReader.Packet, context Context) (E, error)) {
+	decoders[reflect.TypeFor[E]()] = decoder{
+		ids: ids,
+		decode: func(p *replayReader.Packet, context Context) (Event, error) {
+			return decode(p, context)
+		},
+	}
+}
+
+//Bus reads a replay once and delivers the events decoded from it to everyone subscribed to them.
+//Events nobody is subscribed to aren't decoded.
+type Bus struct {
+	protocol    int
+	subscribers map[reflect.Type][]func(Event)
+	//If OnError is set, errors of decoders are passed to it and Run goes on. Otherwise Run stops with the error.
+	OnError func(err *replayReader.PacketError)
+}
+
+//Returns a Bus for a replay recorded with protocol.
+func NewBus(protocol int) *Bus {
+	return &Bus{protocol: protocol, subscribers: make(map[reflect.Type][]func(Event))}
+}
+
+//Makes bus call fn with every event of type E. E has to have a registered decoder.
+func Subscribe[E Event](bus *Bus, fn func(E)) {
+	eventType := reflect.TypeFor[E]()
+	bus.subscribers[eventType] = append(bus.subscribers[eventType], func(event Event) {
+		fn(event.(E))
+	})
+}
+
+//Reads every remaining packet of replay and delivers the events decoded from them.
+//Packets of the login and configuration phases are skipped, the events are decoded from the play phase.
+func (bus *Bus) Run(replay *replayReader.Replay) error {
+	return bus.RunUntil(replay, -1)
+}
+
+//Works like Run, but stops after the last packet whose Time is at most time. If time is negative, it doesn't stop.
+//The first packet after time is read from replay, but not delivered.
+func (bus *Bus) RunUntil(replay *replayReader.Replay, time int) error {
+	var eventTypes []reflect.Type
+	for eventType := range bus.subscribers {
+		eventTypes = append(eventTypes, eventType)
+	}
+	byID := typesByID(bus.protocol, eventTypes)
+
+	phase := PhaseTracker{Protocol: bus.protocol}
+	var p replayReader.Packet
+	for replay.Next(&p) {
+		if time >= 0 && p.Time > time {
+			return nil
+		}
+		id, _, err := p.ReadVarInt()
+		if err != nil {
+			if handled := bus.fail(&p, err); handled != nil {
+				return handled
+			}
+			continue
+		}
+		if !phase.Play(id) {
+			continue
+		}
+		context := Context{Header: Header{Time: p.Time, Packet: p.Index, ID: id}, Protocol: bus.protocol}
+		err = decodeEvents(&p, context, byID[id], func(event Event) {
+			for _, subscriber := range bus.subscribers[reflect.TypeOf(event)] {
+				subscriber(event)
+			}
+		}, bus.fail)
+		if err != nil {
+			return err
+		}
+	}
+	return replay.Error()
+}
+
+//Returns the types of eventTypes with a registered decoder, by the IDs they are decoded from on protocol.
+func typesByID(protocol int, eventTypes []reflect.Type) map[int][]reflect.Type {
+	byID := make(map[int][]reflect.Type)
+	for _, eventType := range eventTypes {
+		decoder, ok := decoders[eventType]
+		if !ok {
+			continue
+		}
+		for _, id := range decoder.ids(protocol) {
+			byID[id] = append(byID[id], eventType)
+		}
+	}
+	return byID
+}
+
+//Decodes the events of eventTypes from p, which is positioned after its ID, and passes them to deliver.
+//Errors are passed to fail, decoding stops if it returns one.
+func decodeEvents(p *replayReader.Packet, context Context, eventTypes []reflect.Type, deliver func(Event), fail func(p *replayReader.Packet, err error) error) error {
+	if len(eventTypes) == 0 {
+		return nil
+	}
+	start, _ := p.Seek(0, io.SeekCurrent)
+	for _, eventType := range eventTypes {
+		p.Seek(start, io.SeekStart)
+		event, err := decoders[eventType].decode(p, context)
+		if err == errSkip {
+			continue
+		}
+		if err != nil {
+			if handled := fail(p, err); handled != nil {
+				return handled
+			}
+			continue
+		}
+		deliver(event)
+	}
+	return nil
+}
+
+//Returns the IDs the events of type E are decoded from on protocol, or nil if E has no registered decoder.
+func IDs[E Event](protocol int) []int {
+	decoder, ok := decoders[reflect.TypeFor[E]()]
+	if !ok {
+		return nil
+	}
+	return decoder.ids(protocol)
+}
+
+//Decodes an event of type E from p, which has to be positioned after its ID, for code that reads packets itself.
+//context.ID has to be one of the IDs of E on context.Protocol.
+//It returns false if the packet doesn't hold such an event.
+func Decode[E Event](p *replayReader.Packet, context Context) (E, bool, error) {
+	var event E
+	decoder, ok := decoders[reflect.TypeFor[E]()]
+	if !ok {
+		return event, false, nil
+	}
+	decoded, err := decoder.decode(p, context)
+	if err == errSkip {
+		return event, false, nil
+	}
+	event, _ = decoded.(E)
+	return event, err == nil, err
+}
+
+//Decodes every event with a registered decoder from p, which has to be positioned after its ID, context.ID.
+//The events come in the order of the names of their types, like from an EventReader.
+//Events that can't be decoded are left out, and the first of their errors is returned.
+func DecodeAll(p *replayReader.Packet, context Context) ([]Event, error) {
+	var eventTypes []reflect.Type
+	for eventType := range decoders {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Slice(eventTypes, func(i, j int) bool {
+		return eventTypes[i].String() < eventTypes[j].String()
+	})
+	var decoded []Event
+	var first error
+	decodeEvents(p, context, typesByID(context.Protocol, eventTypes)[context.ID], func(event Event) {
+		decoded = append(decoded, event)
+	}, func(p *replayReader.Packet, err error) error {
+		if first == nil {
+			first = err
+		}
+		return nil
+	})
+	return decoded, first
+}
+
+//Handles a decoding error. It returns the error if Run has to stop because of it.
+func (bus *Bus) fail(p *replayReader.Packet, err error) error {
+	packetErr := &replayReader.PacketError{Packet: p.Index, Time: p.Time, Err: err}
+	if bus.OnError == nil {
+		return packetErr
+	}
+	bus.OnError(packetErr)
+	return nil
+}
+
+//PhaseTracker follows a recording from the login phase into the play phase.
+//Its zero value is at the start of a recording. Since 1.20.2 the configuration phase comes between them,
+//Protocol has to be set for its packets not to be taken for play packets.
+type PhaseTracker struct {
+	Protocol int
+	playing  bool
+	states   *protocol.StateTracker
+}
+
+//Reports whether the packet with id belongs to the play phase.
+//The login phase ends with Login Success, ID 0x02. Recordings without a login phase start playing right away,
+//which shows as an ID that doesn't exist in the login phase.
+func (t *PhaseTracker) Play(id int) bool {
+	if t.Protocol >= protocol.ProtocolConfiguration {
+		if t.states == nil {
+			t.states = protocol.NewStateTracker(t.Protocol)
+		}
+		return t.states.Packet(id) == protocol.StatePlay
+	}
+	if t.playing {
+		return true
+	}
+	if id == 0x02 {
+		t.playing = true
+		return false
+	}
+	if id > 0x04 {
+		t.playing = true
+		return true
+	}
+	return false
+}
@@ -0,0 +1,468 @@
+package events
+
+import (
+	"errors"
+	"io"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/nbt"
+)
+
+//First protocol versions that changed the chunk format: 1.9 packed block states into palettes, 1.13 stopped sending
+//the palette of sections with direct block states, 1.14 moved the light into its own packet, 1.15 sent the biomes
+//before the chunk data, 1.16.2 stopped packing entries across longs and changed Multi Block Change to one section,
+//and 1.18 sent the whole height of the world.
+const (
+	protocolChunkPalette  = 107
+	protocolDirectPalette = 393
+	protocolChunkLight    = 477
+	protocolBiomesFirst   = 573
+	protocolSectionLongs  = 751
+	protocolWorldHeight   = 757
+)
+
+//Returned for chunk data that can't be decoded, like a palette index that isn't in the palette.
+var ErrInvalidChunk = errors.New("events: invalid chunk data")
+
+//Number of blocks in a chunk section.
+const sectionBlocks = 16 * 16 * 16
+
+func decodeMultiBlockChange(p *replayReader.Packet, context Context) (MultiBlockChangeEvent, error) {
+	event := MultiBlockChangeEvent{Header: context.Header}
+	if context.Protocol >= protocolSectionLongs {
+		section, err := p.ReadLong()
+		if err != nil {
+			return event, err
+		}
+		sectionX, sectionY, sectionZ := int(section>>42), int(section<<44>>44), int(section<<22>>42)
+		//1.20 removed suppressing light updates
+		if context.Protocol < 763 {
+			_, err = p.ReadBool()
+			if err != nil {
+				return event, err
+			}
+		}
+		count, _, err := p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+		for i := 0; i < count; i++ {
+			record, _, err := p.ReadVarLong()
+			if err != nil {
+				return event, err
+			}
+			event.Changes = append(event.Changes, BlockChangeEvent{
+				Header:     context.Header,
+				X:          sectionX*16 + int(record>>8&15),
+				Y:          sectionY*16 + int(record&15),
+				Z:          sectionZ*16 + int(record>>4&15),
+				BlockState: int(record >> 12),
+			})
+		}
+		return event, nil
+	}
+	chunkX, err := p.ReadInt()
+	if err != nil {
+		return event, err
+	}
+	chunkZ, err := p.ReadInt()
+	if err != nil {
+		return event, err
+	}
+	count, _, err := p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	for i := 0; i < count; i++ {
+		horizontal, err := p.ReaduByte()
+		if err != nil {
+			return event, err
+		}
+		y, err := p.ReaduByte()
+		if err != nil {
+			return event, err
+		}
+		state, _, err := p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+		event.Changes = append(event.Changes, BlockChangeEvent{
+			Header:     context.Header,
+			X:          int(chunkX)*16 + int(horizontal>>4),
+			Y:          int(y),
+			Z:          int(chunkZ)*16 + int(horizontal&15),
+			BlockState: state,
+		})
+	}
+	return event, nil
+}
+
+//Reads the position of a chunk, as two Ints.
+func readChunkPos(p *replayReader.Packet) (int, int, error) {
+	x, err := p.ReadInt()
+	if err != nil {
+		return 0, 0, err
+	}
+	z, err := p.ReadInt()
+	return int(x), int(z), err
+}
+
+func decodeUnloadChunk(p *replayReader.Packet, context Context) (UnloadChunkEvent, error) {
+	event := UnloadChunkEvent{Header: context.Header}
+	var err error
+	if context.ID == protocols[context.Protocol].unloadChunk {
+		event.X, event.Z, err = readChunkPos(p)
+		return event, err
+	}
+	//1.8 unloads a chunk with a whole chunk without sections
+	event.X, event.Z, err = readChunkPos(p)
+	if err != nil {
+		return event, err
+	}
+	full, err := p.ReadBool()
+	if err != nil {
+		return event, err
+	}
+	mask, err := p.ReaduShort()
+	if err != nil || !full || mask != 0 {
+		return event, errorOr(err, errSkip)
+	}
+	return event, nil
+}
+
+//Returns err, or other if it is nil.
+func errorOr(err, other error) error {
+	if err != nil {
+		return err
+	}
+	return other
+}
+
+func decodeChunk(p *replayReader.Packet, context Context) (ChunkEvent, error) {
+	event := ChunkEvent{Header: context.Header, Full: true, Relative: context.Protocol >= protocolWorldHeight}
+	var err error
+	event.X, event.Z, err = readChunkPos(p)
+	if err != nil {
+		return event, err
+	}
+	if context.Protocol < protocolChunkPalette {
+		full, err := p.ReadBool()
+		if err != nil {
+			return event, err
+		}
+		mask, err := p.ReaduShort()
+		if err != nil {
+			return event, err
+		}
+		if full && mask == 0 {
+			return event, errSkip
+		}
+		event.Full = full
+		size, _, err := p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+		start := p.Position()
+		event.Sections, err = readLegacySections(p, int(mask))
+		if err != nil {
+			return event, err
+		}
+		//The light and the biomes follow the blocks
+		return event, p.Skip(size - (p.Position() - start))
+	}
+	mask := -1
+	if context.Protocol < protocolWorldHeight {
+		event.Full, err = p.ReadBool()
+		if err != nil {
+			return event, err
+		}
+		mask, _, err = p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+	}
+	if context.Protocol >= protocolChunkLight {
+		//Heightmaps
+		err = nbt.Skip(p, context.Protocol)
+		if err != nil {
+			return event, err
+		}
+	}
+	if context.Protocol >= protocolChunkLight && context.Protocol < protocolWorldHeight && event.Full {
+		err = skipBiomes(p, context.Protocol)
+		if err != nil {
+			return event, err
+		}
+	}
+	size, _, err := p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	start := p.Position()
+	switch {
+	case context.Protocol >= protocolWorldHeight:
+		for y := 0; p.Position()-start < size; y++ {
+			section, err := readSection(p, context.Protocol, y, false)
+			if err != nil {
+				return event, err
+			}
+			event.Sections = append(event.Sections, section)
+		}
+	case context.Protocol >= protocolChunkLight:
+		event.Sections, err = readSections(p, context.Protocol, mask, false)
+	default:
+		//Sky light is only sent in worlds with a sky, which the packet doesn't tell, so it is decoded with it first
+		biomes := 0
+		if event.Full {
+			biomes = 256
+		}
+		event.Sections, err = readSections(p, context.Protocol, mask, true)
+		if err != nil || p.Position()-start+biomes != size {
+			_, err = p.Seek(int64(start), io.SeekStart)
+			if err == nil {
+				event.Sections, err = readSections(p, context.Protocol, mask, false)
+			}
+		}
+	}
+	if err != nil {
+		return event, err
+	}
+	//Biomes before 1.14, and the block entities
+	return event, p.Skip(size - (p.Position() - start))
+}
+
+//Skips the biomes sent before the chunk data between 1.14 and 1.18.
+func skipBiomes(p *replayReader.Packet, protocol int) error {
+	//1.15 sends the biome of every 4×4×4 blocks, 1.16.2 as a VarInt array
+	if protocol < protocolSectionLongs {
+		//Before 1.15 they are part of the chunk data
+		if protocol < protocolBiomesFirst {
+			return nil
+		}
+		return p.Skip(1024 * 4)
+	}
+	count, _, err := p.ReadVarInt()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		_, _, err = p.ReadVarInt()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeChunkBulk(p *replayReader.Packet, context Context) (ChunkBulkEvent, error) {
+	event := ChunkBulkEvent{Header: context.Header}
+	sky, err := p.ReadBool()
+	if err != nil {
+		return event, err
+	}
+	count, _, err := p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	var masks []int
+	for i := 0; i < count; i++ {
+		chunk := ChunkEvent{Header: context.Header, Full: true}
+		chunk.X, chunk.Z, err = readChunkPos(p)
+		if err != nil {
+			return event, err
+		}
+		mask, err := p.ReaduShort()
+		if err != nil {
+			return event, err
+		}
+		masks = append(masks, int(mask))
+		event.Chunks = append(event.Chunks, chunk)
+	}
+	for i := range event.Chunks {
+		event.Chunks[i].Sections, err = readLegacySections(p, masks[i])
+		if err != nil {
+			return event, err
+		}
+		//Block light, sky light and biomes
+		light := 2048
+		if sky {
+			light *= 2
+		}
+		err = p.Skip(len(event.Chunks[i].Sections)*light + 256)
+		if err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
+
+//Reads the block states of the sections in mask from 1.8 chunk data, which are little endian, the block ID times 16 plus its metadata.
+func readLegacySections(p *replayReader.Packet, mask int) ([]ChunkSection, error) {
+	var sections []ChunkSection
+	for y := 0; y < 16; y++ {
+		if mask&(1<<y) == 0 {
+			continue
+		}
+		data, _, err := p.ReaduByteArray(sectionBlocks * 2)
+		if err != nil {
+			return sections, err
+		}
+		blocks := new([sectionBlocks]uint16)
+		for i := range blocks {
+			blocks[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+		}
+		sections = append(sections, ChunkSection{Y: y, Blocks: airOrBlocks(blocks)})
+	}
+	return sections, nil
+}
+
+//Reads the sections in mask from 1.9 to 1.17 chunk data. sky tells if they have sky light, which is only sent before 1.14.
+func readSections(p *replayReader.Packet, protocol int, mask int, sky bool) ([]ChunkSection, error) {
+	var sections []ChunkSection
+	for y := 0; y < 32; y++ {
+		if mask&(1<<y) == 0 {
+			continue
+		}
+		section, err := readSection(p, protocol, y, sky)
+		if err != nil {
+			return sections, err
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+//Reads a section at y from chunk data since 1.9.
+func readSection(p *replayReader.Packet, protocol int, y int, sky bool) (ChunkSection, error) {
+	section := ChunkSection{Y: y}
+	if protocol >= protocolChunkLight {
+		//Number of blocks that aren't air
+		_, err := p.ReadShort()
+		if err != nil {
+			return section, err
+		}
+	}
+	blocks, err := readPalettedContainer(p, protocol, sectionBlocks, 4, 8)
+	if err != nil {
+		return section, err
+	}
+	section.Blocks = blocks
+	if protocol >= protocolWorldHeight {
+		//Biomes of every 4×4×4 blocks
+		_, err = readPalettedContainer(p, protocol, 64, 1, 3)
+		return section, err
+	}
+	if protocol < protocolChunkLight {
+		light := 2048
+		if sky {
+			light *= 2
+		}
+		err = p.Skip(light)
+	}
+	return section, err
+}
+
+//Reads count entries packed into longs, looked up in their palette unless they are direct.
+//minBits is the fewest bits of entries with a palette, and maxBits the most, above which they are direct.
+//1.18 sends a bits of 0 for a single value, which leaves the result nil if it is 0.
+func readPalettedContainer(p *replayReader.Packet, protocol int, count int, minBits, maxBits int) (*[sectionBlocks]uint16, error) {
+	bits, err := p.ReaduByte()
+	if err != nil {
+		return nil, err
+	}
+	if bits == 0 && protocol >= protocolWorldHeight {
+		value, _, err := p.ReadVarInt()
+		if err != nil {
+			return nil, err
+		}
+		//The longs, of which there are none
+		_, _, err = p.ReadVarInt()
+		if err != nil || value == 0 {
+			return nil, err
+		}
+		entries := new([sectionBlocks]uint16)
+		for i := 0; i < count; i++ {
+			entries[i] = uint16(value)
+		}
+		return entries, nil
+	}
+	var palette []int
+	direct := int(bits) > maxBits
+	if !direct || protocol < protocolDirectPalette {
+		length, _, err := p.ReadVarInt()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < length; i++ {
+			entry, _, err := p.ReadVarInt()
+			if err != nil {
+				return nil, err
+			}
+			palette = append(palette, entry)
+		}
+	}
+	if !direct && int(bits) < minBits {
+		bits = byte(minBits)
+	}
+	if bits > 32 {
+		return nil, ErrInvalidChunk
+	}
+	length, _, err := p.ReadVarInt()
+	if err != nil {
+		return nil, err
+	}
+	var longs []uint64
+	for i := 0; i < length; i++ {
+		long, err := p.ReadLong()
+		if err != nil {
+			return nil, err
+		}
+		longs = append(longs, uint64(long))
+	}
+	entries := new([sectionBlocks]uint16)
+	mask := uint64(1)<<bits - 1
+	perLong := 64 / int(bits)
+	for i := 0; i < count; i++ {
+		var value uint64
+		if protocol < protocolSectionLongs {
+			//Entries go on in the next long
+			bit := i * int(bits)
+			long, offset := bit/64, bit%64
+			if long >= len(longs) {
+				return nil, ErrInvalidChunk
+			}
+			value = longs[long] >> offset
+			if offset+int(bits) > 64 {
+				if long+1 >= len(longs) {
+					return nil, ErrInvalidChunk
+				}
+				value |= longs[long+1] << (64 - offset)
+			}
+		} else {
+			long := i / perLong
+			if long >= len(longs) {
+				return nil, ErrInvalidChunk
+			}
+			value = longs[long] >> (i % perLong * int(bits))
+		}
+		value &= mask
+		if !direct {
+			if value >= uint64(len(palette)) {
+				return nil, ErrInvalidChunk
+			}
+			value = uint64(palette[value])
+		}
+		entries[i] = uint16(value)
+	}
+	return airOrBlocks(entries), nil
+}
+
+//Returns blocks, or nil if they are all air.
+func airOrBlocks(blocks *[sectionBlocks]uint16) *[sectionBlocks]uint16 {
+	for _, block := range blocks {
+		if block != 0 {
+			return blocks
+		}
+	}
+	return nil
+}
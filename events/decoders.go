@@ -0,0 +1,717 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/metadata"
+	"github.com/bela333/replayReader/nbt"
+)
+
+//IDs of the clientbound play packets the built-in decoders use. -1 means the packet doesn't exist in that version.
+type playIDs struct {
+	chat        int
+	systemChat  int
+	blockChange int
+	//Chunks. Map Chunk Bulk only exists in 1.8, which unloads chunks with an empty Chunk Data packet.
+	chunkData        int
+	chunkBulk        int
+	multiBlockChange int
+	unloadChunk      int
+	spawnObject      int
+	spawnMob         int
+	spawnPlayer      int
+	destroy          int
+	payload          int
+	move             int
+	rotate           int
+	moveRotate       int
+	teleport         int
+	headLook         int
+	joinGame         int
+	respawn          int
+	passengers       int
+	vehicleMove      int
+	effect           int
+	removeEffect     int
+	experience       int
+	playerInfo       int
+	playerRemove     int
+	disconnect       int
+	//Signed chat, since 1.19
+	playerChat     int
+	disguisedChat  int
+	playerPosition int
+	metadata       int
+	//Scoreboard
+	objective        int
+	score            int
+	displayObjective int
+	teams            int
+	bossBar          int
+	//The World Border packet until 1.16, which 1.17 split into one packet per action
+	worldBorder         int
+	borderInit          int
+	borderCenter        int
+	borderLerp          int
+	borderSize          int
+	borderWarningTime   int
+	borderWarningBlocks int
+	//The Title packet until 1.16, which 1.17 split into one packet per action
+	title        int
+	titleText    int
+	subtitleText int
+	titleTimes   int
+	clearTitles  int
+	actionBar    int
+	namedSound   int
+	sound        int
+	entitySound  int
+	particle     int
+	//Windows
+	openWindow      int
+	openHorseWindow int
+	closeWindow     int
+	windowItems     int
+	setSlot         int
+	heldItem        int
+}
+
+//The protocol versions the built-in decoders support.
+var protocols = map[int]playIDs{
+	47:  {chat: 0x02, systemChat: -1, blockChange: 0x23, chunkData: 0x21, chunkBulk: 0x26, multiBlockChange: 0x22, unloadChunk: -1, spawnObject: 0x0E, spawnMob: 0x0F, spawnPlayer: 0x0C, destroy: 0x13, payload: 0x3F, move: 0x15, rotate: 0x16, moveRotate: 0x17, teleport: 0x18, headLook: 0x19, joinGame: 0x01, respawn: 0x07, passengers: 0x1B, vehicleMove: -1, effect: 0x1D, removeEffect: 0x1E, experience: 0x1F, playerInfo: 0x38, playerRemove: -1, disconnect: 0x40, playerChat: -1, disguisedChat: -1, playerPosition: 0x08, metadata: 0x1C, objective: 0x3B, score: 0x3C, displayObjective: 0x3D, teams: 0x3E, bossBar: -1, worldBorder: 0x44, borderInit: -1, borderCenter: -1, borderLerp: -1, borderSize: -1, borderWarningTime: -1, borderWarningBlocks: -1, title: 0x45, titleText: -1, subtitleText: -1, titleTimes: -1, clearTitles: -1, actionBar: -1, namedSound: 0x29, sound: -1, entitySound: -1, particle: 0x2A, openWindow: 0x2D, openHorseWindow: -1, closeWindow: 0x2E, windowItems: 0x30, setSlot: 0x2F, heldItem: 0x09},
+	340: {chat: 0x0F, systemChat: -1, blockChange: 0x0B, chunkData: 0x20, chunkBulk: -1, multiBlockChange: 0x10, unloadChunk: 0x1D, spawnObject: 0x00, spawnMob: 0x03, spawnPlayer: 0x05, destroy: 0x32, payload: 0x18, move: 0x26, rotate: 0x28, moveRotate: 0x27, teleport: 0x4C, headLook: 0x36, joinGame: 0x23, respawn: 0x35, passengers: 0x43, vehicleMove: 0x29, effect: 0x4F, removeEffect: 0x33, experience: 0x40, playerInfo: 0x2E, playerRemove: -1, disconnect: 0x1A, playerChat: -1, disguisedChat: -1, playerPosition: 0x2F, metadata: 0x3C, objective: 0x42, score: 0x45, displayObjective: 0x3B, teams: 0x44, bossBar: 0x0C, worldBorder: 0x38, borderInit: -1, borderCenter: -1, borderLerp: -1, borderSize: -1, borderWarningTime: -1, borderWarningBlocks: -1, title: 0x48, titleText: -1, subtitleText: -1, titleTimes: -1, clearTitles: -1, actionBar: -1, namedSound: 0x19, sound: 0x49, entitySound: -1, particle: 0x22, openWindow: 0x13, openHorseWindow: -1, closeWindow: 0x12, windowItems: 0x14, setSlot: 0x16, heldItem: 0x3A},
+	578: {chat: 0x0F, systemChat: -1, blockChange: 0x0C, chunkData: 0x22, chunkBulk: -1, multiBlockChange: 0x10, unloadChunk: 0x1E, spawnObject: 0x00, spawnMob: 0x03, spawnPlayer: 0x05, destroy: 0x38, payload: 0x19, move: 0x29, rotate: 0x2B, moveRotate: 0x2A, teleport: 0x57, headLook: 0x3C, joinGame: 0x26, respawn: 0x3B, passengers: 0x4B, vehicleMove: 0x2D, effect: 0x5A, removeEffect: 0x39, experience: 0x48, playerInfo: 0x34, playerRemove: -1, disconnect: 0x1B, playerChat: -1, disguisedChat: -1, playerPosition: 0x36, metadata: 0x44, objective: 0x4A, score: 0x4D, displayObjective: 0x43, teams: 0x4C, bossBar: 0x0D, worldBorder: 0x3E, borderInit: -1, borderCenter: -1, borderLerp: -1, borderSize: -1, borderWarningTime: -1, borderWarningBlocks: -1, title: 0x50, titleText: -1, subtitleText: -1, titleTimes: -1, clearTitles: -1, actionBar: -1, namedSound: 0x1A, sound: 0x52, entitySound: 0x51, particle: 0x24, openWindow: 0x2F, openHorseWindow: 0x20, closeWindow: 0x14, windowItems: 0x15, setSlot: 0x17, heldItem: 0x40},
+	754: {chat: 0x0E, systemChat: -1, blockChange: 0x0B, chunkData: 0x20, chunkBulk: -1, multiBlockChange: 0x3B, unloadChunk: 0x1C, spawnObject: 0x00, spawnMob: 0x02, spawnPlayer: 0x04, destroy: 0x36, payload: 0x17, move: 0x27, rotate: 0x29, moveRotate: 0x28, teleport: 0x56, headLook: 0x3A, joinGame: 0x24, respawn: 0x39, passengers: 0x4B, vehicleMove: 0x2B, effect: 0x59, removeEffect: 0x37, experience: 0x48, playerInfo: 0x32, playerRemove: -1, disconnect: 0x19, playerChat: -1, disguisedChat: -1, playerPosition: 0x34, metadata: 0x44, objective: 0x4A, score: 0x4D, displayObjective: 0x43, teams: 0x4C, bossBar: 0x0C, worldBorder: 0x3D, borderInit: -1, borderCenter: -1, borderLerp: -1, borderSize: -1, borderWarningTime: -1, borderWarningBlocks: -1, title: 0x4F, titleText: -1, subtitleText: -1, titleTimes: -1, clearTitles: -1, actionBar: -1, namedSound: 0x18, sound: 0x51, entitySound: 0x50, particle: 0x22, openWindow: 0x2D, openHorseWindow: 0x1E, closeWindow: 0x12, windowItems: 0x13, setSlot: 0x15, heldItem: 0x3F},
+	763: {chat: -1, systemChat: 0x64, blockChange: 0x0A, chunkData: 0x24, chunkBulk: -1, multiBlockChange: 0x43, unloadChunk: 0x1E, spawnObject: 0x01, spawnMob: -1, spawnPlayer: 0x03, destroy: 0x3E, payload: 0x17, move: 0x2B, rotate: 0x2D, moveRotate: 0x2C, teleport: 0x68, headLook: 0x42, joinGame: 0x28, respawn: 0x41, passengers: 0x59, vehicleMove: 0x2E, effect: 0x6C, removeEffect: 0x3F, experience: 0x56, playerInfo: 0x3A, playerRemove: 0x39, disconnect: 0x1A, playerChat: 0x35, disguisedChat: 0x1B, playerPosition: 0x3C, metadata: 0x52, objective: 0x58, score: 0x5B, displayObjective: 0x51, teams: 0x5A, bossBar: 0x0B, worldBorder: -1, borderInit: 0x22, borderCenter: 0x47, borderLerp: 0x48, borderSize: 0x49, borderWarningTime: 0x4A, borderWarningBlocks: 0x4B, title: -1, titleText: 0x5F, subtitleText: 0x5D, titleTimes: 0x60, clearTitles: 0x0E, actionBar: 0x46, namedSound: -1, sound: 0x62, entitySound: 0x61, particle: 0x26, openWindow: 0x30, openHorseWindow: 0x20, closeWindow: 0x11, windowItems: 0x12, setSlot: 0x14, heldItem: 0x4D},
+}
+
+//Reports whether the built-in events can be decoded from replays recorded with protocol.
+func Supported(protocol int) bool {
+	_, ok := protocols[protocol]
+	return ok
+}
+
+//MovementIDs are the IDs of the packets that move entities on a protocol.
+//Move carries a position change, Rotate a rotation and MoveRotate both. Teleport sets an absolute position.
+type MovementIDs struct {
+	Move       int
+	Rotate     int
+	MoveRotate int
+	Teleport   int
+	HeadLook   int
+}
+
+//Returns the IDs of the movement packets on protocol, or false if it isn't supported.
+func Movement(protocol int) (MovementIDs, bool) {
+	ids, ok := protocols[protocol]
+	return MovementIDs{Move: ids.move, Rotate: ids.rotate, MoveRotate: ids.moveRotate, Teleport: ids.teleport, HeadLook: ids.headLook}, ok
+}
+
+//Returns a function giving the IDs picked by pick for a protocol, leaving out the ones that don't exist.
+func idsOf(pick func(ids playIDs) []int) func(protocol int) []int {
+	return func(protocol int) []int {
+		ids, ok := protocols[protocol]
+		if !ok {
+			return nil
+		}
+		var result []int
+		for _, id := range pick(ids) {
+			if id >= 0 {
+				result = append(result, id)
+			}
+		}
+		return result
+	}
+}
+
+func init() {
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.chat, ids.systemChat} }), decodeChat)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.blockChange} }), decodeBlockChange)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.multiBlockChange} }), decodeMultiBlockChange)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.chunkData} }), decodeChunk)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.chunkBulk} }), decodeChunkBulk)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.unloadChunk, ids.chunkData} }), decodeUnloadChunk)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.spawnObject, ids.spawnMob, ids.spawnPlayer} }), decodeEntitySpawn)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.destroy} }), decodeEntityDestroy)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.payload} }), decodeCustomPayload)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.move, ids.rotate, ids.moveRotate} }), decodeEntityMove)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.teleport} }), decodeEntityTeleport)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.headLook} }), decodeEntityHeadLook)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.joinGame, ids.respawn} }), decodeDimension)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.passengers} }), decodePassengers)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.vehicleMove} }), decodeVehicleMove)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.effect} }), decodeEntityEffect)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.removeEffect} }), decodeRemoveEffect)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.experience} }), decodeExperience)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.playerInfo} }), decodePlayerJoin)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.playerInfo, ids.playerRemove} }), decodePlayerLeave)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.playerInfo} }), decodePlayerUpdate)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.disconnect} }), decodeDisconnect)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.playerChat, ids.disguisedChat} }), decodePlayerChat)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.playerPosition} }), decodePlayerPosition)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.metadata} }), decodeEntityMetadata)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.objective} }), decodeObjective)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.score} }), decodeScore)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.displayObjective} }), decodeDisplayObjective)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.teams} }), decodeTeam)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.bossBar} }), decodeBossBar)
+	RegisterDecoder(idsOf(func(ids playIDs) []int {
+		return []int{ids.worldBorder, ids.borderInit, ids.borderCenter, ids.borderLerp, ids.borderSize, ids.borderWarningTime, ids.borderWarningBlocks}
+	}), decodeWorldBorder)
+	RegisterDecoder(idsOf(func(ids playIDs) []int {
+		return []int{ids.title, ids.titleText, ids.subtitleText, ids.titleTimes, ids.clearTitles, ids.actionBar}
+	}), decodeTitle)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.namedSound, ids.sound, ids.entitySound} }), decodeSound)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.particle} }), decodeParticle)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.openWindow, ids.openHorseWindow} }), decodeWindowOpen)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.closeWindow} }), decodeWindowClose)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.windowItems} }), decodeWindowItems)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.setSlot} }), decodeSetSlot)
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.heldItem} }), decodeHeldItem)
+}
+
+func decodeChat(p *replayReader.Packet, context Context) (ChatEvent, error) {
+	event := ChatEvent{Header: context.Header}
+	var err error
+	event.Message, _, err = p.ReadString()
+	if err != nil {
+		return event, err
+	}
+	if context.ID == protocols[context.Protocol].systemChat {
+		overlay, err := p.ReadBool()
+		event.Position = 1
+		if overlay {
+			event.Position = 2
+		}
+		return event, err
+	}
+	position, err := p.ReadByte()
+	event.Position = int(position)
+	if err != nil || context.Protocol < 735 {
+		return event, err
+	}
+	event.Sender, err = p.ReadUUID()
+	return event, err
+}
+
+func decodeBlockChange(p *replayReader.Packet, context Context) (BlockChangeEvent, error) {
+	event := BlockChangeEvent{Header: context.Header}
+	position, err := p.ReadPosition(context.Protocol)
+	if err != nil {
+		return event, err
+	}
+	event.X, event.Y, event.Z = position.X, position.Y, position.Z
+	event.BlockState, _, err = p.ReadVarInt()
+	return event, err
+}
+
+func decodeEntitySpawn(p *replayReader.Packet, context Context) (EntitySpawnEvent, error) {
+	ids := protocols[context.Protocol]
+	event := EntitySpawnEvent{Header: context.Header, Type: -1}
+	switch context.ID {
+	case ids.spawnObject:
+		event.Kind = EntityObject
+	case ids.spawnMob:
+		event.Kind = EntityMob
+	case ids.spawnPlayer:
+		event.Kind = EntityPlayer
+	}
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	if context.Protocol >= 107 || event.Kind == EntityPlayer {
+		event.UUID, err = p.ReadUUID()
+		if err != nil {
+			return event, err
+		}
+	}
+	if event.Kind != EntityPlayer {
+		//Object types were a byte until 1.14, mob types until 1.11.
+		if event.Kind == EntityObject && context.Protocol < 477 || event.Kind == EntityMob && context.Protocol < 315 {
+			entityType, err := p.ReaduByte()
+			if err != nil {
+				return event, err
+			}
+			event.Type = int(entityType)
+		} else {
+			event.Type, _, err = p.ReadVarInt()
+			if err != nil {
+				return event, err
+			}
+		}
+	}
+	if context.Protocol < 100 {
+		for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+			*coordinate, err = p.ReadFixedPoint()
+			if err != nil {
+				return event, err
+			}
+		}
+	} else {
+		for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+			*coordinate, err = p.ReadDouble()
+			if err != nil {
+				return event, err
+			}
+		}
+	}
+	first, err := p.ReadAngle()
+	if err != nil {
+		return event, err
+	}
+	second, err := p.ReadAngle()
+	//Objects send their pitch first, mobs and players their yaw.
+	if event.Kind == EntityObject {
+		event.Pitch, event.Yaw = first, second
+	} else {
+		event.Yaw, event.Pitch = first, second
+	}
+	return event, err
+}
+
+func decodeEntityDestroy(p *replayReader.Packet, context Context) (EntityDestroyEvent, error) {
+	event := EntityDestroyEvent{Header: context.Header}
+	var err error
+	event.EntityIDs, err = replayReader.ReadVarIntPrefixedArray(p, replayReader.IgnoreLen((*replayReader.Packet).ReadVarInt))
+	return event, err
+}
+
+func decodeCustomPayload(p *replayReader.Packet, context Context) (CustomPayloadEvent, error) {
+	event := CustomPayloadEvent{Header: context.Header}
+	var err error
+	event.Channel, _, err = p.ReadString()
+	if err != nil {
+		return event, err
+	}
+	event.Data, _, err = p.ReadRest()
+	return event, err
+}
+
+//Reads the position change of a movement packet. It was a fixed-point byte in 1/32 blocks until 1.9, a short in 1/4096 blocks since.
+func readDelta(p *replayReader.Packet, protocol int) (float64, error) {
+	if protocol < 107 {
+		return p.ReadFixedPointByte()
+	}
+	delta, err := p.ReadShort()
+	return float64(delta) / 4096, err
+}
+
+func decodeEntityMove(p *replayReader.Packet, context Context) (EntityMoveEvent, error) {
+	ids := protocols[context.Protocol]
+	event := EntityMoveEvent{Header: context.Header}
+	event.Moved = context.ID != ids.rotate
+	event.Rotated = context.ID != ids.move
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	if event.Moved {
+		for _, delta := range []*float64{&event.DX, &event.DY, &event.DZ} {
+			*delta, err = readDelta(p, context.Protocol)
+			if err != nil {
+				return event, err
+			}
+		}
+	}
+	if event.Rotated {
+		for _, angle := range []*float64{&event.Yaw, &event.Pitch} {
+			*angle, err = p.ReadAngle()
+			if err != nil {
+				return event, err
+			}
+		}
+	}
+	event.OnGround, err = p.ReadBool()
+	return event, err
+}
+
+func decodeEntityTeleport(p *replayReader.Packet, context Context) (EntityTeleportEvent, error) {
+	event := EntityTeleportEvent{Header: context.Header}
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+		if context.Protocol < 100 {
+			*coordinate, err = p.ReadFixedPoint()
+		} else {
+			*coordinate, err = p.ReadDouble()
+		}
+		if err != nil {
+			return event, err
+		}
+	}
+	for _, angle := range []*float64{&event.Yaw, &event.Pitch} {
+		*angle, err = p.ReadAngle()
+		if err != nil {
+			return event, err
+		}
+	}
+	event.OnGround, err = p.ReadBool()
+	return event, err
+}
+
+func decodeEntityHeadLook(p *replayReader.Packet, context Context) (EntityHeadLookEvent, error) {
+	event := EntityHeadLookEvent{Header: context.Header}
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.HeadYaw, err = p.ReadAngle()
+	return event, err
+}
+
+//Names of the dimensions of versions that sent them as numbers.
+var dimensionNames = map[int32]string{-1: "minecraft:the_nether", 0: "minecraft:overworld", 1: "minecraft:the_end"}
+
+func decodeDimension(p *replayReader.Packet, context Context) (DimensionEvent, error) {
+	event := DimensionEvent{Header: context.Header, Respawn: context.ID == protocols[context.Protocol].respawn}
+	if context.Protocol < 735 {
+		var dimension int32
+		var err error
+		if !event.Respawn {
+			event.EntityID, err = readJoinEntity(p, 1)
+			if err != nil {
+				return event, err
+			}
+		}
+		if !event.Respawn && context.Protocol < 108 {
+			var small int8
+			small, err = p.ReadByte()
+			dimension = int32(small)
+		} else {
+			dimension, err = p.ReadInt()
+		}
+		event.Dimension = dimensionNames[dimension]
+		return event, err
+	}
+	if !event.Respawn {
+		//Entity ID, hardcore, game mode and previous game mode, then the names of every world
+		var err error
+		event.EntityID, err = readJoinEntity(p, 3)
+		if err != nil {
+			return event, err
+		}
+		count, _, err := p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+		for i := 0; i < count; i++ {
+			_, _, err = p.ReadString()
+			if err != nil {
+				return event, err
+			}
+		}
+		if context.Protocol < 759 {
+			err = nbt.Skip(p, context.Protocol)
+		} else {
+			event.DimensionTypes, err = readDimensionTypes(p, context.Protocol)
+		}
+		if err != nil {
+			return event, err
+		}
+	}
+	//1.16 sends the dimension type as NBT, 1.19 as its name
+	var err error
+	if context.Protocol < 759 {
+		err = nbt.Skip(p, context.Protocol)
+	} else {
+		event.Type, _, err = p.ReadString()
+	}
+	if err != nil {
+		return event, err
+	}
+	event.Dimension, _, err = p.ReadString()
+	return event, err
+}
+
+//Reads the dimension types of the registry codec sent by Join Game, by name.
+func readDimensionTypes(p *replayReader.Packet, protocol int) (map[string]DimensionType, error) {
+	tree, err := nbt.Read(p, protocol)
+	if err != nil {
+		return nil, err
+	}
+	var codec struct {
+		DimensionTypes struct {
+			Value []struct {
+				Name    string        `nbt:"name"`
+				Element DimensionType `nbt:"element"`
+			} `nbt:"value"`
+		} `nbt:"minecraft:dimension_type"`
+	}
+	//A codec that doesn't look like the vanilla one only loses the dimension types, not the event
+	if nbt.Unmarshal(tree, &codec) != nil {
+		return nil, nil
+	}
+	types := make(map[string]DimensionType, len(codec.DimensionTypes.Value))
+	for _, dimensionType := range codec.DimensionTypes.Value {
+		types[dimensionType.Name] = dimensionType.Element
+	}
+	return types, nil
+}
+
+//Reads the entity ID of the recording player from Join Game, and skips the skip bytes after it.
+func readJoinEntity(p *replayReader.Packet, skip int) (int, error) {
+	entityID, err := p.ReadInt()
+	if err != nil {
+		return 0, err
+	}
+	return int(entityID), p.Skip(skip)
+}
+
+func decodePassengers(p *replayReader.Packet, context Context) (PassengersEvent, error) {
+	event := PassengersEvent{Header: context.Header}
+	//Until 1.9, Attach Entity put one entity on another, or on a leash
+	if context.Protocol < 107 {
+		rider, err := p.ReadInt()
+		if err != nil {
+			return event, err
+		}
+		vehicle, err := p.ReadInt()
+		if err != nil {
+			return event, err
+		}
+		leash, err := p.ReadBool()
+		if err != nil {
+			return event, err
+		}
+		if leash {
+			return event, errSkip
+		}
+		event.Vehicle, event.Passengers = int(vehicle), []int{int(rider)}
+		return event, nil
+	}
+	var err error
+	event.Vehicle, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Passengers, err = replayReader.ReadVarIntPrefixedArray(p, replayReader.IgnoreLen((*replayReader.Packet).ReadVarInt))
+	return event, err
+}
+
+func decodeVehicleMove(p *replayReader.Packet, context Context) (VehicleMoveEvent, error) {
+	event := VehicleMoveEvent{Header: context.Header}
+	var err error
+	for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+		*coordinate, err = p.ReadDouble()
+		if err != nil {
+			return event, err
+		}
+	}
+	yaw, err := p.ReadFloat()
+	if err != nil {
+		return event, err
+	}
+	pitch, err := p.ReadFloat()
+	event.Yaw, event.Pitch = float64(yaw), float64(pitch)
+	return event, err
+}
+
+//Reads an effect ID. It was a byte until 1.18.2.
+func readEffect(p *replayReader.Packet, protocol int) (int, error) {
+	if protocol < 758 {
+		effect, err := p.ReaduByte()
+		return int(effect), err
+	}
+	effect, _, err := p.ReadVarInt()
+	return effect, err
+}
+
+func decodeEntityEffect(p *replayReader.Packet, context Context) (EntityEffectEvent, error) {
+	event := EntityEffectEvent{Header: context.Header}
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Effect, err = readEffect(p, context.Protocol)
+	if err != nil {
+		return event, err
+	}
+	amplifier, err := p.ReaduByte()
+	if err != nil {
+		return event, err
+	}
+	event.Amplifier = int(amplifier)
+	//Effects that don't run out send a duration of -1
+	duration, _, err := p.ReadVarInt32()
+	if err != nil {
+		return event, err
+	}
+	event.Duration = int(duration)
+	//1.8 only sent whether the particles are hidden
+	if context.Protocol < 107 {
+		hide, err := p.ReadBool()
+		if !hide {
+			event.Flags = EffectParticles
+		}
+		return event, err
+	}
+	event.Flags, err = p.ReaduByte()
+	return event, err
+}
+
+func decodeRemoveEffect(p *replayReader.Packet, context Context) (RemoveEffectEvent, error) {
+	event := RemoveEffectEvent{Header: context.Header}
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Effect, err = readEffect(p, context.Protocol)
+	return event, err
+}
+
+func decodeExperience(p *replayReader.Packet, context Context) (ExperienceEvent, error) {
+	event := ExperienceEvent{Header: context.Header}
+	bar, err := p.ReadFloat()
+	if err != nil {
+		return event, err
+	}
+	event.Bar = float64(bar)
+	event.Level, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Total, _, err = p.ReadVarInt()
+	return event, err
+}
+
+func decodeDisconnect(p *replayReader.Packet, context Context) (DisconnectEvent, error) {
+	event := DisconnectEvent{Header: context.Header}
+	var err error
+	event.Reason, _, err = p.ReadString()
+	return event, err
+}
+
+//Length of a message signature.
+const signatureLen = 256
+
+func decodePlayerChat(p *replayReader.Packet, context Context) (PlayerChatEvent, error) {
+	event := PlayerChatEvent{Header: context.Header}
+	var err error
+	if context.ID == protocols[context.Protocol].disguisedChat {
+		event.Message, _, err = p.ReadString()
+		if err != nil {
+			return event, err
+		}
+		return event, readChatType(p, &event)
+	}
+	event.Sender, err = p.ReadUUID()
+	if err != nil {
+		return event, err
+	}
+	//Index of the message, and its signature
+	_, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Signed, err = p.ReadBool()
+	if err == nil && event.Signed {
+		err = p.Skip(signatureLen)
+	}
+	if err != nil {
+		return event, err
+	}
+	event.Content, _, err = p.ReadString()
+	if err != nil {
+		return event, err
+	}
+	//Timestamp and salt, then the messages before it the signature covers
+	err = p.Skip(16)
+	if err != nil {
+		return event, err
+	}
+	previous, _, err := p.ReadVarInt()
+	for i := 0; i < previous && err == nil; i++ {
+		var id int
+		id, _, err = p.ReadVarInt()
+		if err == nil && id == 0 {
+			err = p.Skip(signatureLen)
+		}
+	}
+	if err != nil {
+		return event, err
+	}
+	unsigned, err := p.ReadBool()
+	if err == nil && unsigned {
+		event.Message, _, err = p.ReadString()
+	} else if err == nil {
+		message, _ := json.Marshal(replayReader.ChatComponent{Text: event.Content})
+		event.Message = string(message)
+	}
+	if err != nil {
+		return event, err
+	}
+	filter, _, err := p.ReadVarInt()
+	//Partially filtered messages tell which characters are hidden
+	if err == nil && filter == 2 {
+		_, _, err = p.ReadBitSet()
+	}
+	if err != nil {
+		return event, err
+	}
+	return event, readChatType(p, &event)
+}
+
+//Reads the chat type of a message, and the names it is decorated with.
+func readChatType(p *replayReader.Packet, event *PlayerChatEvent) error {
+	var err error
+	event.ChatType, _, err = p.ReadVarInt()
+	if err != nil {
+		return err
+	}
+	event.Name, _, err = p.ReadString()
+	if err != nil {
+		return err
+	}
+	event.Target, err = readDisplayName(p)
+	return err
+}
+
+func decodePlayerPosition(p *replayReader.Packet, context Context) (PlayerPositionEvent, error) {
+	event := PlayerPositionEvent{Header: context.Header}
+	var err error
+	for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+		*coordinate, err = p.ReadDouble()
+		if err != nil {
+			return event, err
+		}
+	}
+	for _, angle := range []*float64{&event.Yaw, &event.Pitch} {
+		var value float32
+		value, err = p.ReadFloat()
+		if err != nil {
+			return event, err
+		}
+		*angle = float64(value)
+	}
+	event.Flags, err = p.ReaduByte()
+	if err != nil || context.Protocol < 107 {
+		return event, err
+	}
+	event.TeleportID, _, err = p.ReadVarInt()
+	return event, err
+}
+
+func decodeEntityMetadata(p *replayReader.Packet, context Context) (EntityMetadataEvent, error) {
+	event := EntityMetadataEvent{Header: context.Header}
+	var err error
+	event.EntityID, _, err = p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Entries, err = metadata.ReadEntityMetadata(p, context.Protocol)
+	//The entries after a particle can't be found, the ones before it are still good
+	if err == metadata.ErrParticle {
+		err = nil
+	}
+	return event, err
+}
@@ -0,0 +1,92 @@
+package events_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/replaytest"
+)
+
+//Returns the Position of a block as protocol encodes it: 1.14 swapped the places of Y and Z.
+func position(protocol int, x, y, z int64) int64 {
+	if protocol < 477 {
+		return (x&0x3FFFFFF)<<38 | (y&0xFFF)<<26 | z&0x3FFFFFF
+	}
+	return (x&0x3FFFFFF)<<38 | (z&0x3FFFFFF)<<12 | y&0xFFF
+}
+
+//Returns a chunk section of 1.18 and later, whose blocks and biomes are all value.
+func singleSection(p *replaytest.PacketBuilder, value int) *replaytest.PacketBuilder {
+	return p.Short(4096).UByte(0).VarInt(value).VarInt(0).UByte(0).VarInt(0).VarInt(0)
+}
+
+//Returns blocks which are all state.
+func filled(state uint16) *[4096]uint16 {
+	blocks := new([4096]uint16)
+	for i := range blocks {
+		blocks[i] = state
+	}
+	return blocks
+}
+
+func TestDecoders(t *testing.T) {
+	chunk763 := &replaytest.PacketBuilder{}
+	singleSection(chunk763, 0)
+	singleSection(chunk763, 9)
+	tests := []struct {
+		name    string
+		fixture replaytest.Fixture
+		packet  *replaytest.PacketBuilder
+		want    events.Event
+	}{
+		{"chat", replaytest.Protocol47, replaytest.Protocol47.ChatMessage(`{"text":"hi"}`), events.ChatEvent{Message: `{"text":"hi"}`}},
+		{"chat", replaytest.Protocol754, replaytest.Protocol754.ChatMessage(`{"text":"hi"}`), events.ChatEvent{Message: `{"text":"hi"}`}},
+		{"system chat", replaytest.Protocol763, replaytest.Protocol763.ChatMessage(`{"text":"hi"}`), events.ChatEvent{Message: `{"text":"hi"}`, Position: 1}},
+		{"block change", replaytest.Protocol47, replaytest.NewPacket(0x23).Long(position(47, -3, 64, 5)).VarInt(16), events.BlockChangeEvent{X: -3, Y: 64, Z: 5, BlockState: 16}},
+		{"block change", replaytest.Protocol578, replaytest.NewPacket(0x0C).Long(position(578, 3, 64, -5)).VarInt(1), events.BlockChangeEvent{X: 3, Y: 64, Z: -5, BlockState: 1}},
+		{"block change", replaytest.Protocol763, replaytest.NewPacket(0x0A).Long(position(763, 3, -60, 5)).VarInt(2), events.BlockChangeEvent{X: 3, Y: -60, Z: 5, BlockState: 2}},
+		{"entity effect", replaytest.Protocol47, replaytest.NewPacket(0x1D).VarInt(7).UByte(1).UByte(2).VarInt(600).Bool(false),
+			events.EntityEffectEvent{EntityID: 7, Effect: 1, Amplifier: 2, Duration: 600, Flags: events.EffectParticles}},
+		{"entity effect", replaytest.Protocol340, replaytest.NewPacket(0x4F).VarInt(7).UByte(1).UByte(2).VarInt(600).UByte(6),
+			events.EntityEffectEvent{EntityID: 7, Effect: 1, Amplifier: 2, Duration: 600, Flags: 6}},
+		{"entity effect", replaytest.Protocol763, replaytest.NewPacket(0x6C).VarInt(7).VarInt(1).UByte(2).VarInt(-1).UByte(6).Bool(false),
+			events.EntityEffectEvent{EntityID: 7, Effect: 1, Amplifier: 2, Duration: -1, Flags: 6}},
+		{"multi block change", replaytest.Protocol340, replaytest.NewPacket(0x10).Int(-1).Int(2).VarInt(1).UByte(3<<4 | 4).UByte(70).VarInt(5),
+			events.MultiBlockChangeEvent{Changes: []events.BlockChangeEvent{{X: -13, Y: 70, Z: 36, BlockState: 5}}}},
+		{"multi block change", replaytest.Protocol754, replaytest.NewPacket(0x3B).Long(-1<<42 | 2<<20 | 4).Bool(false).VarInt(1).VarLong(5<<12 | 3<<8 | 4<<4 | 6),
+			events.MultiBlockChangeEvent{Changes: []events.BlockChangeEvent{{X: -13, Y: 70, Z: 36, BlockState: 5}}}},
+		{"multi block change", replaytest.Protocol763, replaytest.NewPacket(0x43).Long(-1<<42 | 2<<20 | 0xFFFFC).VarInt(1).VarLong(5<<12 | 3<<8 | 4<<4 | 6),
+			events.MultiBlockChangeEvent{Changes: []events.BlockChangeEvent{{X: -13, Y: -58, Z: 36, BlockState: 5}}}},
+		{"chunk", replaytest.Protocol763, replaytest.NewPacket(0x24).Int(1).Int(-2).Raw([]byte{10, 0, 0, 0}).VarInt(len(chunk763.Bytes())).Raw(chunk763.Bytes()).VarInt(0),
+			events.ChunkEvent{X: 1, Z: -2, Full: true, Relative: true, Sections: []events.ChunkSection{{Y: 0}, {Y: 1, Blocks: filled(9)}}}},
+		{"unload chunk", replaytest.Protocol47, replaytest.NewPacket(0x21).Int(1).Int(-2).Bool(true).Short(0).VarInt(0), events.UnloadChunkEvent{X: 1, Z: -2}},
+		{"unload chunk", replaytest.Protocol340, replaytest.NewPacket(0x1D).Int(1).Int(-2), events.UnloadChunkEvent{X: 1, Z: -2}},
+	}
+	for _, test := range tests {
+		t.Run(test.fixture.Version+" "+test.name, func(t *testing.T) {
+			replay := replaytest.NewReplay().
+				AddPacket(0, test.fixture.LoginSuccess()).
+				AddPacket(100, test.packet).
+				Replay()
+			reader := events.NewEventReader(replay, test.fixture.Protocol, test.want)
+			got, ok := reader.Next()
+			if !ok {
+				t.Fatalf("no event, error %v", reader.Error())
+			}
+			//The Header comes from the packet, and is shared by the events inside the event
+			want := reflect.New(reflect.TypeOf(test.want)).Elem()
+			want.Set(reflect.ValueOf(test.want))
+			header := got.EventHeader()
+			want.FieldByName("Header").Set(reflect.ValueOf(header))
+			if changes := want.FieldByName("Changes"); changes.IsValid() {
+				for i := 0; i < changes.Len(); i++ {
+					changes.Index(i).FieldByName("Header").Set(reflect.ValueOf(header))
+				}
+			}
+			if header.Time != 100 || header.Packet != 1 || !reflect.DeepEqual(got, want.Interface()) {
+				t.Errorf("got %+v, want %+v", got, want.Interface())
+			}
+		})
+	}
+}
@@ -0,0 +1,566 @@
+//Package events turns the packets of a replay into typed events, and delivers them to subscribers in a single pass or one by one with an EventReader.
+package events
+
+import (
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/item"
+	"github.com/bela333/replayReader/metadata"
+)
+
+//Event is something that happened in a replay, decoded from one of its packets.
+type Event interface {
+	EventHeader() Header
+}
+
+//Header tells where in the replay an Event comes from.
+//Time is the Time of its packet, Packet is the Index of its packet and ID is its packet ID.
+type Header struct {
+	Time   int
+	Packet int
+	ID     int
+}
+
+func (h Header) EventHeader() Header {
+	return h
+}
+
+//ChatEvent is a message shown in the chat box or the action bar.
+//Message is the JSON chat component of the message.
+//Position is 0 for chat, 1 for system messages and 2 for the action bar.
+//Sender is the UUID of the player who sent it, on versions that tell it.
+type ChatEvent struct {
+	Header
+	Message  string
+	Position int
+	Sender   replayReader.UUID
+}
+
+//Parses the JSON chat component of the message.
+func (e ChatEvent) Component() (replayReader.ChatComponent, error) {
+	return replayReader.ParseChat(e.Message)
+}
+
+//PlayerChatEvent is a chat message sent by a player, on versions since 1.19 that sign them.
+//Message is the JSON chat component shown, Content the plain text the player typed. Signed tells if the message has a signature.
+//The server can show something else than Content, and it can send disguised messages, which have no Sender and no Content.
+//ChatType is the ID of the chat type in the registry of the server, which decorates the message with Name,
+//the JSON chat component of the sender, and Target, the one of the receiver of a private message, or "".
+type PlayerChatEvent struct {
+	Header
+	Sender   replayReader.UUID
+	Message  string
+	Content  string
+	Signed   bool
+	ChatType int
+	Name     string
+	Target   string
+}
+
+//Parses the JSON chat component of the message.
+func (e PlayerChatEvent) Component() (replayReader.ChatComponent, error) {
+	return replayReader.ParseChat(e.Message)
+}
+
+//BlockChangeEvent is a single block being changed to BlockState.
+type BlockChangeEvent struct {
+	Header
+	X, Y, Z    int
+	BlockState int
+}
+
+//MultiBlockChangeEvent is many blocks of a chunk section being changed at once. Changes share the Header of the event.
+type MultiBlockChangeEvent struct {
+	Header
+	Changes []BlockChangeEvent
+}
+
+//ChunkEvent is a chunk, a column of 16×16 blocks, being loaded at X and Z, which are block coordinates divided by 16.
+//Sections are the sections of 16×16×16 blocks the packet sent. If Full is set the chunk is sent whole, and sections
+//that aren't sent are air; otherwise the sections replace the ones the client has, and the others stay.
+//Relative is set since 1.18, which sends every section of the height of the world, starting at its bottom:
+//the Y of a section counts from there, and MinY of the DimensionType tells where that is.
+type ChunkEvent struct {
+	Header
+	X, Z     int
+	Full     bool
+	Relative bool
+	Sections []ChunkSection
+}
+
+//ChunkSection is a section of 16×16×16 blocks of a chunk, Y is its block Y divided by 16.
+//Blocks holds its block states by their position in the section, y<<8 | z<<4 | x. It is nil if the section is only air.
+type ChunkSection struct {
+	Y      int
+	Blocks *[4096]uint16
+}
+
+//ChunkBulkEvent is many chunks being loaded by one Map Chunk Bulk packet, which only 1.8 sends. Chunks share the Header of the event.
+type ChunkBulkEvent struct {
+	Header
+	Chunks []ChunkEvent
+}
+
+//UnloadChunkEvent is the chunk at X and Z being unloaded by the client. 1.8 sends it as an empty Chunk Data packet.
+type UnloadChunkEvent struct {
+	Header
+	X, Z int
+}
+
+//EntityKind tells which packet spawned an entity.
+type EntityKind int
+
+const (
+	EntityObject EntityKind = iota
+	EntityMob
+	EntityPlayer
+)
+
+//EntitySpawnEvent is an entity appearing in the world.
+//Type is the entity type ID of the protocol; it is -1 for players on versions that don't send one for them.
+//Yaw and Pitch are in degrees.
+type EntitySpawnEvent struct {
+	Header
+	Kind       EntityKind
+	EntityID   int
+	UUID       replayReader.UUID
+	Type       int
+	X, Y, Z    float64
+	Yaw, Pitch float64
+}
+
+//EntityDestroyEvent is entities disappearing from the world.
+type EntityDestroyEvent struct {
+	Header
+	EntityIDs []int
+}
+
+//EntityMoveEvent is an entity moving by DX, DY and DZ blocks, turning to Yaw and Pitch, or both.
+//Moved and Rotated tell which of them the packet had. Angles are in degrees.
+type EntityMoveEvent struct {
+	Header
+	EntityID   int
+	DX, DY, DZ float64
+	Yaw, Pitch float64
+	Moved      bool
+	Rotated    bool
+	OnGround   bool
+}
+
+//EntityMetadataEvent changes the metadata entries of an entity, like its flags, health or custom name.
+//Entries that aren't in the packet keep their value. Entries after a particle are left out, they can't be decoded.
+type EntityMetadataEvent struct {
+	Header
+	EntityID int
+	Entries  []metadata.Entry
+}
+
+//EntityTeleportEvent is an entity being moved to an absolute position.
+type EntityTeleportEvent struct {
+	Header
+	EntityID   int
+	X, Y, Z    float64
+	Yaw, Pitch float64
+	OnGround   bool
+}
+
+//EntityHeadLookEvent is an entity turning its head to HeadYaw, in degrees.
+type EntityHeadLookEvent struct {
+	Header
+	EntityID int
+	HeadYaw  float64
+}
+
+//DimensionEvent is the recording player joining the game or respawning in Dimension, like minecraft:the_nether.
+//Respawn is false for the Join Game packet. Respawning doesn't always change the dimension, it also happens after dying.
+//EntityID is the entity ID of the recording player, it is only sent by Join Game.
+//Since 1.19, Type is the name of the dimension type of the world, like minecraft:overworld,
+//and Join Game sends every DimensionType of the server in DimensionTypes, by name.
+type DimensionEvent struct {
+	Header
+	Dimension      string
+	Respawn        bool
+	EntityID       int
+	Type           string
+	DimensionTypes map[string]DimensionType
+}
+
+//DimensionType tells the height of the worlds of a dimension type: their lowest block Y is MinY, and they are Height blocks high.
+type DimensionType struct {
+	MinY   int `nbt:"min_y"`
+	Height int `nbt:"height"`
+}
+
+//Flags of a PlayerPositionEvent, telling which of its values are relative to where the player was.
+const (
+	RelativeX = 1 << iota
+	RelativeY
+	RelativeZ
+	RelativeYaw
+	RelativePitch
+)
+
+//PlayerPositionEvent is the server moving the recording player to X, Y and Z and turning it to Yaw and Pitch, in degrees.
+//The values whose flag is in Flags are added to the current ones instead. TeleportID is 0 before 1.9.
+type PlayerPositionEvent struct {
+	Header
+	X, Y, Z    float64
+	Yaw, Pitch float64
+	Flags      byte
+	TeleportID int
+}
+
+//PassengersEvent sets the entities riding Vehicle to Passengers. An empty list means nobody rides it anymore.
+//Before 1.9, a vehicle could only have one passenger. If Vehicle is -1, the passenger got off whatever it was riding.
+type PassengersEvent struct {
+	Header
+	Vehicle    int
+	Passengers []int
+}
+
+//VehicleMoveEvent moves the vehicle of the recording player to X, Y and Z. Yaw and Pitch are in degrees.
+type VehicleMoveEvent struct {
+	Header
+	X, Y, Z    float64
+	Yaw, Pitch float64
+}
+
+//Flags of an EntityEffectEvent.
+const (
+	EffectAmbient   = 0x01
+	EffectParticles = 0x02
+	EffectIcon      = 0x04
+)
+
+//EntityEffectEvent is an entity getting a potion effect.
+//Effect is the effect ID of the protocol, Amplifier is the level of the effect minus one,
+//and Duration is in ticks, or -1 for effects that don't run out.
+type EntityEffectEvent struct {
+	Header
+	EntityID  int
+	Effect    int
+	Amplifier int
+	Duration  int
+	Flags     byte
+}
+
+//RemoveEffectEvent is an entity losing a potion effect.
+type RemoveEffectEvent struct {
+	Header
+	EntityID int
+	Effect   int
+}
+
+//ExperienceEvent sets the experience of the recording player.
+//Bar is how full the experience bar is, from 0 to 1, Level is the level shown and Total is the total experience.
+type ExperienceEvent struct {
+	Header
+	Bar   float64
+	Level int
+	Total int
+}
+
+//CustomPayloadEvent is a plugin message the server sent on Channel, like minecraft:brand or a channel of a mod.
+//Data is the whole message, its format depends on the channel.
+type CustomPayloadEvent struct {
+	Header
+	Channel string
+	Data    []byte
+}
+
+//Modes of an ObjectiveEvent.
+const (
+	ObjectiveCreate = iota
+	ObjectiveRemove
+	ObjectiveUpdate
+)
+
+//ObjectiveEvent creates, removes or updates the scoreboard objective Name.
+//DisplayName is the JSON chat component shown as its title, and Hearts tells whether its scores are shown as hearts.
+//They are only sent when the objective is created or updated.
+type ObjectiveEvent struct {
+	Header
+	Name        string
+	Mode        int
+	DisplayName string
+	Hearts      bool
+}
+
+//ScoreEvent sets the score of Entity, a player name or an entity UUID, in Objective to Value, or removes it if Remove is set.
+//Before 1.13, removing a score with an empty Objective removes it from every objective.
+type ScoreEvent struct {
+	Header
+	Entity    string
+	Objective string
+	Remove    bool
+	Value     int
+}
+
+//Positions of a DisplayObjectiveEvent. The positions from DisplaySidebarTeam are the sidebar
+//shown to the members of teams, DisplaySidebarTeam plus the color of the team.
+const (
+	DisplayList = iota
+	DisplaySidebar
+	DisplayBelowName
+	DisplaySidebarTeam
+)
+
+//DisplayObjectiveEvent shows the objective Objective at Position. An empty Objective clears the position.
+type DisplayObjectiveEvent struct {
+	Header
+	Position  int
+	Objective string
+}
+
+//Modes of a TeamEvent.
+const (
+	TeamCreate = iota
+	TeamRemove
+	TeamUpdate
+	TeamAddPlayers
+	TeamRemovePlayers
+)
+
+//TeamEvent creates, removes or updates the team Name, or adds or removes its Players, which are player names or entity UUIDs.
+//DisplayName, Prefix and Suffix are JSON chat components. FriendlyFlags are 0x01 for friendly fire
+//and 0x02 for seeing invisible teammates. NameTagVisibility and CollisionRule are values like always and never,
+//CollisionRule is empty before 1.9. Color is a chat color, 0 to 15, or -1 or 21 for none depending on the version.
+//Only the fields of the Mode are sent, the others keep their zero value.
+type TeamEvent struct {
+	Header
+	Name              string
+	Mode              int
+	DisplayName       string
+	Prefix            string
+	Suffix            string
+	FriendlyFlags     byte
+	NameTagVisibility string
+	CollisionRule     string
+	Color             int
+	Players           []string
+}
+
+//Actions of a BossBarEvent.
+const (
+	BossBarAdd = iota
+	BossBarRemove
+	BossBarHealth
+	BossBarTitle
+	BossBarStyle
+	BossBarFlags
+)
+
+//Flags of a BossBarEvent.
+const (
+	BossBarDarkenSky = 0x01
+	BossBarMusic     = 0x02
+	BossBarFog       = 0x04
+)
+
+//BossBarEvent adds, removes or changes the boss bar UUID, since 1.9. Title is a JSON chat component,
+//Health is how full the bar is, from 0 to 1. Color is 0 to 6 for pink, blue, red, green, yellow, purple and white,
+//and Division is the number of notches: 0, 6, 10, 12 or 20 for 0 to 4.
+//Only the fields of the Action are sent, BossBarAdd sends every one of them.
+type BossBarEvent struct {
+	Header
+	UUID     replayReader.UUID
+	Action   int
+	Title    string
+	Health   float64
+	Color    int
+	Division int
+	Flags    byte
+}
+
+//Actions of a WorldBorderEvent.
+const (
+	BorderSize = iota
+	BorderLerp
+	BorderCenter
+	BorderInitialize
+	BorderWarningTime
+	BorderWarningBlocks
+)
+
+//WorldBorderEvent changes the world border. BorderSize sets its Diameter, and BorderLerp grows or shrinks it
+//from OldDiameter to Diameter in Speed milliseconds. BorderCenter moves its center to X and Z.
+//Warnings are shown WarningTime seconds or WarningBlocks blocks before the border reaches the player.
+//BorderInitialize sends every field, PortalBoundary is where portals can take players at most.
+//Only the fields of the Action are sent, the others keep their zero value.
+type WorldBorderEvent struct {
+	Header
+	Action         int
+	X, Z           float64
+	OldDiameter    float64
+	Diameter       float64
+	Speed          int64
+	PortalBoundary int
+	WarningTime    int
+	WarningBlocks  int
+}
+
+//Actions of a TitleEvent.
+const (
+	TitleText = iota
+	TitleSubtitle
+	TitleActionBar
+	TitleTimes
+	TitleClear
+	TitleReset
+)
+
+//TitleEvent sets the title, the subtitle shown under it or the action bar text to Text, a JSON chat component,
+//or changes how long titles are shown: FadeIn, Stay and FadeOut are in ticks.
+//TitleClear hides the title and its subtitle, TitleReset also sets the times back to their defaults.
+//Action bar texts are also sent as a ChatEvent with Position 2.
+type TitleEvent struct {
+	Header
+	Action  int
+	Text    string
+	FadeIn  int
+	Stay    int
+	FadeOut int
+}
+
+//Parses the JSON chat component of the text.
+func (e TitleEvent) Component() (replayReader.ChatComponent, error) {
+	return replayReader.ParseChat(e.Text)
+}
+
+//SoundEvent is a sound played at X, Y and Z, or by the entity EntityID, which is -1 for sounds played at a position.
+//Name is the sound, like minecraft:entity.generic.explode, for sounds sent by name. Otherwise ID is the sound ID of the protocol,
+//it is -1 for sounds sent by name. Category is the sound category, 0 for master, 1 for music and so on; it is 0 before 1.9.
+//Pitch is 1 at the normal pitch. Seed picks which variant of the sound is played, since 1.19.
+type SoundEvent struct {
+	Header
+	Name     string
+	ID       int
+	Category int
+	EntityID int
+	X, Y, Z  float64
+	Volume   float64
+	Pitch    float64
+	Seed     int64
+}
+
+//ParticleEvent is Count particles of the particle ID Particle spawned around X, Y and Z, spread by the offsets.
+//Speed is the particle data of the packet, most particles use it as their speed.
+//LongDistance particles are shown up to 65536 blocks away instead of 256.
+//Data is the extra data of particles like dust and block, which depends on the particle, as it was sent.
+type ParticleEvent struct {
+	Header
+	Particle                  int
+	LongDistance              bool
+	X, Y, Z                   float64
+	OffsetX, OffsetY, OffsetZ float64
+	Speed                     float64
+	Count                     int
+	Data                      []byte
+}
+
+//WindowOpenEvent opens the window of a container, like a chest or a villager trade, as WindowID.
+//Before 1.14 the window is told by Type, like minecraft:chest, and Slots is the number of its slots, TypeID is -1 then.
+//Since 1.14 TypeID is the window type ID of the protocol and Slots is 0, except for horses.
+//Title is a JSON chat component. EntityID is the horse of the window of a horse, or -1 for other windows.
+type WindowOpenEvent struct {
+	Header
+	WindowID int
+	Type     string
+	TypeID   int
+	Title    string
+	Slots    int
+	EntityID int
+}
+
+//WindowCloseEvent is the server closing the window WindowID.
+//Windows the player closes don't send one, the client doesn't tell the server it closed them in a way the replay records.
+type WindowCloseEvent struct {
+	Header
+	WindowID int
+}
+
+//WindowItemsEvent sets every slot of the window WindowID. Window 0 is the inventory of the player.
+//StateID, since 1.17.1, counts the changes of the window. Carried is the item held by the cursor, it is nil before 1.17.1.
+type WindowItemsEvent struct {
+	Header
+	WindowID int
+	StateID  int
+	Slots    []item.Slot
+	Carried  *item.Slot
+}
+
+//SetSlotEvent sets the slot Slot of the window WindowID to Item.
+//WindowID -1 with Slot -1 is the item held by the cursor, and WindowID -2 is a slot of the inventory of the player.
+type SetSlotEvent struct {
+	Header
+	WindowID int
+	StateID  int
+	Slot     int
+	Item     item.Slot
+}
+
+//HeldItemEvent selects the slot of the hotbar the player holds, from 0 to 8.
+type HeldItemEvent struct {
+	Header
+	Slot int
+}
+
+//PlayerInfo is what the tab list knows about a player. DisplayName is the JSON chat component shown instead of Name,
+//or "" if there is none. GameMode is 0 for survival, 1 for creative, 2 for adventure and 3 for spectator.
+//Ping is in milliseconds. Listed tells whether the player is shown in the tab list, it is always true before 1.19.3.
+type PlayerInfo struct {
+	UUID        replayReader.UUID
+	Name        string
+	Properties  []PlayerProperty
+	GameMode    int
+	Ping        int
+	DisplayName string
+	Listed      bool
+}
+
+//PlayerProperty is a property of a player's profile, like textures. Signature is "" if it isn't signed.
+type PlayerProperty struct {
+	Name      string
+	Value     string
+	Signature string
+}
+
+//PlayerJoinEvent is players being added to the tab list, which is how the client learns that they are online.
+type PlayerJoinEvent struct {
+	Header
+	Players []PlayerInfo
+}
+
+//PlayerUpdateEvent changes what the tab list shows about players that are already in it.
+//The fields of Players that aren't changed keep their zero value, GameMode, Listed, Ping and DisplayName tell which ones are.
+//A DisplayName change to "" removes the display name.
+type PlayerUpdateEvent struct {
+	Header
+	GameMode    bool
+	Listed      bool
+	Ping        bool
+	DisplayName bool
+	Players     []PlayerInfo
+}
+
+//PlayerLeaveEvent is players being removed from the tab list.
+type PlayerLeaveEvent struct {
+	Header
+	UUIDs []replayReader.UUID
+}
+
+//DisconnectEvent is the server ending the connection. Reason is the JSON chat component shown to the player.
+type DisconnectEvent struct {
+	Header
+	Reason string
+}
+
+//Parses the JSON chat component of the reason.
+func (e DisconnectEvent) Component() (replayReader.ChatComponent, error) {
+	return replayReader.ParseChat(e.Reason)
+}
+
+//Context tells a decoder about the packet it decodes.
+type Context struct {
+	Header
+	Protocol int
+}
@@ -0,0 +1,34 @@
+package events
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/bela333/replayReader"
+)
+
+//Returned by a decoder when the packet doesn't hold its event, so the Bus skips it without reporting an error.
+var errSkip = errors.New("packet holds no event")
+
+//Registers decode as the decoder of events of type E, decoded from the plugin messages sent on channel.
+//This lets modules decode the packets of a mod without changing this package.
+//decode is called with the message, context.ID is the ID of the plugin message packet.
+//Like RegisterDecoder, it should be called from init functions.
+func RegisterPayload[E Event](channel string, decode func(data []byte, context Context) (E, error)) {
+	RegisterDecoder(idsOf(func(ids playIDs) []int { return []int{ids.payload} }), func(p *replayReader.Packet, context Context) (E, error) {
+		var event E
+		payload, err := decodeCustomPayload(p, context)
+		if err != nil {
+			return event, err
+		}
+		if payload.Channel != channel {
+			return event, errSkip
+		}
+		return decode(payload.Data, context)
+	})
+}
+
+//Returns a Packet reading data, so the Read methods of replayReader can be used to decode plugin messages.
+func PayloadPacket(data []byte) *replayReader.Packet {
+	return &replayReader.Packet{Len: len(data), Data: bytes.NewReader(data)}
+}
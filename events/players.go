@@ -0,0 +1,203 @@
+package events
+
+import "github.com/bela333/replayReader"
+
+//First protocol version, 1.19.3, that split Player Info into Player Info Update and Player Info Remove.
+const protocolPlayerInfoUpdate = 761
+
+//What a Player Info packet changes about its players. They are the bits of the actions of Player Info Update,
+//the single action of older versions is turned into them.
+const (
+	playerAdd = 1 << iota
+	playerChat
+	playerGameMode
+	playerListed
+	playerPing
+	playerDisplayName
+	//Not a bit of Player Info Update, the removal of older versions
+	playerRemove = 0x80
+)
+
+//Actions of the Player Info packet before 1.19.3, by their ID.
+var legacyPlayerActions = []byte{
+	playerAdd | playerGameMode | playerListed | playerPing | playerDisplayName,
+	playerGameMode,
+	playerPing,
+	playerDisplayName,
+	playerRemove,
+}
+
+//Reads a Player Info, or Player Info Update, packet. It returns what the packet changes and the players it changes.
+//Their fields that aren't changed keep their zero value.
+func readPlayerInfo(p *replayReader.Packet, protocol int) (byte, []PlayerInfo, error) {
+	var actions byte
+	if protocol < protocolPlayerInfoUpdate {
+		action, _, err := p.ReadVarInt()
+		if err != nil {
+			return 0, nil, err
+		}
+		if action < 0 || action >= len(legacyPlayerActions) {
+			return 0, nil, errSkip
+		}
+		actions = legacyPlayerActions[action]
+	} else {
+		var err error
+		actions, err = p.ReaduByte()
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	players, err := replayReader.ReadVarIntPrefixedArray(p, func(p *replayReader.Packet) (PlayerInfo, error) {
+		return readPlayer(p, protocol, actions)
+	})
+	return actions, players, err
+}
+
+func readPlayer(p *replayReader.Packet, protocol int, actions byte) (PlayerInfo, error) {
+	player := PlayerInfo{Listed: protocol < protocolPlayerInfoUpdate}
+	var err error
+	player.UUID, err = p.ReadUUID()
+	if err != nil {
+		return player, err
+	}
+	if actions&playerAdd != 0 {
+		player.Name, _, err = p.ReadString()
+		if err != nil {
+			return player, err
+		}
+		player.Properties, err = replayReader.ReadVarIntPrefixedArray(p, readProperty)
+		if err != nil {
+			return player, err
+		}
+	}
+	//Older versions send the other fields of an added player in this order
+	if protocol < protocolPlayerInfoUpdate {
+		if actions&playerGameMode != 0 {
+			player.GameMode, _, err = p.ReadVarInt()
+		}
+		if err == nil && actions&playerPing != 0 {
+			player.Ping, _, err = p.ReadVarInt()
+		}
+		if err == nil && actions&playerDisplayName != 0 {
+			player.DisplayName, err = readDisplayName(p)
+		}
+		//1.19 added the chat signing key of added players
+		if err == nil && actions&playerAdd != 0 && protocol >= 759 {
+			err = skipChatSession(p, false)
+		}
+		return player, err
+	}
+	if actions&playerChat != 0 {
+		err = skipChatSession(p, true)
+	}
+	if err == nil && actions&playerGameMode != 0 {
+		player.GameMode, _, err = p.ReadVarInt()
+	}
+	if err == nil && actions&playerListed != 0 {
+		player.Listed, err = p.ReadBool()
+	}
+	if err == nil && actions&playerPing != 0 {
+		player.Ping, _, err = p.ReadVarInt()
+	}
+	if err == nil && actions&playerDisplayName != 0 {
+		player.DisplayName, err = readDisplayName(p)
+	}
+	return player, err
+}
+
+func readProperty(p *replayReader.Packet) (PlayerProperty, error) {
+	var property PlayerProperty
+	var err error
+	property.Name, _, err = p.ReadString()
+	if err == nil {
+		property.Value, _, err = p.ReadString()
+	}
+	if err != nil {
+		return property, err
+	}
+	signed, err := p.ReadBool()
+	if err != nil || !signed {
+		return property, err
+	}
+	property.Signature, _, err = p.ReadString()
+	return property, err
+}
+
+//Reads an optional JSON chat component, returning "" if there is none.
+func readDisplayName(p *replayReader.Packet) (string, error) {
+	present, err := p.ReadBool()
+	if err != nil || !present {
+		return "", err
+	}
+	name, _, err := p.ReadString()
+	return name, err
+}
+
+//Skips the optional chat signing key of a player: the session ID since 1.19.3, then when it expires, the key and its signature.
+func skipChatSession(p *replayReader.Packet, session bool) error {
+	present, err := p.ReadBool()
+	if err != nil || !present {
+		return err
+	}
+	if session {
+		_, err = p.ReadUUID()
+		if err != nil {
+			return err
+		}
+	}
+	_, err = p.ReadLong()
+	for i := 0; i < 2 && err == nil; i++ {
+		var length int
+		length, _, err = p.ReadVarInt()
+		if err == nil {
+			err = p.Skip(length)
+		}
+	}
+	return err
+}
+
+func decodePlayerJoin(p *replayReader.Packet, context Context) (PlayerJoinEvent, error) {
+	event := PlayerJoinEvent{Header: context.Header}
+	actions, players, err := readPlayerInfo(p, context.Protocol)
+	if err == nil && actions&playerAdd == 0 {
+		return event, errSkip
+	}
+	event.Players = players
+	return event, err
+}
+
+func decodePlayerLeave(p *replayReader.Packet, context Context) (PlayerLeaveEvent, error) {
+	event := PlayerLeaveEvent{Header: context.Header}
+	var err error
+	if context.ID == protocols[context.Protocol].playerRemove {
+		event.UUIDs, err = replayReader.ReadVarIntPrefixedArray(p, (*replayReader.Packet).ReadUUID)
+		return event, err
+	}
+	//Before 1.19.3, players are removed by a Player Info packet
+	if context.Protocol >= protocolPlayerInfoUpdate {
+		return event, errSkip
+	}
+	actions, players, err := readPlayerInfo(p, context.Protocol)
+	if err == nil && actions != playerRemove {
+		return event, errSkip
+	}
+	for _, player := range players {
+		event.UUIDs = append(event.UUIDs, player.UUID)
+	}
+	return event, err
+}
+
+func decodePlayerUpdate(p *replayReader.Packet, context Context) (PlayerUpdateEvent, error) {
+	event := PlayerUpdateEvent{Header: context.Header}
+	actions, players, err := readPlayerInfo(p, context.Protocol)
+	//Added players are a PlayerJoinEvent, with every field they are sent with
+	if err == nil && (actions&playerAdd != 0 || actions&(playerGameMode|playerListed|playerPing|playerDisplayName) == 0) {
+		return event, errSkip
+	}
+	event.GameMode = actions&playerGameMode != 0
+	event.Listed = actions&playerListed != 0
+	event.Ping = actions&playerPing != 0
+	event.DisplayName = actions&playerDisplayName != 0
+	event.Players = players
+	return event, err
+}
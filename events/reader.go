@@ -0,0 +1,89 @@
+package events
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/bela333/replayReader"
+)
+
+//EventReader reads the events of a replay one after the other, for code that would rather pull them than subscribe to them.
+//The events of a packet come in the order of the names of their types.
+type EventReader struct {
+	replay   *replayReader.Replay
+	protocol int
+	phase    PhaseTracker
+	byID     map[int][]reflect.Type
+	queue    []Event
+	err      error
+	//If OnError is set, errors of decoders are passed to it and Next goes on. Otherwise Next stops with the error.
+	OnError func(err *replayReader.PacketError)
+}
+
+//Returns an EventReader reading replay, recorded with protocol, from its current position.
+//Every event type with a registered decoder is decoded, or only the types of the events in only,
+//like NewEventReader(replay, protocol, ChatEvent{}, PlayerJoinEvent{}).
+func NewEventReader(replay *replayReader.Replay, protocol int, only ...Event) *EventReader {
+	var eventTypes []reflect.Type
+	for _, event := range only {
+		eventTypes = append(eventTypes, reflect.TypeOf(event))
+	}
+	if len(only) == 0 {
+		for eventType := range decoders {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	sort.Slice(eventTypes, func(i, j int) bool {
+		return eventTypes[i].String() < eventTypes[j].String()
+	})
+	return &EventReader{
+		replay:   replay,
+		protocol: protocol,
+		phase:    PhaseTracker{Protocol: protocol},
+		byID:     typesByID(protocol, eventTypes),
+	}
+}
+
+//Returns the next event of the replay. Type switches tell what it is, like case PlayerJoinEvent.
+//It returns false at the end of the replay or after an error, r.Error() tells which one happened.
+func (r *EventReader) Next() (Event, bool) {
+	var p replayReader.Packet
+	for len(r.queue) == 0 {
+		if r.err != nil || !r.replay.Next(&p) {
+			return nil, false
+		}
+		id, _, err := p.ReadVarInt()
+		if err != nil {
+			r.err = r.fail(&p, err)
+			continue
+		}
+		if !r.phase.Play(id) {
+			continue
+		}
+		context := Context{Header: Header{Time: p.Time, Packet: p.Index, ID: id}, Protocol: r.protocol}
+		r.err = decodeEvents(&p, context, r.byID[id], func(event Event) {
+			r.queue = append(r.queue, event)
+		}, r.fail)
+	}
+	event := r.queue[0]
+	r.queue = r.queue[1:]
+	return event, true
+}
+
+//Returns the error that stopped Next, or nil if it got to the end of the replay.
+func (r *EventReader) Error() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.replay.Error()
+}
+
+//Handles a decoding error. It returns the error if Next has to stop because of it.
+func (r *EventReader) fail(p *replayReader.Packet, err error) error {
+	packetErr := &replayReader.PacketError{Packet: p.Index, Time: p.Time, Err: err}
+	if r.OnError == nil {
+		return packetErr
+	}
+	r.OnError(packetErr)
+	return nil
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/bela333/replayReader"
+)
+
+//First protocol versions that changed the scoreboard packets: 1.9 added the collision rule of teams,
+//and 1.13 sent names as chat components instead of plain text.
+const (
+	protocolTeamCollision  = 107
+	protocolScoreboardChat = 393
+)
+
+//Reads a name that is a chat component since 1.13, and returns it as a JSON chat component on every version.
+func readScoreboardText(p *replayReader.Packet, protocol int) (string, error) {
+	text, _, err := p.ReadString()
+	if err != nil || protocol >= protocolScoreboardChat {
+		return text, err
+	}
+	component, _ := json.Marshal(replayReader.ChatComponent{Text: text})
+	return string(component), nil
+}
+
+func decodeObjective(p *replayReader.Packet, context Context) (ObjectiveEvent, error) {
+	event := ObjectiveEvent{Header: context.Header}
+	var err error
+	event.Name, _, err = p.ReadString()
+	if err != nil {
+		return event, err
+	}
+	mode, err := p.ReadByte()
+	event.Mode = int(mode)
+	if err != nil || (event.Mode != ObjectiveCreate && event.Mode != ObjectiveUpdate) {
+		return event, err
+	}
+	event.DisplayName, err = readScoreboardText(p, context.Protocol)
+	if err != nil {
+		return event, err
+	}
+	if context.Protocol < protocolScoreboardChat {
+		var kind string
+		kind, _, err = p.ReadString()
+		event.Hearts = kind == "hearts"
+	} else {
+		var kind int
+		kind, _, err = p.ReadVarInt()
+		event.Hearts = kind == 1
+	}
+	return event, err
+}
+
+func decodeScore(p *replayReader.Packet, context Context) (ScoreEvent, error) {
+	event := ScoreEvent{Header: context.Header}
+	var err error
+	event.Entity, _, err = p.ReadString()
+	if err != nil {
+		return event, err
+	}
+	action, _, err := p.ReadVarInt()
+	if err != nil {
+		return event, err
+	}
+	event.Remove = action == 1
+	event.Objective, _, err = p.ReadString()
+	if err != nil || event.Remove {
+		return event, err
+	}
+	value, _, err := p.ReadVarInt32()
+	event.Value = int(value)
+	return event, err
+}
+
+func decodeDisplayObjective(p *replayReader.Packet, context Context) (DisplayObjectiveEvent, error) {
+	event := DisplayObjectiveEvent{Header: context.Header}
+	position, err := p.ReadByte()
+	if err != nil {
+		return event, err
+	}
+	event.Position = int(position)
+	event.Objective, _, err = p.ReadString()
+	return event, err
+}
+
+func decodeTeam(p *replayReader.Packet, context Context) (TeamEvent, error) {
+	event := TeamEvent{Header: context.Header}
+	var err error
+	event.Name, _, err = p.ReadString()
+	if err != nil {
+		return event, err
+	}
+	mode, err := p.ReadByte()
+	if err != nil {
+		return event, err
+	}
+	event.Mode = int(mode)
+	if event.Mode == TeamCreate || event.Mode == TeamUpdate {
+		err = readTeamInfo(p, context.Protocol, &event)
+		if err != nil {
+			return event, err
+		}
+	}
+	if event.Mode == TeamCreate || event.Mode == TeamAddPlayers || event.Mode == TeamRemovePlayers {
+		event.Players, err = replayReader.ReadVarIntPrefixedArray(p, replayReader.IgnoreLen((*replayReader.Packet).ReadString))
+	}
+	return event, err
+}
+
+//Reads the fields of a team that are sent when it is created or updated.
+func readTeamInfo(p *replayReader.Packet, protocol int, event *TeamEvent) error {
+	var err error
+	event.DisplayName, err = readScoreboardText(p, protocol)
+	if err != nil {
+		return err
+	}
+	//Before 1.13, the prefix and suffix come right after the display name
+	if protocol < protocolScoreboardChat {
+		event.Prefix, err = readScoreboardText(p, protocol)
+		if err == nil {
+			event.Suffix, err = readScoreboardText(p, protocol)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	event.FriendlyFlags, err = p.ReaduByte()
+	if err == nil {
+		event.NameTagVisibility, _, err = p.ReadString()
+	}
+	if err == nil && protocol >= protocolTeamCollision {
+		event.CollisionRule, _, err = p.ReadString()
+	}
+	if err != nil {
+		return err
+	}
+	if protocol < protocolScoreboardChat {
+		color, err := p.ReadByte()
+		event.Color = int(color)
+		return err
+	}
+	event.Color, _, err = p.ReadVarInt()
+	if err == nil {
+		event.Prefix, _, err = p.ReadString()
+	}
+	if err == nil {
+		event.Suffix, _, err = p.ReadString()
+	}
+	return err
+}
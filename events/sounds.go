@@ -0,0 +1,130 @@
+package events
+
+import "github.com/bela333/replayReader"
+
+//First protocol versions that changed the sound and particle packets: 1.9 added sound categories, 1.10 sent the pitch as a Float,
+//1.15 sent the position of particles as Doubles, 1.19 added the seed of sounds and sent particle IDs as VarInts,
+//and 1.19.3 could send sounds by name in the Sound Effect packet.
+const (
+	protocolSoundCategory   = 107
+	protocolSoundPitchFloat = 210
+	protocolParticleDouble  = 573
+	protocolSoundSeed       = 759
+	protocolSoundInline     = 761
+)
+
+func decodeSound(p *replayReader.Packet, context Context) (SoundEvent, error) {
+	event := SoundEvent{Header: context.Header, ID: -1, EntityID: -1}
+	ids := protocols[context.Protocol]
+	var err error
+	if context.ID == ids.namedSound {
+		event.Name, _, err = p.ReadString()
+	} else {
+		err = readSoundID(p, context.Protocol, &event)
+	}
+	if err == nil && context.Protocol >= protocolSoundCategory {
+		event.Category, _, err = p.ReadVarInt()
+	}
+	if err != nil {
+		return event, err
+	}
+	if context.ID == ids.entitySound {
+		event.EntityID, _, err = p.ReadVarInt()
+	} else {
+		//The position is sent in eighths of a block
+		for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+			var value int32
+			value, err = p.ReadInt()
+			*coordinate = float64(value) / 8
+			if err != nil {
+				return event, err
+			}
+		}
+	}
+	if err != nil {
+		return event, err
+	}
+	volume, err := p.ReadFloat()
+	if err != nil {
+		return event, err
+	}
+	event.Volume = float64(volume)
+	if context.Protocol < protocolSoundPitchFloat {
+		//63 is the normal pitch
+		var pitch byte
+		pitch, err = p.ReaduByte()
+		event.Pitch = float64(pitch) / 63
+	} else {
+		var pitch float32
+		pitch, err = p.ReadFloat()
+		event.Pitch = float64(pitch)
+	}
+	if err == nil && context.Protocol >= protocolSoundSeed {
+		event.Seed, err = p.ReadLong()
+	}
+	return event, err
+}
+
+//Reads the sound ID of a Sound Effect packet. Since 1.19.3, 0 is followed by the name of the sound, and other IDs are one more than the sound ID.
+func readSoundID(p *replayReader.Packet, protocol int, event *SoundEvent) error {
+	var err error
+	event.ID, _, err = p.ReadVarInt()
+	if err != nil || protocol < protocolSoundInline {
+		return err
+	}
+	event.ID--
+	if event.ID >= 0 {
+		return nil
+	}
+	event.Name, _, err = p.ReadIdentifier()
+	if err != nil {
+		return err
+	}
+	_, err = replayReader.ReadOptional(p, (*replayReader.Packet).ReadFloat)
+	return err
+}
+
+func decodeParticle(p *replayReader.Packet, context Context) (ParticleEvent, error) {
+	event := ParticleEvent{Header: context.Header}
+	var err error
+	if context.Protocol >= protocolSoundSeed {
+		event.Particle, _, err = p.ReadVarInt()
+	} else {
+		var particle int32
+		particle, err = p.ReadInt()
+		event.Particle = int(particle)
+	}
+	if err == nil {
+		event.LongDistance, err = p.ReadBool()
+	}
+	if err != nil {
+		return event, err
+	}
+	for _, coordinate := range []*float64{&event.X, &event.Y, &event.Z} {
+		if context.Protocol >= protocolParticleDouble {
+			*coordinate, err = p.ReadDouble()
+		} else {
+			var value float32
+			value, err = p.ReadFloat()
+			*coordinate = float64(value)
+		}
+		if err != nil {
+			return event, err
+		}
+	}
+	for _, value := range []*float64{&event.OffsetX, &event.OffsetY, &event.OffsetZ, &event.Speed} {
+		var float float32
+		float, err = p.ReadFloat()
+		*value = float64(float)
+		if err != nil {
+			return event, err
+		}
+	}
+	count, err := p.ReadInt()
+	if err != nil {
+		return event, err
+	}
+	event.Count = int(count)
+	event.Data, _, err = p.ReadRest()
+	return event, err
+}
@@ -0,0 +1,54 @@
+package events
+
+import "github.com/bela333/replayReader"
+
+//First protocol version, 1.11, that sends action bar texts with the Title packet, which moved its later actions.
+const protocolTitleActionBar = 315
+
+func decodeTitle(p *replayReader.Packet, context Context) (TitleEvent, error) {
+	event := TitleEvent{Header: context.Header}
+	ids := protocols[context.Protocol]
+	var err error
+	switch context.ID {
+	case ids.title:
+		event.Action, _, err = p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+		if context.Protocol < protocolTitleActionBar && event.Action >= TitleActionBar {
+			event.Action++
+		}
+	case ids.titleText:
+		event.Action = TitleText
+	case ids.subtitleText:
+		event.Action = TitleSubtitle
+	case ids.actionBar:
+		event.Action = TitleActionBar
+	case ids.titleTimes:
+		event.Action = TitleTimes
+	case ids.clearTitles:
+		reset, err := p.ReadBool()
+		event.Action = TitleClear
+		if reset {
+			event.Action = TitleReset
+		}
+		return event, err
+	}
+	switch event.Action {
+	case TitleText, TitleSubtitle, TitleActionBar:
+		event.Text, _, err = p.ReadString()
+	case TitleTimes:
+		var times [3]int32
+		for i := range times {
+			times[i], err = p.ReadInt()
+			if err != nil {
+				return event, err
+			}
+		}
+		event.FadeIn, event.Stay, event.FadeOut = int(times[0]), int(times[1]), int(times[2])
+	case TitleClear, TitleReset:
+	default:
+		return event, errSkip
+	}
+	return event, err
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/item"
+)
+
+//First protocol versions that changed the window packets: 1.14 sent window types as IDs and opened horse windows with their own packet,
+//and 1.17.1 added the state ID of windows.
+const (
+	protocolWindowTypeID = 477
+	protocolWindowState  = 756
+)
+
+func decodeWindowOpen(p *replayReader.Packet, context Context) (WindowOpenEvent, error) {
+	event := WindowOpenEvent{Header: context.Header, TypeID: -1, EntityID: -1}
+	if context.ID == protocols[context.Protocol].openHorseWindow {
+		window, err := p.ReaduByte()
+		if err != nil {
+			return event, err
+		}
+		event.WindowID = int(window)
+		event.Type = "minecraft:horse"
+		event.Slots, _, err = p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+		entity, err := p.ReadInt()
+		event.EntityID = int(entity)
+		return event, err
+	}
+	var err error
+	if context.Protocol >= protocolWindowTypeID {
+		event.WindowID, _, err = p.ReadVarInt()
+		if err == nil {
+			event.TypeID, _, err = p.ReadVarInt()
+		}
+		if err == nil {
+			event.Title, _, err = p.ReadString()
+		}
+		return event, err
+	}
+	window, err := p.ReaduByte()
+	if err != nil {
+		return event, err
+	}
+	event.WindowID = int(window)
+	event.Type, _, err = p.ReadString()
+	if err == nil {
+		event.Title, _, err = p.ReadString()
+	}
+	if err != nil {
+		return event, err
+	}
+	slots, err := p.ReaduByte()
+	if err != nil {
+		return event, err
+	}
+	event.Slots = int(slots)
+	if event.Type == "EntityHorse" {
+		entity, err := p.ReadInt()
+		event.EntityID = int(entity)
+		return event, err
+	}
+	return event, nil
+}
+
+func decodeWindowClose(p *replayReader.Packet, context Context) (WindowCloseEvent, error) {
+	window, err := p.ReaduByte()
+	return WindowCloseEvent{Header: context.Header, WindowID: int(window)}, err
+}
+
+func decodeWindowItems(p *replayReader.Packet, context Context) (WindowItemsEvent, error) {
+	event := WindowItemsEvent{Header: context.Header}
+	window, err := p.ReaduByte()
+	if err != nil {
+		return event, err
+	}
+	event.WindowID = int(window)
+	var count int
+	if context.Protocol >= protocolWindowState {
+		event.StateID, _, err = p.ReadVarInt()
+		if err == nil {
+			count, _, err = p.ReadVarInt()
+		}
+	} else {
+		var short int16
+		short, err = p.ReadShort()
+		count = int(short)
+	}
+	if err != nil {
+		return event, err
+	}
+	event.Slots, err = item.ReadSlots(p, context.Protocol, count)
+	if err == nil && context.Protocol >= protocolWindowState {
+		var carried item.Slot
+		carried, err = item.ReadSlot(p, context.Protocol)
+		event.Carried = &carried
+	}
+	return event, err
+}
+
+func decodeSetSlot(p *replayReader.Packet, context Context) (SetSlotEvent, error) {
+	event := SetSlotEvent{Header: context.Header}
+	window, err := p.ReadByte()
+	if err != nil {
+		return event, err
+	}
+	event.WindowID = int(window)
+	if context.Protocol >= protocolWindowState {
+		event.StateID, _, err = p.ReadVarInt()
+		if err != nil {
+			return event, err
+		}
+	}
+	slot, err := p.ReadShort()
+	if err != nil {
+		return event, err
+	}
+	event.Slot = int(slot)
+	event.Item, err = item.ReadSlot(p, context.Protocol)
+	return event, err
+}
+
+func decodeHeldItem(p *replayReader.Packet, context Context) (HeldItemEvent, error) {
+	slot, err := p.ReadByte()
+	return HeldItemEvent{Header: context.Header, Slot: int(slot)}, err
+}
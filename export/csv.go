@@ -0,0 +1,56 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/bela333/replayReader/tracking"
+)
+
+//Writes series to w as CSV, with a time column followed by one column per series.
+//There is a row for every time any of the series changes. A series keeps its last value until it changes,
+//and is empty before its first sample.
+func CSV(w io.Writer, series ...tracking.Series) error {
+	output := csv.NewWriter(w)
+	header := []string{"time"}
+	for _, s := range series {
+		header = append(header, s.Name)
+	}
+	err := output.Write(header)
+	if err != nil {
+		return err
+	}
+
+	timeSet := make(map[int]bool)
+	for _, s := range series {
+		for _, sample := range s.Samples {
+			timeSet[sample.Time] = true
+		}
+	}
+	times := make([]int, 0, len(timeSet))
+	for time := range timeSet {
+		times = append(times, time)
+	}
+	sort.Ints(times)
+
+	//Position of the next sample of every series
+	next := make([]int, len(series))
+	row := make([]string, len(series)+1)
+	for _, time := range times {
+		row[0] = strconv.Itoa(time)
+		for i, s := range series {
+			for next[i] < len(s.Samples) && s.Samples[next[i]].Time <= time {
+				row[i+1] = strconv.FormatFloat(s.Samples[next[i]].Value, 'g', -1, 64)
+				next[i]++
+			}
+		}
+		err = output.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	output.Flush()
+	return output.Error()
+}
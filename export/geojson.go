@@ -0,0 +1,79 @@
+//Package export writes what was tracked in a replay in formats other tools understand.
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/bela333/replayReader/tracking"
+)
+
+//Name of the coordinate reference system of the exported GeoJSON: block coordinates,
+//where the first coordinate is X (east) and the second is Z (south), like on Dynmap and BlueMap.
+const BlockCRS = "urn:x-minecraft:crs:blocks"
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	CRS      geoJSONCRS       `json:"crs"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONCRS struct {
+	Type       string            `json:"type"`
+	Properties map[string]string `json:"properties"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string       `json:"type"`
+	Coordinates [][2]float64 `json:"coordinates"`
+}
+
+//Writes path to w as a GeoJSON FeatureCollection, with one LineString per dimension.
+//The coordinates are in BlockCRS. Every feature has its dimension and the times of its first and last point as properties.
+func GeoJSON(w io.Writer, path tracking.Path) error {
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		CRS:      geoJSONCRS{Type: "name", Properties: map[string]string{"name": BlockCRS}},
+		Features: []geoJSONFeature{},
+	}
+	dimensions := path.ByDimension()
+	for _, dimension := range sortedDimensions(dimensions) {
+		points := dimensions[dimension]
+		coordinates := make([][2]float64, len(points))
+		for i, point := range points {
+			coordinates[i] = [2]float64{point.X, point.Z}
+		}
+		//A LineString needs two points, so a single point is repeated.
+		if len(coordinates) == 1 {
+			coordinates = append(coordinates, coordinates[0])
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: map[string]any{
+				"dimension": dimension,
+				"start":     points[0].Time,
+				"end":       points[len(points)-1].Time,
+			},
+		})
+	}
+	encoder := json.NewEncoder(w)
+	return encoder.Encode(collection)
+}
+
+//Returns the dimensions in a stable order.
+func sortedDimensions(dimensions map[string]tracking.Path) []string {
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/bela333/replayReader/tracking"
+)
+
+//Writes the points of path to w as an SVG polyline, in block coordinates with X to the right and Z down.
+//path should only hold points of one dimension, see tracking.Path.ByDimension.
+//The view box fits the path with margin blocks around it.
+func SVG(w io.Writer, path tracking.Path, margin float64) error {
+	minX, minZ := math.Inf(1), math.Inf(1)
+	maxX, maxZ := math.Inf(-1), math.Inf(-1)
+	var points strings.Builder
+	for i, point := range path {
+		minX, maxX = math.Min(minX, point.X), math.Max(maxX, point.X)
+		minZ, maxZ = math.Min(minZ, point.Z), math.Max(maxZ, point.Z)
+		if i > 0 {
+			points.WriteByte(' ')
+		}
+		fmt.Fprintf(&points, "%g,%g", point.X, point.Z)
+	}
+	if len(path) == 0 {
+		minX, minZ, maxX, maxZ = 0, 0, 0, 0
+	}
+	width := maxX - minX + 2*margin
+	height := maxZ - minZ + 2*margin
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="%g %g %g %g">
+<polyline points="%s" fill="none" stroke="red" stroke-width="%g" stroke-linejoin="round"/>
+</svg>
+`, minX-margin, minZ-margin, math.Max(width, 1), math.Max(height, 1), points.String(), math.Max(math.Max(width, height)/500, 0.5))
+	return err
+}
+
+//Writes one SVG per dimension of path, to the writer returned by create for that dimension.
+func SVGByDimension(path tracking.Path, margin float64, create func(dimension string) (io.WriteCloser, error)) error {
+	dimensions := path.ByDimension()
+	for _, dimension := range sortedDimensions(dimensions) {
+		w, err := create(dimension)
+		if err != nil {
+			return err
+		}
+		err = SVG(w, dimensions[dimension], margin)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package replayReader
+
+import "io"
+
+//Reads packets until pred returns true for one, and returns it with its Data back at the start.
+//pred can read from the packet, it doesn't have to put it back. Searching starts at the current position of the Replay,
+//use FindFrom to start at a given time.
+//If no packet matches, or reading failed, it returns false. r.Error() tells which one happened.
+//The data of the packets pred doesn't read is skipped like with WithLazyPayloads, if the Replay can do that.
+func (r *Replay) Find(pred func(p *Packet) bool) (Packet, bool) {
+	lazy := r.config.lazyPayloads
+	r.config.lazyPayloads = true
+	defer func() {
+		r.config.lazyPayloads = lazy
+	}()
+	var p Packet
+	for r.Next(&p) {
+		if pred(&p) {
+			//That also reads the data of a lazy packet, so it can be kept
+			p.Data.Seek(0, io.SeekStart)
+			return p, true
+		}
+	}
+	return Packet{}, false
+}
+
+//Works like Find, but starts at the first packet whose Time is at least time, jumping there with index like SeekTime.
+func (r *Replay) FindFrom(index Index, time int, pred func(p *Packet) bool) (Packet, bool) {
+	err := r.SeekTime(index, time)
+	if err != nil {
+		r.error = err
+		return Packet{}, false
+	}
+	return r.Find(pred)
+}
+
+//Returns every packet pred returns true for, reading until the end of the replay or until limit packets were found.
+//If limit is 0 or less, there is no limit. The packets found before an error are returned with it.
+func (r *Replay) FindAll(pred func(p *Packet) bool, limit int) ([]Packet, error) {
+	var found []Packet
+	for limit <= 0 || len(found) < limit {
+		p, ok := r.Find(pred)
+		if !ok {
+			break
+		}
+		found = append(found, p)
+	}
+	return found, r.Error()
+}
+
+//Makes the Replay continue reading from the first packet whose Time is at least time, using index to find it
+//instead of reading every packet before it. index has to be the Index of the replay file, which has to be an io.Seeker.
+func (r *Replay) SeekTime(index Index, time int) error {
+	i := index.Search(time)
+	cursor := Cursor{Packet: i}
+	if i > 0 {
+		cursor.Time = index[i-1].Time
+	}
+	if i < len(index) {
+		cursor.Offset = index[i].Offset
+	} else if i > 0 {
+		last := index[i-1]
+		cursor.Offset = last.Offset + int64(r.headerSize()) + int64(last.Len)
+	}
+	return r.SeekCursor(cursor)
+}
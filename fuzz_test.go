@@ -0,0 +1,81 @@
+package replayReader_test
+
+import (
+	"testing"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/replaytest"
+)
+
+//Seeds of the fuzz targets: a valid replay, a header cut short, a negative length and a never ending VarInt.
+func fuzzSeeds(f *testing.F) {
+	f.Add(replaytest.NewReplay().AddPacket(0, replaytest.NewPacket(0x02).String("hello").VarInt(300).Long(1)).Bytes())
+	f.Add([]byte{0, 0, 0, 1, 0, 0})
+	f.Add([]byte{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF})
+	f.Add(replaytest.NewReplay().Add(0, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}).Bytes())
+	f.Add(replaytest.NewReplay().Add(0, []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0x07}).Bytes())
+}
+
+//Limits small enough that no input makes the fuzzer run out of memory.
+var fuzzLimits = replayReader.Limits{MaxPacketLen: 1 << 20, MaxStringLen: 1 << 16, MaxArrayLen: 1 << 16}
+
+func FuzzNext(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, file []byte) {
+		for _, options := range [][]replayReader.Option{nil, {replayReader.WithStrict()}, {replayReader.WithPartialPackets()}} {
+			replay := replayReader.NewReplayFromBytes(file, options...)
+			var packet replayReader.Packet
+			for replay.Next(&packet) {
+				if packet.Len < 0 {
+					t.Fatalf("packet %d has a negative length %d", packet.Index, packet.Len)
+				}
+			}
+		}
+	})
+}
+
+//Reads the first packet of file with read, until read fails or the packet ends.
+func fuzzRead(t *testing.T, file []byte, read func(p *replayReader.Packet) (int, error)) {
+	replay := replayReader.NewReplayFromBytes(file, replayReader.WithLimits(fuzzLimits))
+	var packet replayReader.Packet
+	if !replay.Next(&packet) {
+		return
+	}
+	for range packet.Len + 1 {
+		n, err := read(&packet)
+		if err != nil {
+			return
+		}
+		if n < 0 || n > packet.Len {
+			t.Fatalf("read %d bytes of a packet of %d", n, packet.Len)
+		}
+	}
+}
+
+func FuzzReadVarInt(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, file []byte) {
+		fuzzRead(t, file, func(p *replayReader.Packet) (int, error) {
+			_, n, err := p.ReadVarInt()
+			return n, err
+		})
+		fuzzRead(t, file, func(p *replayReader.Packet) (int, error) {
+			_, n, err := p.ReadVarLong()
+			return n, err
+		})
+	})
+}
+
+func FuzzReadString(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, file []byte) {
+		fuzzRead(t, file, func(p *replayReader.Packet) (int, error) {
+			_, n, err := p.ReadString()
+			return n, err
+		})
+		fuzzRead(t, file, func(p *replayReader.Packet) (int, error) {
+			_, n, err := p.ReadString16()
+			return n, err
+		})
+	})
+}
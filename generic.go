@@ -0,0 +1,43 @@
+package replayReader
+
+//Reads a value with read if the Boolean before it is true, like the many optional fields of the protocol.
+//It returns nil if the value isn't there.
+func ReadOptional[T any](p *Packet, read func(*Packet) (T, error)) (*T, error) {
+	present, err := p.ReadBool()
+	if err != nil || !present {
+		return nil, err
+	}
+	value, err := read(p)
+	return &value, err
+}
+
+//Reads a VarInt count followed by that many values, each read with read.
+//Every value takes at least a byte, so the count is checked like the length of a byte array.
+//On an error the values read before it are returned.
+func ReadVarIntPrefixedArray[T any](p *Packet, read func(*Packet) (T, error)) ([]T, error) {
+	count, _, err := p.ReadVarInt()
+	if err != nil {
+		return nil, err
+	}
+	err = p.checkLength(count, "array count")
+	if err != nil {
+		return nil, err
+	}
+	values := make([]T, 0, count)
+	for i := 0; i < count; i++ {
+		value, err := read(p)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+//Turns a reader that also returns its length, like (*Packet).ReadVarInt, into one ReadOptional and ReadVarIntPrefixedArray take.
+func IgnoreLen[T any](read func(*Packet) (T, int, error)) func(*Packet) (T, error) {
+	return func(p *Packet) (T, error) {
+		value, _, err := read(p)
+		return value, err
+	}
+}
@@ -0,0 +1,39 @@
+package replayReader
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+//Makes the Replay feed every byte it reads from its file, headers included, to h.
+//Once Next returned false, r.Sum() is the hash of the whole file, so it doesn't have to be read a second time.
+//Any hash.Hash can be used, for example sha256.New() or a non-cryptographic one for deduplication.
+//h is reset when the Replay goes back to the beginning of its file. After SeekCursor it only covers the packets read since.
+func WithHash(h hash.Hash) Option {
+	return func(r *Replay) {
+		r.hash = h
+	}
+}
+
+//Returns the hash of everything read from the replay file so far, or nil if the Replay was created without WithHash.
+func (r *Replay) Sum() []byte {
+	if r.hash == nil {
+		return nil
+	}
+	return r.hash.Sum(nil)
+}
+
+//Feeds the packet that was just read to the hash of the Replay.
+func (r *Replay) hashPacket(time uint32, len uint32, checksum uint32, data []byte) {
+	if r.hash == nil {
+		return
+	}
+	header := make([]byte, 0, headerLen+4)
+	header = binary.BigEndian.AppendUint32(header, time)
+	header = binary.BigEndian.AppendUint32(header, len)
+	if r.config.checksumFraming {
+		header = binary.BigEndian.AppendUint32(header, checksum)
+	}
+	r.hash.Write(header)
+	r.hash.Write(data)
+}
@@ -0,0 +1,82 @@
+package replayReader
+
+import (
+	"fmt"
+	"strings"
+)
+
+//Namespace of identifiers that don't have one.
+const DefaultNamespace = "minecraft"
+
+//IdentifierError is returned by ReadIdentifier for a string that isn't a valid namespaced identifier.
+//Identifier is the string that was read. It matches ErrCorrupt.
+type IdentifierError struct {
+	Packet     int
+	Offset     int64
+	Identifier string
+	Reason     string
+}
+
+func (e *IdentifierError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: invalid identifier %q: %s", e.Packet, e.Offset, e.Identifier, e.Reason)
+}
+
+func (e *IdentifierError) Unwrap() error {
+	return ErrCorrupt
+}
+
+//Reads a namespaced identifier, like minecraft:stone, from the packet. Len: len bytes
+//The identifier is returned as it was sent, use SplitIdentifier to get its namespace and path.
+//Identifiers with characters the protocol doesn't allow are reported with an *IdentifierError.
+func (p *Packet) ReadIdentifier() (result string, len int, err error) {
+	identifier, length, err := p.ReadString()
+	if err != nil {
+		return "", length, err
+	}
+	if reason := validateIdentifier(identifier); reason != "" {
+		return "", length, &IdentifierError{Packet: p.Index, Offset: p.errorOffset(length), Identifier: identifier, Reason: reason}
+	}
+	return identifier, length, nil
+}
+
+//Splits an identifier into its namespace and path. Identifiers without a namespace are in DefaultNamespace.
+func SplitIdentifier(identifier string) (namespace, path string) {
+	namespace, path, found := strings.Cut(identifier, ":")
+	if !found {
+		return DefaultNamespace, identifier
+	}
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return namespace, path
+}
+
+//Returns why identifier isn't valid, or "" if it is.
+//Namespaces can have lowercase letters, digits, '.', '-' and '_', paths can have '/' too.
+func validateIdentifier(identifier string) string {
+	if identifier == "" {
+		return "empty"
+	}
+	namespace, path, found := strings.Cut(identifier, ":")
+	if !found {
+		namespace, path = "", identifier
+	}
+	for _, c := range namespace {
+		if !identifierChar(c) {
+			return fmt.Sprintf("character %q in namespace", c)
+		}
+	}
+	if path == "" {
+		return "empty path"
+	}
+	for _, c := range path {
+		if !identifierChar(c) && c != '/' {
+			return fmt.Sprintf("character %q in path", c)
+		}
+	}
+	return ""
+}
+
+func identifierChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_'
+}
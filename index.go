@@ -0,0 +1,100 @@
+package replayReader
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+//IndexEntry describes where a packet is in a replay file.
+//Time is the milliseconds elapsed since the beginning of the Replay.
+//Offset is the position of the packet's header in the file.
+//Len is the length of the packet's data, without the header.
+type IndexEntry struct {
+	Time   int
+	Offset int64
+	Len    int
+}
+
+//Index lists the packets of a replay file in the order they appear in it.
+type Index []IndexEntry
+
+//Size of the time and length fields in front of every packet.
+const headerLen = 8
+
+//Builds an Index of the packets in data, which should contain a whole replay file.
+//The headers are read straight from data, so this is a lot faster than going through Next().
+//data can just as well be a memory mapped file.
+//If the last packet is cut off, the Index of the complete packets is returned along with a *FramingError.
+func ScanIndex(data []byte) (Index, error) {
+	index := make(Index, 0, len(data)/256)
+	offset := 0
+	for offset < len(data) {
+		if len(data)-offset < headerLen {
+			return index, &FramingError{Packet: len(index), Offset: int64(offset), Part: "header"}
+		}
+		time := binary.BigEndian.Uint32(data[offset:])
+		length := binary.BigEndian.Uint32(data[offset+4:])
+		if uint64(length) > uint64(len(data)-offset-headerLen) {
+			return index, &FramingError{Packet: len(index), Offset: int64(offset), Part: "data"}
+		}
+		index = append(index, IndexEntry{Time: int(time), Offset: int64(offset), Len: int(length)})
+		offset += headerLen + int(length)
+	}
+	return index, nil
+}
+
+//Builds an Index by reading the headers from r.
+//The data of the packets is skipped with Seek if r is an io.Seeker, and read and thrown away otherwise.
+//Prefer ScanIndex when the whole file is already in memory.
+func BuildIndex(r io.Reader) (Index, error) {
+	var index Index
+	seeker, canSeek := r.(io.Seeker)
+	//Seeking past the end doesn't fail, so the data of a packet is checked against the size of the file
+	size := int64(-1)
+	if canSeek {
+		size = remainingIn(seeker)
+	}
+	header := make([]byte, headerLen)
+	offset := int64(0)
+	for {
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF {
+			return index, nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return index, &FramingError{Packet: len(index), Offset: offset, Part: "header"}
+		}
+		if err != nil {
+			return index, err
+		}
+		time := binary.BigEndian.Uint32(header)
+		length := binary.BigEndian.Uint32(header[4:])
+		if canSeek {
+			if size >= 0 && offset+headerLen+int64(length) > size {
+				return index, &FramingError{Packet: len(index), Offset: offset, Part: "data"}
+			}
+			_, err = seeker.Seek(int64(length), io.SeekCurrent)
+		} else {
+			var n int64
+			n, err = io.CopyN(io.Discard, r, int64(length))
+			if err == io.EOF && n < int64(length) {
+				err = &FramingError{Packet: len(index), Offset: offset, Part: "data"}
+			}
+		}
+		if err != nil {
+			return index, err
+		}
+		index = append(index, IndexEntry{Time: int(time), Offset: offset, Len: int(length)})
+		offset += headerLen + int64(length)
+	}
+}
+
+//Returns the position in the Index of the first packet whose Time is at least time.
+//If there is no such packet, it returns len(index).
+//The Index has to be sorted by Time, which is true for well-formed replays.
+func (index Index) Search(time int) int {
+	return sort.Search(len(index), func(i int) bool {
+		return index[i].Time >= time
+	})
+}
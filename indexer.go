@@ -0,0 +1,169 @@
+package replayReader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+//Indexer builds the Index of a replay file in the background, while the file is read by others.
+//Everything it has found so far can be used right away, so seeking gets possible bit by bit while the indexing goes on.
+//Besides the Index, it counts the packets of every packet ID, and can remember the positions of interesting packets.
+//All its methods are safe for concurrent use.
+type Indexer struct {
+	mutex     sync.RWMutex
+	index     Index
+	histogram map[int]int
+	positions map[string][]int
+	err       error
+	done      chan struct{}
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+//Classify returns the kind of event a packet is, or "" if it isn't interesting. Its positions are kept by the Indexer.
+//p is positioned after the packet ID id.
+type Classify func(id int, p *Packet) string
+
+//Starts indexing the replay file in file, which is size bytes long.
+//The file is read through file's ReadAt, so it can be read by a Replay at the same time.
+//If classify isn't nil, it is called with every packet, which makes the Indexer read the whole file instead of only its headers.
+func StartIndexer(file io.ReaderAt, size int64, classify Classify) *Indexer {
+	indexer := &Indexer{
+		histogram: make(map[int]int),
+		positions: make(map[string][]int),
+		done:      make(chan struct{}),
+		stop:      make(chan struct{}),
+	}
+	go indexer.run(bufio.NewReaderSize(io.NewSectionReader(file, 0, size), 64*1024), classify)
+	return indexer
+}
+
+func (ix *Indexer) run(r *bufio.Reader, classify Classify) {
+	defer close(ix.done)
+	header := make([]byte, headerLen)
+	offset := int64(0)
+	for packet := 0; ; packet++ {
+		select {
+		case <-ix.stop:
+			return
+		default:
+		}
+		_, err := io.ReadFull(r, header)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			ix.fail(&FramingError{Packet: packet, Offset: offset, Part: "header"})
+			return
+		}
+		time := int(binary.BigEndian.Uint32(header))
+		length := int64(binary.BigEndian.Uint32(header[4:]))
+
+		var data []byte
+		if classify != nil {
+			data, err = readPayload(r, length)
+		} else {
+			data, err = r.Peek(min(int(length), 5))
+			if err == nil {
+				data = bytes.Clone(data)
+				_, err = r.Discard(int(length))
+			}
+		}
+		if err != nil {
+			ix.fail(&FramingError{Packet: packet, Offset: offset, Part: "data"})
+			return
+		}
+
+		p := Packet{Time: time, Len: int(length), Data: bytes.NewReader(data), Index: packet, Offset: offset, dataOffset: offset + headerLen}
+		id, _, idErr := p.ReadVarInt()
+		kind := ""
+		if idErr == nil && classify != nil {
+			kind = classify(id, &p)
+		}
+
+		ix.mutex.Lock()
+		ix.index = append(ix.index, IndexEntry{Time: time, Offset: offset, Len: int(length)})
+		if idErr == nil {
+			ix.histogram[id]++
+		}
+		if kind != "" {
+			ix.positions[kind] = append(ix.positions[kind], packet)
+		}
+		ix.mutex.Unlock()
+		offset += headerLen + length
+	}
+}
+
+func (ix *Indexer) fail(err error) {
+	ix.mutex.Lock()
+	ix.err = err
+	ix.mutex.Unlock()
+}
+
+//Returns a copy of the Index built so far.
+func (ix *Indexer) Index() Index {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	return append(Index(nil), ix.index...)
+}
+
+//Returns the number of packets indexed so far.
+func (ix *Indexer) Len() int {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	return len(ix.index)
+}
+
+//Returns the first indexed packet whose Time is at least time.
+//If that packet isn't indexed yet, it returns false, unless indexing is done, in which case there is no such packet.
+func (ix *Indexer) Search(time int) (IndexEntry, bool) {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	i := ix.index.Search(time)
+	if i == len(ix.index) {
+		return IndexEntry{}, false
+	}
+	return ix.index[i], true
+}
+
+//Returns a copy of the number of packets per packet ID counted so far.
+func (ix *Indexer) Histogram() map[int]int {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	histogram := make(map[int]int, len(ix.histogram))
+	for id, count := range ix.histogram {
+		histogram[id] = count
+	}
+	return histogram
+}
+
+//Returns the Indexes of the packets classified as kind so far.
+func (ix *Indexer) Positions(kind string) []int {
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	return append([]int(nil), ix.positions[kind]...)
+}
+
+//Returns a channel that is closed when the indexing is done or stopped.
+func (ix *Indexer) Done() <-chan struct{} {
+	return ix.done
+}
+
+//Waits until the indexing is done and returns its error, if there was one.
+func (ix *Indexer) Wait() error {
+	<-ix.done
+	ix.mutex.RLock()
+	defer ix.mutex.RUnlock()
+	return ix.err
+}
+
+//Stops the indexing. What was indexed until then stays usable.
+func (ix *Indexer) Stop() {
+	ix.stopOnce.Do(func() {
+		close(ix.stop)
+	})
+	<-ix.done
+}
@@ -0,0 +1,90 @@
+package replayReader
+
+import "io"
+
+//RawString is a string read from a packet that hasn't been converted to a Go string yet.
+//It points into the data of its packet, so reading it doesn't allocate at all, and converting it only when needed
+//saves an allocation for every string that is skipped or only compared.
+type RawString []byte
+
+//Converts s to a string. Every call allocates a new string.
+func (s RawString) String() string {
+	return string(s)
+}
+
+//Returns s as a string from in, allocating only the first time s is seen.
+func (s RawString) Intern(in *Interner) string {
+	return in.Intern(s)
+}
+
+//Reports whether s contains the same characters as str, without converting s.
+func (s RawString) Equals(str string) bool {
+	return string(s) == str
+}
+
+//Interner hands out a single shared copy of every string it has seen.
+//The zero value is ready to use. An Interner is not safe for concurrent use.
+type Interner struct {
+	strings map[string]string
+}
+
+//Returns the shared copy of the string made from b.
+//b isn't retained, so it can be reused after the call.
+func (in *Interner) Intern(b []byte) string {
+	if s, ok := in.strings[string(b)]; ok {
+		return s
+	}
+	if in.strings == nil {
+		in.strings = make(map[string]string)
+	}
+	s := string(b)
+	in.strings[s] = s
+	return s
+}
+
+//Returns the number of distinct strings in the Interner.
+func (in *Interner) Len() int {
+	return len(in.strings)
+}
+
+//Forgets all strings seen so far.
+func (in *Interner) Reset() {
+	in.strings = nil
+}
+
+//Reads a string from the packet without converting or copying it. Len: len bytes
+//The result is a slice of the data of the packet at the current position, so it must not be changed,
+//and it is only valid until the next call of Next. Use String or Intern to keep the string after that.
+func (p *Packet) ReadRawString() (result RawString, len int, err error) {
+	stringLen, stringLenLen, err := p.ReadVarInt()
+	if err != nil {
+		return nil, stringLenLen, err
+	}
+	if max := p.maxStringLen(); max >= 0 && (stringLen > max*3 || stringLen < 0) {
+		return nil, stringLenLen, &LimitError{Packet: p.Index, Offset: p.errorOffset(stringLenLen), Limit: "string length", Len: stringLen, Max: max * 3}
+	}
+	err = p.checkLength(stringLen, "string length")
+	if err != nil {
+		return nil, stringLenLen, err
+	}
+	outputString, ok := p.sliceData(stringLen)
+	if !ok {
+		//The data of the packet can't be sliced, so the string is copied
+		outputString, byteArrayLen, err := p.readByteArray(stringLen, "string length")
+		return RawString(outputString), stringLenLen + byteArrayLen, err
+	}
+	_, err = p.Data.Seek(int64(stringLen), io.SeekCurrent)
+	if err != nil {
+		return nil, stringLenLen, err
+	}
+	return RawString(outputString), stringLenLen + stringLen, nil
+}
+
+//Reads a string from the packet and interns it in in. Len: len bytes
+func (p *Packet) ReadInternedString(in *Interner) (result string, len int, err error) {
+	raw, n, err := p.ReadRawString()
+	if err != nil {
+		return "", n, err
+	}
+	return in.Intern(raw), n, nil
+}
@@ -0,0 +1,113 @@
+//Package item decodes the item stacks, or slots, sent in inventory, container and entity packets.
+package item
+
+import (
+	"errors"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/nbt"
+)
+
+//Protocol versions where the format of slots changed.
+const (
+	//1.13 dropped the damage of items, it became part of their NBT.
+	ProtocolNoDamage = 393
+	//1.13.2 sends whether a slot is empty as a boolean, followed by the item ID as a VarInt.
+	ProtocolPresentFlag = 404
+	//1.20.5 replaced NBT with item components.
+	ProtocolComponents = 766
+)
+
+//Returned by ReadSlot for items with components, which can't be decoded yet.
+//The Slot is returned with its ID and Count, but the rest of the packet can't be read.
+var ErrComponents = errors.New("item components can't be decoded")
+
+//Slot is an item stack. Empty slots have Present set to false.
+//Damage is only sent before 1.13. NBT is nil for items without a tag.
+type Slot struct {
+	Present bool
+	ID      int
+	Count   int
+	Damage  int
+	NBT     map[string]any
+}
+
+//Reads a slot from the packet, the way protocol sends it.
+func ReadSlot(p *replayReader.Packet, protocol int) (Slot, error) {
+	if protocol >= ProtocolComponents {
+		return readComponentSlot(p)
+	}
+	var slot Slot
+	if protocol >= ProtocolPresentFlag {
+		present, err := p.ReadBool()
+		if err != nil || !present {
+			return slot, err
+		}
+		slot.ID, _, err = p.ReadVarInt()
+		if err != nil {
+			return slot, err
+		}
+	} else {
+		id, err := p.ReadShort()
+		if err != nil || id == -1 {
+			return slot, err
+		}
+		slot.ID = int(id)
+	}
+	slot.Present = true
+	count, err := p.ReadByte()
+	if err != nil {
+		return slot, err
+	}
+	slot.Count = int(count)
+	if protocol < ProtocolNoDamage {
+		damage, err := p.ReadShort()
+		if err != nil {
+			return slot, err
+		}
+		slot.Damage = int(damage)
+	}
+	slot.NBT, err = nbt.ReadCompound(p, protocol)
+	return slot, err
+}
+
+//Reads count slots, like the contents of a window.
+func ReadSlots(p *replayReader.Packet, protocol int, count int) ([]Slot, error) {
+	if count < 0 || count > p.Remaining() {
+		return nil, replayReader.ErrInvalidLength
+	}
+	slots := make([]Slot, count)
+	for i := range slots {
+		var err error
+		slots[i], err = ReadSlot(p, protocol)
+		if err != nil {
+			return slots[:i], err
+		}
+	}
+	return slots, nil
+}
+
+func readComponentSlot(p *replayReader.Packet) (Slot, error) {
+	var slot Slot
+	count, _, err := p.ReadVarInt()
+	if err != nil || count <= 0 {
+		return slot, err
+	}
+	slot.Present, slot.Count = true, count
+	slot.ID, _, err = p.ReadVarInt()
+	if err != nil {
+		return slot, err
+	}
+	added, _, err := p.ReadVarInt()
+	if err != nil {
+		return slot, err
+	}
+	removed, _, err := p.ReadVarInt()
+	if err != nil {
+		return slot, err
+	}
+	if added != 0 || removed != 0 {
+		return slot, ErrComponents
+	}
+	return slot, nil
+}
@@ -0,0 +1,23 @@
+package replayReader
+
+import "iter"
+
+//Returns an iterator over the remaining packets of the Replay, for use with range:
+//
+//	for p, err := range replay.All() {
+//
+//At the end of the file the iteration just stops. If reading fails, the error is yielded with an empty Packet
+//as the last value, so the loop body sees it. If the loop stops early, the Replay stays at the next packet.
+func (r *Replay) All() iter.Seq2[Packet, error] {
+	return func(yield func(Packet, error) bool) {
+		var p Packet
+		for r.Next(&p) {
+			if !yield(p, nil) {
+				return
+			}
+		}
+		if err := r.Error(); err != nil {
+			yield(Packet{}, err)
+		}
+	}
+}
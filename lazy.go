@@ -0,0 +1,133 @@
+package replayReader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+//Returned when reading the Data of a packet of a lazy Replay after Next already skipped it.
+var ErrDataSkipped = errors.New("packet data was skipped by Next")
+
+//Makes Next read the data of a packet only once its Data is used. If it isn't, the next call of Next skips it,
+//with Seek if the file is an io.Seeker. That makes reading just the headers, like counting packets, much cheaper.
+//The Data of a packet can only be read until the next call of Next, later reads return ErrDataSkipped,
+//so packets can't be kept for later, like Stream and FindAll do.
+//A packet cut off by the end of the file is only noticed if its Data is read, Next then returns the error.
+//It has no effect together with checksums, WithHash, raw recordings or WithResync, which need the data of every packet.
+func WithLazyPayloads() Option {
+	return func(r *Replay) {
+		r.config.lazyPayloads = true
+	}
+}
+
+//Reports whether Next can leave the data of packets unread.
+func (r *Replay) lazy() bool {
+	if !r.config.lazyPayloads || r.config.checksumFraming || r.config.checksums != nil || r.config.rawPackets || r.config.setCompression || r.hash != nil {
+		return false
+	}
+	_, resync := r.replayFile.(*resyncReader)
+	return !resync
+}
+
+//lazyPayload is the Data of a packet of a lazy Replay. It reads the data from the file when it is first used.
+type lazyPayload struct {
+	replay *Replay
+	packet int
+	offset int64
+	length int64
+	data   []byte
+	reader *bytes.Reader
+	err    error
+}
+
+//Returns the next packet, whose header has been read, without reading its data.
+func (r *Replay) nextLazy(p *Packet, time int, length int64) bool {
+	payload := &lazyPayload{replay: r, packet: r.packets, offset: r.offset, length: length}
+	r.pending = payload
+	r.meter.packet()
+	headerSize := int64(r.headerSize())
+	*p = Packet{
+		Time:       time,
+		Len:        int(length),
+		Data:       payload,
+		Index:      r.packets,
+		Offset:     r.offset,
+		dataOffset: r.offset + headerSize,
+		config:     &r.config,
+	}
+	r.packets++
+	r.offset += headerSize + length
+	r.lastTime = time
+	return true
+}
+
+//Skips the data of the packet returned last, if its Data wasn't used.
+func (r *Replay) skipPending() error {
+	payload := r.pending
+	r.pending = nil
+	if payload.reader != nil || payload.err != nil {
+		return nil
+	}
+	payload.err = ErrDataSkipped
+	if seeker, ok := r.replayFile.(io.Seeker); ok {
+		_, err := seeker.Seek(payload.length, io.SeekCurrent)
+		if err != nil {
+			return &ReplayError{Packet: payload.packet, Offset: payload.offset, Stage: "data", Err: err}
+		}
+		r.meter.skip(payload.length)
+		return nil
+	}
+	n, err := io.CopyN(io.Discard, r.replayFile, payload.length)
+	r.meter.skip(n)
+	if err == io.EOF && n < payload.length {
+		if r.config.partialPackets {
+			return nil
+		}
+		return &FramingError{Packet: payload.packet, Offset: payload.offset, Part: "data"}
+	}
+	if err != nil {
+		return &ReplayError{Packet: payload.packet, Offset: payload.offset, Stage: "data", Err: err}
+	}
+	return nil
+}
+
+//Reads the data of the packet from the file, if it wasn't read yet.
+func (l *lazyPayload) load() error {
+	if l.reader != nil || l.err != nil {
+		return l.err
+	}
+	r := l.replay
+	start := r.meter.now()
+	data, err := readPayload(r.replayFile, l.length)
+	r.meter.payload(start, len(data))
+	l.data = data
+	l.reader = bytes.NewReader(data)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if r.config.partialPackets {
+			return nil
+		}
+		err = &FramingError{Packet: l.packet, Offset: l.offset, Part: "data"}
+	} else if err != nil {
+		err = &ReplayError{Packet: l.packet, Offset: l.offset, Stage: "data", Err: err}
+	}
+	if err != nil {
+		l.err = err
+		r.error = err
+	}
+	return err
+}
+
+func (l *lazyPayload) Read(b []byte) (int, error) {
+	if err := l.load(); err != nil {
+		return 0, err
+	}
+	return l.reader.Read(b)
+}
+
+func (l *lazyPayload) Seek(offset int64, whence int) (int64, error) {
+	if err := l.load(); err != nil {
+		return 0, err
+	}
+	return l.reader.Seek(offset, whence)
+}
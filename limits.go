@@ -0,0 +1,27 @@
+package replayReader
+
+//Limits caps the lengths a Replay accepts while reading. A zero field means no limit.
+//MaxPacketLen is the longest packet Next returns, in bytes.
+//MaxStringLen is the longest string ReadString reads, in UTF-16 code units. Strings are allowed to take up 3 bytes per unit.
+//Unlike the other limits, a zero MaxStringLen means DefaultMaxStringLen, a negative one means no limit.
+//MaxArrayLen is the longest byte array ReaduByteArray reads.
+//MaxNBTDepth is the deepest nesting of NBT compounds and lists.
+type Limits struct {
+	MaxPacketLen int
+	MaxStringLen int
+	MaxArrayLen  int
+	MaxNBTDepth  int
+}
+
+//StrictLimits are the largest sizes the vanilla client and server accept.
+var StrictLimits = Limits{
+	MaxPacketLen: 8388608,
+	MaxStringLen: MaxStringLength,
+	MaxArrayLen:  8388608,
+	MaxNBTDepth:  512,
+}
+
+//Returns the Limits of the Replay p was read from, for decoders outside this package that have to honor them.
+func (p *Packet) Limits() Limits {
+	return p.getConfig().limits
+}
@@ -0,0 +1,141 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"slices"
+	"time"
+
+	"github.com/bela333/replayReader"
+)
+
+//Name of the entry holding the annotations of an archive.
+const AnnotationsEntry = "annotations.json"
+
+const annotationsVersion = 1
+
+//Annotation is a note attached to a part of a replay.
+//It is attached either to the packet with the index Packet, or to the time range from Start to End, in milliseconds.
+//An annotation with a Start and no End is attached to the moment Start.
+type Annotation struct {
+	Packet  *int      `json:"packet,omitempty"`
+	Start   int       `json:"start,omitempty"`
+	End     int       `json:"end,omitempty"`
+	Text    string    `json:"text"`
+	Tags    []string  `json:"tags,omitempty"`
+	Author  string    `json:"author,omitempty"`
+	Created time.Time `json:"created,omitzero"`
+}
+
+//Returns an Annotation attached to the packet with the given index.
+func PacketAnnotation(packet int, text string, tags ...string) Annotation {
+	return Annotation{Packet: &packet, Text: text, Tags: tags}
+}
+
+//Returns an Annotation attached to the time range from start to end.
+func RangeAnnotation(start int, end int, text string, tags ...string) Annotation {
+	return Annotation{Start: start, End: end, Text: text, Tags: tags}
+}
+
+//Reports whether the annotation is attached to packet p.
+//Annotations attached to a time range cover every packet in it.
+func (a Annotation) Covers(p *replayReader.Packet) bool {
+	if a.Packet != nil {
+		return *a.Packet == p.Index
+	}
+	return a.CoversTime(p.Time)
+}
+
+//Reports whether the annotation is attached to a time range containing time.
+func (a Annotation) CoversTime(time int) bool {
+	if a.Packet != nil {
+		return false
+	}
+	return time >= a.Start && time <= max(a.End, a.Start)
+}
+
+//Annotations is the list of annotations of a replay.
+type Annotations []Annotation
+
+//Returns the annotations attached to p.
+func (list Annotations) For(p *replayReader.Packet) Annotations {
+	var found Annotations
+	for _, annotation := range list {
+		if annotation.Covers(p) {
+			found = append(found, annotation)
+		}
+	}
+	return found
+}
+
+//Returns the annotations with tag.
+func (list Annotations) Tagged(tag string) Annotations {
+	var found Annotations
+	for _, annotation := range list {
+		if slices.Contains(annotation.Tags, tag) {
+			found = append(found, annotation)
+		}
+	}
+	return found
+}
+
+type annotationsFile struct {
+	Version     int         `json:"version"`
+	Annotations Annotations `json:"annotations"`
+}
+
+//Reads the annotations of archive. An archive without annotations has none, it isn't an error.
+func ReadAnnotations(archive *zip.Reader) (Annotations, error) {
+	entry, err := openEntry(archive, "", AnnotationsEntry)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	var file annotationsFile
+	err = json.NewDecoder(entry).Decode(&file)
+	if err == nil && file.Version != annotationsVersion {
+		err = errors.New("unknown annotations version")
+	}
+	if err != nil {
+		return nil, &replayReader.ContainerError{Entry: AnnotationsEntry, Err: err}
+	}
+	return file.Annotations, nil
+}
+
+//Changes the annotations of the .mcpr archive at path with edit.
+//Like EditMetadata, only the annotations entry is written again.
+func EditAnnotations(path string, edit func(annotations Annotations) Annotations) error {
+	return rewrite(path, func(archive *zip.Reader, output *zip.Writer) error {
+		annotations, err := ReadAnnotations(archive)
+		if err != nil {
+			return err
+		}
+		annotations = edit(annotations)
+		err = copyEntries(output, archive, func(name string) bool {
+			return name == AnnotationsEntry
+		})
+		if err != nil {
+			return err
+		}
+		writer, err := output.Create(AnnotationsEntry)
+		if err != nil {
+			return err
+		}
+		if annotations == nil {
+			annotations = Annotations{}
+		}
+		return json.NewEncoder(writer).Encode(annotationsFile{Version: annotationsVersion, Annotations: annotations})
+	})
+}
+
+//Adds annotations to the .mcpr archive at path.
+func Annotate(path string, annotations ...Annotation) error {
+	return EditAnnotations(path, func(existing Annotations) Annotations {
+		return append(existing, annotations...)
+	})
+}
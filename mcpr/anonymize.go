@@ -0,0 +1,183 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/bela333/replayReader"
+)
+
+//Number of packets at the start of a recording that are searched for a handshake.
+const handshakeSearch = 10
+
+//Replaces the address of the server in the .mcpr archive at path with replacement.
+//The server name in the metadata is replaced, and so is the address in a handshake packet at the start of the recording.
+//The recording is only written again if it has a handshake, otherwise it is copied without being decompressed.
+//A seekable recording is written again as a seekable one.
+func Anonymize(path string, replacement string) error {
+	return rewrite(path, func(archive *zip.Reader, output *zip.Writer) error {
+		hasHandshake, err := findHandshake(archive, path)
+		if err != nil {
+			return err
+		}
+		err = copyEntries(output, archive, func(name string) bool {
+			return name == MetadataEntry || (hasHandshake && (name == RecordingEntry || name == SeekableEntry))
+		})
+		if err != nil {
+			return err
+		}
+
+		if hasHandshake {
+			err = writeAnonymized(archive, path, output, replacement)
+			if err != nil {
+				return err
+			}
+		}
+
+		metadata, err := readMetadata(archive, path)
+		if err != nil {
+			return err
+		}
+		metadata.ServerName = replacement
+		metadata.CustomServerName = ""
+		writer, err := output.Create(MetadataEntry)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(writer).Encode(metadata)
+	})
+}
+
+//Reports whether the recording of archive has a handshake packet at its start.
+func findHandshake(archive *zip.Reader, path string) (bool, error) {
+	entry, err := openRecordingEntry(archive, path)
+	if err != nil {
+		return false, err
+	}
+	defer entry.Close()
+	replay := replayReader.NewReplay(entry)
+	var p replayReader.Packet
+	for i := 0; i < handshakeSearch && replay.Next(&p); i++ {
+		if _, ok := parseHandshake(&p); ok {
+			return true, nil
+		}
+	}
+	return false, replay.Error()
+}
+
+//handshake is the content of a Handshake packet.
+type handshake struct {
+	protocol  int
+	address   string
+	port      uint16
+	nextState int
+}
+
+//Reads p as a Handshake packet. It reports false if p doesn't look like one.
+func parseHandshake(p *replayReader.Packet) (handshake, bool) {
+	p.Seek(0, io.SeekStart)
+	defer p.Seek(0, io.SeekStart)
+	var h handshake
+	id, _, err := p.ReadVarInt()
+	if err != nil || id != 0 {
+		return h, false
+	}
+	h.protocol, _, err = p.ReadVarInt()
+	if err != nil {
+		return h, false
+	}
+	h.address, _, err = p.ReadString()
+	if err != nil || len(h.address) == 0 || len(h.address) > 255 {
+		return h, false
+	}
+	h.port, err = p.ReaduShort()
+	if err != nil {
+		return h, false
+	}
+	h.nextState, _, err = p.ReadVarInt()
+	if err != nil || h.nextState < 1 || h.nextState > 3 {
+		return h, false
+	}
+	position, _ := p.Seek(0, io.SeekCurrent)
+	return h, position == int64(p.Len)
+}
+
+//Writes the recording of archive to output, with the address of its handshake replaced,
+//as a plain and as a seekable recording, like the archive has them.
+func writeAnonymized(archive *zip.Reader, path string, output *zip.Writer, replacement string) error {
+	if hasEntry(archive, RecordingEntry) {
+		entry, err := openEntry(archive, path, RecordingEntry)
+		if err != nil {
+			return err
+		}
+		defer entry.Close()
+		writer, err := output.Create(RecordingEntry)
+		if err != nil {
+			return err
+		}
+		err = anonymizeRecording(entry, writer, replacement)
+		if err != nil {
+			return err
+		}
+	}
+	if !hasEntry(archive, SeekableEntry) {
+		return nil
+	}
+	entry, err := openRecordingEntry(archive, path)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+	writer, err := output.CreateHeader(&zip.FileHeader{Name: SeekableEntry, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	reader, pipe := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pipe.CloseWithError(anonymizeRecording(entry, pipe, replacement))
+	}()
+	_, err = WriteSeekable(writer, reader, DefaultFrameSize)
+	//Stops the writing side if WriteSeekable failed, before entry is closed
+	reader.CloseWithError(err)
+	<-done
+	return err
+}
+
+//Writes the recording read from entry to writer, with the address of its handshake replaced.
+func anonymizeRecording(entry io.Reader, writer io.Writer, replacement string) error {
+	recording := replayReader.NewReplayWriter(writer)
+	replay := replayReader.NewReplayFromReader(entry)
+	var p replayReader.Packet
+	for i := 0; replay.Next(&p); i++ {
+		data := p.Bytes()
+		if i < handshakeSearch {
+			if h, ok := parseHandshake(&p); ok {
+				data = encodeHandshake(h, replacement)
+			}
+		}
+		err := recording.WritePacket(p.Time, data)
+		if err != nil {
+			return err
+		}
+	}
+	if err := replay.Error(); err != nil {
+		return err
+	}
+	return recording.Flush()
+}
+
+//Encodes a Handshake packet with h's fields and address.
+func encodeHandshake(h handshake, address string) []byte {
+	var data []byte
+	data = binary.AppendUvarint(data, 0)
+	data = binary.AppendUvarint(data, uint64(uint32(h.protocol)))
+	data = binary.AppendUvarint(data, uint64(len(address)))
+	data = append(data, address...)
+	data = binary.BigEndian.AppendUint16(data, h.port)
+	data = binary.AppendUvarint(data, uint64(h.nextState))
+	return data
+}
@@ -0,0 +1,122 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/bela333/replayReader"
+)
+
+//Archive is an opened .mcpr archive. Replay reads its recording, Zip gives access to every other entry.
+//If the recording is seekable, because it is stored without compression or was made seekable with MakeSeekable,
+//Replay can be rewound and seeked.
+type Archive struct {
+	Path   string
+	Replay *replayReader.Replay
+	Zip    *zip.Reader
+
+	recording io.ReadCloser
+	file      io.Closer
+}
+
+//Opens the .mcpr archive at path. The options are passed to the Replay.
+func Open(path string, options ...replayReader.Option) (*Archive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	archive, err := NewArchive(file, info.Size(), options...)
+	if err != nil {
+		file.Close()
+		var container *replayReader.ContainerError
+		if errors.As(err, &container) {
+			container.Path = path
+		}
+		return nil, err
+	}
+	archive.Path = path
+	archive.file = file
+	return archive, nil
+}
+
+//Opens the .mcpr archive in r, which is size bytes long. The options are passed to the Replay.
+//r has to stay open until the Archive is closed.
+func NewArchive(r io.ReaderAt, size int64, options ...replayReader.Option) (*Archive, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	recording, err := OpenRecording(r, archive)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{
+		Replay:    replayReader.NewReplay(recording, options...),
+		Zip:       archive,
+		recording: recording,
+	}, nil
+}
+
+//Reads the metadata of the archive.
+func (a *Archive) Metadata() (*Metadata, error) {
+	return readMetadata(a.Zip, a.Path)
+}
+
+//Reads the markers of the archive. See ReadMarkers.
+func (a *Archive) Markers() ([]Marker, error) {
+	return ReadMarkers(a.Zip)
+}
+
+//Opens the entry called name. A missing entry is reported as an error matching fs.ErrNotExist.
+func (a *Archive) Open(name string) (io.ReadCloser, error) {
+	return openEntry(a.Zip, a.Path, name)
+}
+
+//Returns the names of the entries of the archive.
+func (a *Archive) Entries() []string {
+	names := make([]string, len(a.Zip.File))
+	for i, file := range a.Zip.File {
+		names[i] = file.Name
+	}
+	return names
+}
+
+//Checks the signature of the archive with key. See VerifySignature.
+func (a *Archive) VerifySignature(key ed25519.PublicKey) error {
+	return VerifySignature(a.Zip, key)
+}
+
+//Closes the recording, and the file if the Archive was opened with Open.
+func (a *Archive) Close() error {
+	err := a.recording.Close()
+	if a.file != nil {
+		if closeErr := a.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//Returns the protocol version the archive was recorded with. It is the one in the metadata if it has one,
+//otherwise it is found in the start of the recording, like replayReader.DetectProtocol does.
+//Replay isn't moved, the recording is read again from the archive.
+func (a *Archive) DetectProtocol() (int, error) {
+	metadata, err := a.Metadata()
+	if err == nil && metadata.Protocol > 0 {
+		return metadata.Protocol, nil
+	}
+	entry, err := openRecordingEntry(a.Zip, a.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer entry.Close()
+	return replayReader.DetectProtocol(entry)
+}
@@ -0,0 +1,72 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+//Changes the metadata of the .mcpr archive at path with edit.
+//Only the metaData.json entry is written again, every other entry, including the recording, is copied without
+//being decompressed. The archive is replaced atomically once the new one is complete.
+func EditMetadata(path string, edit func(metadata *Metadata)) error {
+	return rewrite(path, func(archive *zip.Reader, output *zip.Writer) error {
+		metadata, err := readMetadata(archive, path)
+		if err != nil {
+			return err
+		}
+		edit(metadata)
+
+		err = copyEntries(output, archive, func(name string) bool {
+			return name == MetadataEntry
+		})
+		if err != nil {
+			return err
+		}
+		writer, err := output.Create(MetadataEntry)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(writer).Encode(metadata)
+	})
+}
+
+//Writes a new version of the archive at path with change, and replaces the archive with it if change succeeds.
+//The new archive gets the permissions of the old one.
+func rewrite(path string, change func(archive *zip.Reader, output *zip.Writer) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	output := zip.NewWriter(temp)
+	err = change(&archive.Reader, output)
+	if err == nil {
+		err = output.Close()
+	}
+	if err == nil {
+		//CreateTemp makes files only the owner can read
+		err = temp.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	archive.Close()
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,335 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/bela333/replayReader"
+)
+
+//An encrypted recording starts with a header, followed by the recording split into chunks of 64 KiB,
+//each sealed with AES-256-GCM:
+//	"RREN", version byte, key derivation byte, PBKDF2 iterations uint32, salt [16]byte, nonce prefix [7]byte
+//The nonce of a chunk is the nonce prefix, the number of the chunk as a big-endian uint32, and a byte that is 1 for
+//the last chunk and 0 otherwise, so chunks can't be reordered, dropped or cut off without it being noticed.
+//The header is authenticated as additional data of every chunk.
+
+//Name of the entry holding an encrypted recording.
+const EncryptedEntry = "recording.tmcpr.enc"
+
+var (
+	//Returned when an archive that should be encrypted has no encrypted recording.
+	ErrNotEncrypted = errors.New("replay is not encrypted")
+	//Returned when an encrypted recording can't be decrypted, because the password or key is wrong or the data was changed.
+	ErrDecrypt = errors.New("can't decrypt replay: wrong key or corrupted data")
+	//Returned when the header of an encrypted recording asks for more PBKDF2 iterations than maxPasswordIterations.
+	ErrTooManyIterations = errors.New("too many PBKDF2 iterations")
+)
+
+var encryptedMagic = [4]byte{'R', 'R', 'E', 'N'}
+
+const (
+	encryptedVersion   = 1
+	encryptedHeaderLen = 4 + 1 + 1 + 4 + 16 + 7
+	encryptedChunk     = 64 << 10
+
+	kdfNone   = 0
+	kdfPBKDF2 = 1
+
+	//Number of PBKDF2-SHA256 iterations used for new encrypted recordings.
+	passwordIterations = 600000
+	//Most iterations a recording is opened with, so a changed header can't make deriving the key take hours.
+	maxPasswordIterations = 4 * passwordIterations
+)
+
+//Secret is what an encrypted recording is opened with, a password or a key.
+type Secret struct {
+	password []byte
+	key      []byte
+}
+
+//Returns a Secret deriving the key from password with PBKDF2-SHA256.
+func Password(password string) Secret {
+	return Secret{password: []byte(password)}
+}
+
+//Returns a Secret using key, which must be 32 bytes long, as the AES-256 key.
+func Key(key []byte) Secret {
+	return Secret{key: key}
+}
+
+//Reads a key file written by GenerateKeyFile. The file holds the key either as 32 raw bytes or as 64 hex digits.
+func ReadKeyFile(path string) (Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Secret{}, err
+	}
+	if len(data) == 32 {
+		return Key(data), nil
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) != 32 {
+		return Secret{}, errors.New("key file must hold 32 bytes or 64 hex digits")
+	}
+	return Key(key), nil
+}
+
+//Writes a new random key as hex to a key file at path, readable only by its owner.
+func GenerateKeyFile(path string) (Secret, error) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Key(key), nil
+}
+
+//Returns the AES-GCM cipher for the recording with the given header.
+func (s Secret) aead(header []byte) (cipher.AEAD, error) {
+	key := s.key
+	switch header[5] {
+	case kdfNone:
+		if s.key == nil {
+			return nil, errors.New("replay is encrypted with a key, not a password")
+		}
+	case kdfPBKDF2:
+		if s.password == nil {
+			return nil, errors.New("replay is encrypted with a password, not a key")
+		}
+		iterations := binary.BigEndian.Uint32(header[6:])
+		if iterations > maxPasswordIterations {
+			return nil, &replayReader.ContainerError{Entry: EncryptedEntry, Err: ErrTooManyIterations}
+		}
+		var err error
+		key, err = pbkdf2.Key(sha256.New, string(s.password), header[10:26], int(iterations), 32)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unknown key derivation")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("key must be 32 bytes long")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//Returns the nonce of chunk i of the recording with the given header.
+func chunkNonce(header []byte, i uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, header[26:])
+	binary.BigEndian.PutUint32(nonce[7:], i)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+type encryptWriter struct {
+	output io.Writer
+	aead   cipher.AEAD
+	header []byte
+	chunk  []byte
+	count  uint32
+	sealed []byte
+}
+
+//Returns a writer encrypting everything written to it with secret and writing it to dst.
+//Close must be called to write the last chunk. It doesn't close dst.
+func NewEncryptWriter(dst io.Writer, secret Secret) (io.WriteCloser, error) {
+	header := make([]byte, encryptedHeaderLen)
+	copy(header, encryptedMagic[:])
+	header[4] = encryptedVersion
+	rand.Read(header[10:])
+	if secret.password != nil {
+		header[5] = kdfPBKDF2
+		binary.BigEndian.PutUint32(header[6:], passwordIterations)
+	}
+	aead, err := secret.aead(header)
+	if err != nil {
+		return nil, err
+	}
+	_, err = dst.Write(header)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{output: dst, aead: aead, header: header, chunk: make([]byte, 0, encryptedChunk)}, nil
+}
+
+//Seals the buffered chunk and writes it.
+func (w *encryptWriter) seal(last bool) error {
+	w.sealed = w.aead.Seal(w.sealed[:0], chunkNonce(w.header, w.count, last), w.chunk, w.header)
+	w.count++
+	w.chunk = w.chunk[:0]
+	_, err := w.output.Write(w.sealed)
+	return err
+}
+
+func (w *encryptWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		//A full chunk is only sealed once more data comes, since it might be the last one
+		if len(w.chunk) == encryptedChunk {
+			err := w.seal(false)
+			if err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.chunk[len(w.chunk):encryptedChunk], b)
+		w.chunk = w.chunk[:len(w.chunk)+n]
+		b = b[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *encryptWriter) Close() error {
+	return w.seal(true)
+}
+
+type decryptReader struct {
+	input  *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	count  uint32
+	sealed []byte
+	chunk  []byte
+	done   bool
+	err    error
+}
+
+//Returns a reader decrypting the encrypted recording in src with secret.
+//Each chunk is checked before any of it is returned, reads fail with ErrDecrypt if it was changed.
+func NewDecryptReader(src io.Reader, secret Secret) (io.Reader, error) {
+	header := make([]byte, encryptedHeaderLen)
+	_, err := io.ReadFull(src, header)
+	if err != nil || [4]byte(header) != encryptedMagic {
+		return nil, ErrNotEncrypted
+	}
+	if header[4] != encryptedVersion {
+		return nil, errors.New("unknown encrypted replay version")
+	}
+	aead, err := secret.aead(header)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		input:  bufio.NewReader(src),
+		aead:   aead,
+		header: header,
+		sealed: make([]byte, encryptedChunk+aead.Overhead()),
+	}, nil
+}
+
+//Reads and opens the next chunk.
+func (r *decryptReader) next() error {
+	n, err := io.ReadFull(r.input, r.sealed)
+	last := false
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		last = true
+	} else if err != nil {
+		return err
+	} else if _, err := r.input.Peek(1); err == io.EOF {
+		last = true
+	}
+	r.chunk, err = r.aead.Open(r.sealed[:0], chunkNonce(r.header, r.count, last), r.sealed[:n], r.header)
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.count++
+	r.done = last
+	return nil
+}
+
+func (r *decryptReader) Read(b []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(b, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+//Encrypts the recording of the .mcpr archive at path with secret, replacing recording.tmcpr, or the seekable recording,
+//with an encrypted entry.
+//The metadata stays readable.
+func Encrypt(path string, secret Secret) error {
+	return rewrite(path, func(archive *zip.Reader, output *zip.Writer) error {
+		recording, err := openRecordingEntry(archive, path)
+		if err != nil {
+			return err
+		}
+		defer recording.Close()
+		err = copyEntries(output, archive, func(name string) bool {
+			return name == RecordingEntry || name == SeekableEntry || name == EncryptedEntry
+		})
+		if err != nil {
+			return err
+		}
+		//Encrypted data doesn't compress, so it is stored
+		entry, err := output.CreateHeader(&zip.FileHeader{Name: EncryptedEntry, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		writer, err := NewEncryptWriter(entry, secret)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(writer, recording)
+		if err != nil {
+			return err
+		}
+		return writer.Close()
+	})
+}
+
+type encryptedRecording struct {
+	io.Reader
+	archive *zip.ReadCloser
+}
+
+func (e *encryptedRecording) Close() error {
+	return e.archive.Close()
+}
+
+//Opens the encrypted recording of the .mcpr archive at path with secret.
+//The returned reader can be passed to replayReader.NewReplay. Closing it closes the archive.
+func OpenEncrypted(path string, secret Secret) (io.ReadCloser, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := archive.Open(EncryptedEntry)
+	if err != nil {
+		archive.Close()
+		return nil, ErrNotEncrypted
+	}
+	reader, err := NewDecryptReader(entry, secret)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return &encryptedRecording{Reader: reader, archive: archive}, nil
+}
@@ -0,0 +1,67 @@
+package mcpr
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+//The metadata extensions registered with RegisterExtension, by key.
+var extensions = map[string]reflect.Type{}
+
+//Registers the type of the metadata key, so Extensions decodes it as a T.
+//Mods and tools that add their own keys to metaData.json can register them from an init function,
+//it isn't safe for concurrent use.
+func RegisterExtension[T any](key string) {
+	extensions[key] = reflect.TypeFor[T]()
+}
+
+//Returns the registered extension keys, sorted.
+func ExtensionKeys() []string {
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//Decodes every registered extension the metadata has. The values are pointers to the registered types.
+func (m *Metadata) Extensions() (map[string]any, error) {
+	values := make(map[string]any)
+	for key, extensionType := range extensions {
+		data, ok := m.Extra[key]
+		if !ok {
+			continue
+		}
+		value := reflect.New(extensionType).Interface()
+		err := json.Unmarshal(data, value)
+		if err != nil {
+			return values, err
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+//Decodes the value of the metadata key into value. It reports false if the metadata doesn't have the key.
+func (m *Metadata) Extension(key string, value any) (bool, error) {
+	data, ok := m.Extra[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+//Sets the metadata key to value, which is written with the rest of the metadata.
+func (m *Metadata) SetExtension(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if m.Extra == nil {
+		m.Extra = make(map[string]json.RawMessage)
+	}
+	m.Extra[key] = data
+	return nil
+}
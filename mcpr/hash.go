@@ -0,0 +1,88 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"hash"
+	"io"
+)
+
+//HashedReader hashes everything read through it. The hash is available from Sum once the reader is closed.
+type HashedReader struct {
+	reader io.ReadCloser
+	hash   hash.Hash
+	sum    []byte
+}
+
+//Opens the entry called name in archive, hashing its uncompressed content with h while it is read.
+func OpenHashed(archive *zip.Reader, name string, h hash.Hash) (*HashedReader, error) {
+	entry, err := openEntry(archive, "", name)
+	if err != nil {
+		return nil, err
+	}
+	return &HashedReader{reader: entry, hash: h}, nil
+}
+
+func (r *HashedReader) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	r.hash.Write(b[:n])
+	return n, err
+}
+
+//Closes the entry and records the hash of everything that was read.
+func (r *HashedReader) Close() error {
+	if r.sum == nil {
+		r.sum = r.hash.Sum(nil)
+	}
+	return r.reader.Close()
+}
+
+//Returns the hash of the content read before Close, or nil if the reader wasn't closed yet.
+func (r *HashedReader) Sum() []byte {
+	return r.sum
+}
+
+//HashingWriter writes entries to a zip.Writer and hashes the uncompressed content of each of them.
+//The hashes are available from Hashes once the HashingWriter is closed.
+type HashingWriter struct {
+	archive *zip.Writer
+	newHash func() hash.Hash
+	names   []string
+	hashes  []hash.Hash
+	sums    map[string][]byte
+}
+
+//Returns a HashingWriter writing to archive, hashing every entry with a hash made by newHash, for example sha256.New.
+func NewHashingWriter(archive *zip.Writer, newHash func() hash.Hash) *HashingWriter {
+	return &HashingWriter{archive: archive, newHash: newHash}
+}
+
+//Adds an entry called name to the archive. Like zip.Writer.Create, the entry has to be written before the next one is created.
+func (w *HashingWriter) Create(name string) (io.Writer, error) {
+	return w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+}
+
+//Adds an entry described by header to the archive.
+func (w *HashingWriter) CreateHeader(header *zip.FileHeader) (io.Writer, error) {
+	writer, err := w.archive.CreateHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	h := w.newHash()
+	w.names = append(w.names, header.Name)
+	w.hashes = append(w.hashes, h)
+	return io.MultiWriter(writer, h), nil
+}
+
+//Closes the archive and records the hash of every entry.
+func (w *HashingWriter) Close() error {
+	w.sums = make(map[string][]byte, len(w.names))
+	for i, name := range w.names {
+		w.sums[name] = w.hashes[i].Sum(nil)
+	}
+	return w.archive.Close()
+}
+
+//Returns the hash of every entry by name, or nil if the HashingWriter wasn't closed yet.
+func (w *HashingWriter) Hashes() map[string][]byte {
+	return w.sums
+}
@@ -0,0 +1,70 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io/fs"
+
+	"github.com/bela333/replayReader"
+)
+
+//Name of the entry holding the markers the Replay Mod placed in the recording.
+const MarkersEntry = "markers.json"
+
+//Marker is a marker placed in the recording at Time milliseconds, with the camera position it was placed from.
+//Name is empty for markers without one.
+type Marker struct {
+	Time             int
+	Name             string
+	X, Y, Z          float64
+	Yaw, Pitch, Roll float32
+}
+
+//The way the Replay Mod writes a marker
+type markerJSON struct {
+	RealTimestamp int `json:"realTimestamp"`
+	Value         struct {
+		Name     string `json:"name"`
+		Position struct {
+			X     float64 `json:"x"`
+			Y     float64 `json:"y"`
+			Z     float64 `json:"z"`
+			Yaw   float32 `json:"yaw"`
+			Pitch float32 `json:"pitch"`
+			Roll  float32 `json:"roll"`
+		} `json:"position"`
+	} `json:"value"`
+}
+
+//Reads the markers of archive, in the order they were saved. An archive without markers has none, it isn't an error.
+func ReadMarkers(archive *zip.Reader) ([]Marker, error) {
+	entry, err := openEntry(archive, "", MarkersEntry)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	var saved []markerJSON
+	err = json.NewDecoder(entry).Decode(&saved)
+	if err != nil {
+		return nil, &replayReader.ContainerError{Entry: MarkersEntry, Err: err}
+	}
+	markers := make([]Marker, len(saved))
+	for i, marker := range saved {
+		position := marker.Value.Position
+		markers[i] = Marker{
+			Time:  marker.RealTimestamp,
+			Name:  marker.Value.Name,
+			X:     position.X,
+			Y:     position.Y,
+			Z:     position.Z,
+			Yaw:   position.Yaw,
+			Pitch: position.Pitch,
+			Roll:  position.Roll,
+		}
+	}
+	return markers, nil
+}
@@ -0,0 +1,44 @@
+//Package mcpr works with the .mcpr archives of the Replay Mod, which are zip files holding the recording and its metadata.
+package mcpr
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/bela333/replayReader"
+)
+
+//Names of the entries in a .mcpr archive.
+const (
+	RecordingEntry = "recording.tmcpr"
+	MetadataEntry  = "metaData.json"
+)
+
+//Opens the entry called name in archive. path is only used in errors.
+//A missing entry is reported as a *replayReader.ContainerError matching fs.ErrNotExist.
+func openEntry(archive *zip.Reader, path string, name string) (io.ReadCloser, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fs.ErrNotExist
+		}
+		return nil, &replayReader.ContainerError{Path: path, Entry: name, Err: err}
+	}
+	return file, nil
+}
+
+//Copies every entry of src to dst without recompressing them, except the ones for which skip returns true.
+func copyEntries(dst *zip.Writer, src *zip.Reader, skip func(name string) bool) error {
+	for _, file := range src.File {
+		if skip != nil && skip(file.Name) {
+			continue
+		}
+		err := dst.Copy(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/bela333/replayReader"
+)
+
+//Metadata is the content of the metaData.json entry of a .mcpr archive.
+//Duration is the length of the recording and Date the time it started, both in milliseconds.
+//Keys this struct doesn't know are kept in Extra, so they survive editing.
+type Metadata struct {
+	Singleplayer      bool     `json:"singleplayer"`
+	ServerName        string   `json:"serverName"`
+	CustomServerName  string   `json:"customServerName,omitempty"`
+	Duration          int      `json:"duration"`
+	Date              int64    `json:"date"`
+	MCVersion         string   `json:"mcversion"`
+	FileFormat        string   `json:"fileFormat"`
+	FileFormatVersion int      `json:"fileFormatVersion"`
+	Protocol          int      `json:"protocol"`
+	Generator         string   `json:"generator"`
+	SelfID            int      `json:"selfId"`
+	Players           []string `json:"players"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+//The JSON keys of the fields of Metadata.
+var metadataKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	metadataType := reflect.TypeFor[Metadata]()
+	for i := 0; i < metadataType.NumField(); i++ {
+		name, _, _ := strings.Cut(metadataType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+//metadataFields has the fields of Metadata without its methods, so it can be marshalled the default way.
+type metadataFields Metadata
+
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, (*metadataFields)(m))
+	if err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return err
+	}
+	m.Extra = nil
+	for key, value := range all {
+		if metadataKeys[key] {
+			continue
+		}
+		if m.Extra == nil {
+			m.Extra = make(map[string]json.RawMessage)
+		}
+		m.Extra[key] = value
+	}
+	return nil
+}
+
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	//The Replay Mod expects a list of players, even an empty one.
+	if m.Players == nil {
+		m.Players = []string{}
+	}
+	data, err := json.Marshal(metadataFields(m))
+	if err != nil || len(m.Extra) == 0 {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(data, &all)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range m.Extra {
+		if !metadataKeys[key] {
+			all[key] = value
+		}
+	}
+	return json.Marshal(all)
+}
+
+//Returns the wall-clock time the recording started at, from Date.
+func (m *Metadata) StartTime() time.Time {
+	return time.UnixMilli(m.Date)
+}
+
+//Returns the wall-clock time of the replay time, in milliseconds, so events can be matched with server logs.
+func (m *Metadata) WallClock(replayTime int) time.Time {
+	return m.StartTime().Add(replayReader.ReplayDuration(replayTime))
+}
+
+//Returns the replay time, in milliseconds, of a wall-clock time. It is negative for times before the recording started.
+func (m *Metadata) ReplayTime(wallClock time.Time) int {
+	return replayReader.ReplayTime(wallClock.Sub(m.StartTime()))
+}
+
+//Decodes the content of a metaData.json from r.
+func ParseMetadata(r io.Reader) (*Metadata, error) {
+	var metadata Metadata
+	err := json.NewDecoder(r).Decode(&metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+//Reads the metaData.json entry of archive.
+func ReadMetadata(archive *zip.Reader) (*Metadata, error) {
+	return readMetadata(archive, "")
+}
+
+//Reads the metaData.json entry of archive. path is only used in errors.
+func readMetadata(archive *zip.Reader, path string) (*Metadata, error) {
+	entry, err := openEntry(archive, path, MetadataEntry)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	metadata, err := ParseMetadata(entry)
+	if err != nil {
+		return nil, &replayReader.ContainerError{Path: path, Entry: MetadataEntry, Err: err}
+	}
+	return metadata, nil
+}
+
+//Returns the length of the recording, from Duration.
+func (m *Metadata) Length() time.Duration {
+	return replayReader.ReplayDuration(m.Duration)
+}
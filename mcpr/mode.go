@@ -0,0 +1,58 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"encoding/json"
+
+	"github.com/bela333/replayReader"
+)
+
+//Metadata key set by recorders that store raw packets. The Replay Mod itself doesn't write it.
+//It is true for raw recordings, false for processed ones, and "setCompression" for raw recordings that start before Set Compression.
+const rawPacketsKey = "rawPackets"
+
+//Value of rawPacketsKey for ModeSetCompression recordings.
+const setCompressionValue = "setCompression"
+
+//Number of packets DetectMode looks at when the metadata doesn't tell the mode.
+const modeSample = 64
+
+//Returns the recording mode the metadata tells, or ModeUnknown if it doesn't say.
+func (m *Metadata) RecordingMode() replayReader.RecordingMode {
+	value, ok := m.Extra[rawPacketsKey]
+	if !ok {
+		return replayReader.ModeUnknown
+	}
+	var mode string
+	if json.Unmarshal(value, &mode) == nil {
+		if mode == setCompressionValue {
+			return replayReader.ModeSetCompression
+		}
+		return replayReader.ModeUnknown
+	}
+	var raw bool
+	if json.Unmarshal(value, &raw) != nil {
+		return replayReader.ModeUnknown
+	}
+	if raw {
+		return replayReader.ModeRaw
+	}
+	return replayReader.ModeProcessed
+}
+
+//Returns the recording mode of archive. The metadata is trusted if it tells the mode,
+//otherwise the first packets of the recording are looked at with replayReader.DetectMode.
+//Pass the result to replayReader.WithRecordingMode to read both kinds of recordings the same way.
+func DetectMode(archive *zip.Reader) (replayReader.RecordingMode, error) {
+	if metadata, err := ReadMetadata(archive); err == nil {
+		if mode := metadata.RecordingMode(); mode != replayReader.ModeUnknown {
+			return mode, nil
+		}
+	}
+	recording, err := openRecordingEntry(archive, "")
+	if err != nil {
+		return replayReader.ModeUnknown, err
+	}
+	defer recording.Close()
+	return replayReader.DetectMode(recording, modeSample)
+}
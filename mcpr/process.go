@@ -0,0 +1,169 @@
+package mcpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/bela333/replayReader"
+)
+
+//FileError is the error processing one replay failed with.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	//Errors about the archive already start with its path
+	var container *replayReader.ContainerError
+	if errors.As(e.Err, &container) && container.Path == e.Path {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+//ProcessError is returned by Process when some of the replays failed. Files holds one error for every one of them,
+//in the order of the paths. errors.Is and errors.As look through all of them.
+type ProcessError struct {
+	Files []*FileError
+}
+
+func (e *ProcessError) Error() string {
+	if len(e.Files) == 1 {
+		return e.Files[0].Error()
+	}
+	return fmt.Sprintf("%d replays failed, first: %v", len(e.Files), e.Files[0])
+}
+
+func (e *ProcessError) Unwrap() []error {
+	errs := make([]error, len(e.Files))
+	for i, file := range e.Files {
+		errs[i] = file
+	}
+	return errs
+}
+
+//Progress is passed to the progress function of Process after every replay.
+//Path and Err are the replay that was just processed and its error, Done counts every replay processed so far,
+//failed ones included.
+type Progress struct {
+	Path   string
+	Err    error
+	Done   int
+	Failed int
+	Total  int
+}
+
+//ProcessOption changes how Process works.
+type ProcessOption func(c *processConfig)
+
+type processConfig struct {
+	progress func(Progress)
+	options  []replayReader.Option
+}
+
+//Makes Process call progress after every replay. Calls never overlap, so progress doesn't have to be safe for concurrent use.
+func WithProgress(progress func(Progress)) ProcessOption {
+	return func(c *processConfig) {
+		c.progress = progress
+	}
+}
+
+//Makes Process create every Replay with options.
+func WithReplayOptions(options ...replayReader.Option) ProcessOption {
+	return func(c *processConfig) {
+		c.options = options
+	}
+}
+
+//Opens the .mcpr archive at path and calls fn with its recording and metadata.
+//A panic in fn is returned as an error, so one bad replay doesn't stop a whole batch.
+func processFile(path string, fn func(*replayReader.Replay, Metadata) error, options []replayReader.Option) (err error) {
+	archive, err := Open(path, options...)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	metadata, err := archive.Metadata()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return fn(archive.Replay, *metadata)
+}
+
+//Calls fn with the recording and metadata of every .mcpr archive in paths, processing workers of them at once.
+//If workers is 0 or less, it uses one worker per CPU. fn is called from several goroutines at once.
+//A failing replay doesn't stop the others: once every replay was processed, Process returns a *ProcessError
+//listing the ones that failed, or nil if none did.
+//If ctx is cancelled, no more replays are started and the error of ctx is returned, joined with the failures so far.
+func Process(ctx context.Context, paths []string, workers int, fn func(*replayReader.Replay, Metadata) error, options ...ProcessOption) error {
+	var config processConfig
+	for _, option := range options {
+		option(&config)
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var mutex sync.Mutex
+	progress := Progress{Total: len(paths)}
+	var wait sync.WaitGroup
+	for range min(workers, len(paths)) {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := range jobs {
+				errs[i] = processFile(paths[i], fn, config.options)
+				mutex.Lock()
+				progress.Path, progress.Err = paths[i], errs[i]
+				progress.Done++
+				if errs[i] != nil {
+					progress.Failed++
+				}
+				if config.progress != nil {
+					config.progress(progress)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+dispatch:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wait.Wait()
+
+	var failed ProcessError
+	for i, err := range errs {
+		if err != nil {
+			failed.Files = append(failed.Files, &FileError{Path: paths[i], Err: err})
+		}
+	}
+	var result error
+	if len(failed.Files) > 0 {
+		result = &failed
+	}
+	if ctx.Err() != nil {
+		return errors.Join(ctx.Err(), result)
+	}
+	return result
+}
@@ -0,0 +1,380 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/bela333/replayReader"
+)
+
+//A seekable recording is a recording split into frames of whole packets, each compressed on its own,
+//followed by a seek table listing the frames. Any part of the recording can be read by decompressing just the
+//frames that hold it, which gives random access while keeping most of the compression.
+//It follows the layout of the zstd seekable format, but its frames are DEFLATE streams: the standard library has
+//no zstd encoder or decoder, and this module has no dependencies outside of it. Since the entry isn't zstd, it isn't
+//called recording.tmcpr.zst either, so tools expecting zstd don't try to read it.
+//
+//Archives made seekable only have the seekable entry, unless the plain recording.tmcpr is kept on purpose.
+//The Replay Mod can't open them then, but every reader of this package can: they fall back to the seekable entry.
+//
+//The seek table is one entry per frame, then the number of frames and the magic number:
+//	compressed size uint32, decompressed size uint32, time of the first packet uint32, index of the first packet uint32
+//	frame count uint32, "RRSK"
+//All numbers are big-endian.
+
+//Name of the entry holding a seekable recording. It is stored without zip compression, so it can be read with ReadAt.
+const SeekableEntry = "recording.tmcpr.seekable"
+
+//Default amount of packet data in a frame of a seekable recording.
+const DefaultFrameSize = 1 << 20
+
+var seekableMagic = [4]byte{'R', 'R', 'S', 'K'}
+
+const seekTableEntryLen = 16
+
+//Returned when a seekable recording doesn't end with a valid seek table.
+var ErrInvalidSeekable = errors.New("not a seekable recording")
+
+//Frame is an entry of the seek table of a seekable recording.
+//Offset and DecompressedOffset are where the frame starts in the seekable recording and in the plain recording.
+//Time and Packet are the Time and Index of its first packet.
+type Frame struct {
+	Offset             int64
+	CompressedSize     int64
+	DecompressedOffset int64
+	DecompressedSize   int64
+	Time               int
+	Packet             int
+}
+
+//SeekTable lists the frames of a seekable recording in order.
+type SeekTable []Frame
+
+//Returns the position in the table of the last frame whose first packet is at or before time.
+func (table SeekTable) FrameAt(time int) int {
+	i := sort.Search(len(table), func(i int) bool {
+		return table[i].Time > time
+	})
+	return max(i-1, 0)
+}
+
+//Returns the length of the plain recording.
+func (table SeekTable) Size() int64 {
+	if len(table) == 0 {
+		return 0
+	}
+	last := table[len(table)-1]
+	return last.DecompressedOffset + last.DecompressedSize
+}
+
+//Compresses the plain recording in src into a seekable recording written to dst.
+//Frames are cut at the first packet boundary after frameSize bytes of packet data.
+func WriteSeekable(dst io.Writer, src io.Reader, frameSize int) (SeekTable, error) {
+	if frameSize <= 0 {
+		frameSize = DefaultFrameSize
+	}
+	output := bufio.NewWriter(dst)
+	var table SeekTable
+	var frame bytes.Buffer
+	var compressed bytes.Buffer
+	compressor, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	offset, decompressedOffset := int64(0), int64(0)
+	current := Frame{}
+
+	flush := func() error {
+		if frame.Len() == 0 {
+			return nil
+		}
+		compressed.Reset()
+		compressor.Reset(&compressed)
+		compressor.Write(frame.Bytes())
+		err := compressor.Close()
+		if err != nil {
+			return err
+		}
+		current.Offset = offset
+		current.CompressedSize = int64(compressed.Len())
+		current.DecompressedOffset = decompressedOffset
+		current.DecompressedSize = int64(frame.Len())
+		_, err = output.Write(compressed.Bytes())
+		if err != nil {
+			return err
+		}
+		table = append(table, current)
+		offset += current.CompressedSize
+		decompressedOffset += current.DecompressedSize
+		frame.Reset()
+		return nil
+	}
+
+	replay := replayReader.NewReplayFromReader(src)
+	header := make([]byte, 8)
+	var p replayReader.Packet
+	for replay.Next(&p) {
+		if frame.Len() == 0 {
+			current = Frame{Time: p.Time, Packet: p.Index}
+		}
+		binary.BigEndian.PutUint32(header, uint32(p.Time))
+		binary.BigEndian.PutUint32(header[4:], uint32(p.Len))
+		frame.Write(header)
+		_, err := io.Copy(&frame, p.Data)
+		if err != nil {
+			return table, err
+		}
+		if frame.Len() >= frameSize {
+			err = flush()
+			if err != nil {
+				return table, err
+			}
+		}
+	}
+	if err := replay.Error(); err != nil {
+		return table, err
+	}
+	err := flush()
+	if err != nil {
+		return table, err
+	}
+	for _, entry := range table {
+		binary.Write(output, binary.BigEndian, []uint32{uint32(entry.CompressedSize), uint32(entry.DecompressedSize), uint32(entry.Time), uint32(entry.Packet)})
+	}
+	binary.Write(output, binary.BigEndian, uint32(len(table)))
+	output.Write(seekableMagic[:])
+	return table, output.Flush()
+}
+
+//Reads the seek table at the end of the seekable recording in r, which is size bytes long.
+func ReadSeekTable(r io.ReaderAt, size int64) (SeekTable, error) {
+	footer := make([]byte, 8)
+	if size < 8 {
+		return nil, ErrInvalidSeekable
+	}
+	_, err := r.ReadAt(footer, size-8)
+	if err != nil {
+		return nil, err
+	}
+	if [4]byte(footer[4:]) != seekableMagic {
+		return nil, ErrInvalidSeekable
+	}
+	count := int64(binary.BigEndian.Uint32(footer))
+	tableStart := size - 8 - count*seekTableEntryLen
+	if tableStart < 0 {
+		return nil, ErrInvalidSeekable
+	}
+	data := make([]byte, count*seekTableEntryLen)
+	_, err = r.ReadAt(data, tableStart)
+	if err != nil {
+		return nil, err
+	}
+	table := make(SeekTable, count)
+	offset, decompressedOffset := int64(0), int64(0)
+	for i := range table {
+		entry := data[i*seekTableEntryLen:]
+		table[i] = Frame{
+			Offset:             offset,
+			CompressedSize:     int64(binary.BigEndian.Uint32(entry)),
+			DecompressedOffset: decompressedOffset,
+			DecompressedSize:   int64(binary.BigEndian.Uint32(entry[4:])),
+			Time:               int(binary.BigEndian.Uint32(entry[8:])),
+			Packet:             int(binary.BigEndian.Uint32(entry[12:])),
+		}
+		offset += table[i].CompressedSize
+		decompressedOffset += table[i].DecompressedSize
+	}
+	if offset != tableStart {
+		return nil, ErrInvalidSeekable
+	}
+	return table, nil
+}
+
+//SeekableReader reads the plain recording out of a seekable recording, decompressing only the frames it needs.
+//It is an io.ReadSeeker and an io.ReaderAt, so a Replay reading from it can be rewound and seeked.
+//ReadAt is safe for concurrent use, Read and Seek aren't.
+type SeekableReader struct {
+	source   io.ReaderAt
+	table    SeekTable
+	position int64
+
+	mutex       sync.Mutex
+	cachedFrame int
+	cached      []byte
+}
+
+//Opens the seekable recording in r, which is size bytes long.
+func NewSeekableReader(r io.ReaderAt, size int64) (*SeekableReader, error) {
+	table, err := ReadSeekTable(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return &SeekableReader{source: r, table: table, cachedFrame: -1}, nil
+}
+
+//Returns the seek table of the recording.
+func (s *SeekableReader) Table() SeekTable {
+	return s.table
+}
+
+//Returns the decompressed content of frame i.
+func (s *SeekableReader) frame(i int) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cachedFrame == i {
+		return s.cached, nil
+	}
+	frame := s.table[i]
+	compressed := io.NewSectionReader(s.source, frame.Offset, frame.CompressedSize)
+	data := make([]byte, frame.DecompressedSize)
+	_, err := io.ReadFull(flate.NewReader(compressed), data)
+	if err != nil {
+		return nil, err
+	}
+	s.cachedFrame, s.cached = i, data
+	return data, nil
+}
+
+func (s *SeekableReader) ReadAt(b []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	read := 0
+	for read < len(b) {
+		position := offset + int64(read)
+		if position >= s.table.Size() {
+			return read, io.EOF
+		}
+		i := sort.Search(len(s.table), func(i int) bool {
+			return s.table[i].DecompressedOffset+s.table[i].DecompressedSize > position
+		})
+		data, err := s.frame(i)
+		if err != nil {
+			return read, err
+		}
+		read += copy(b[read:], data[position-s.table[i].DecompressedOffset:])
+	}
+	return read, nil
+}
+
+func (s *SeekableReader) Read(b []byte) (int, error) {
+	n, err := s.ReadAt(b, s.position)
+	s.position += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (s *SeekableReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += s.position
+	case io.SeekEnd:
+		offset += s.table.Size()
+	}
+	if offset < 0 {
+		return s.position, errors.New("negative position")
+	}
+	s.position = offset
+	return offset, nil
+}
+
+//Does nothing, so a SeekableReader can be passed to replayReader.NewReplay.
+func (s *SeekableReader) Close() error {
+	return nil
+}
+
+//Makes the recording of the .mcpr archive at path seekable, replacing its recording.tmcpr with a seekable one.
+//If keepPlain is true, recording.tmcpr is kept next to the seekable recording, so the Replay Mod can still open
+//the archive, at the cost of storing the recording twice.
+func MakeSeekable(path string, frameSize int, keepPlain bool) error {
+	return rewrite(path, func(archive *zip.Reader, output *zip.Writer) error {
+		recording, err := openRecordingEntry(archive, path)
+		if err != nil {
+			return err
+		}
+		defer recording.Close()
+		err = copyEntries(output, archive, func(name string) bool {
+			return name == SeekableEntry || (name == RecordingEntry && !keepPlain)
+		})
+		if err != nil {
+			return err
+		}
+		writer, err := output.CreateHeader(&zip.FileHeader{Name: SeekableEntry, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		_, err = WriteSeekable(writer, recording, frameSize)
+		return err
+	})
+}
+
+//Returns the recording of archive, whose file is file. If the archive has a seekable recording,
+//it is returned as a *SeekableReader. Otherwise the plain recording.tmcpr entry is returned,
+//and it is seekable too if it is stored without compression.
+func OpenRecording(file io.ReaderAt, archive *zip.Reader) (io.ReadCloser, error) {
+	var plain *zip.File
+	for _, entry := range archive.File {
+		if entry.Name == RecordingEntry {
+			plain = entry
+		}
+		if entry.Name != SeekableEntry || entry.Method != zip.Store {
+			continue
+		}
+		offset, err := entry.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		return NewSeekableReader(io.NewSectionReader(file, offset, int64(entry.CompressedSize64)), int64(entry.CompressedSize64))
+	}
+	if plain != nil && plain.Method == zip.Store {
+		offset, err := plain.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		return storedRecording{io.NewSectionReader(file, offset, int64(plain.UncompressedSize64))}, nil
+	}
+	return openEntry(archive, "", RecordingEntry)
+}
+
+//Returns the recording of archive when the file under it isn't known: the plain recording.tmcpr if there is one,
+//or else the seekable recording, which is then read into memory. path is only used in errors.
+func openRecordingEntry(archive *zip.Reader, path string) (io.ReadCloser, error) {
+	if hasEntry(archive, RecordingEntry) || !hasEntry(archive, SeekableEntry) {
+		return openEntry(archive, path, RecordingEntry)
+	}
+	entry, err := openEntry(archive, path, SeekableEntry)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	content, err := io.ReadAll(entry)
+	if err != nil {
+		return nil, &replayReader.ContainerError{Path: path, Entry: SeekableEntry, Err: err}
+	}
+	return NewSeekableReader(bytes.NewReader(content), int64(len(content)))
+}
+
+//Reports whether archive has an entry called name.
+func hasEntry(archive *zip.Reader, name string) bool {
+	for _, entry := range archive.File {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+//storedRecording is a recording stored without compression, read straight from the archive.
+type storedRecording struct {
+	*io.SectionReader
+}
+
+func (storedRecording) Close() error {
+	return nil
+}
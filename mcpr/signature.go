@@ -0,0 +1,134 @@
+package mcpr
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/bela333/replayReader"
+)
+
+//Name of the entry holding the signature of a signed archive.
+const SignatureEntry = "recording.tmcpr.sig"
+
+var (
+	//Returned by VerifySignature when the archive isn't signed.
+	ErrNotSigned = errors.New("replay is not signed")
+	//Returned by VerifySignature when the signature doesn't match the recording or the key.
+	ErrBadSignature = errors.New("signature doesn't match the replay")
+	//Returned by RecordingHash when the recording is encrypted, since the hash is the one of the decrypted recording.
+	ErrHashEncrypted = errors.New("replay is encrypted, decrypt it before hashing it")
+)
+
+//Signature is the content of the signature entry.
+//Hash is the SHA-256 of the uncompressed recording.tmcpr, so recompressing the archive doesn't break the signature.
+//Signature is the ed25519 signature of Hash, made with the private key of PublicKey.
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey []byte `json:"publicKey"`
+	Hash      []byte `json:"hash"`
+	Signature []byte `json:"signature"`
+}
+
+//Returns the SHA-256 of the uncompressed recording of archive.
+//The recording is found like OpenRecording does, so archives made seekable give the same hash.
+func RecordingHash(archive *zip.Reader) ([]byte, error) {
+	recording, err := openRecordingEntry(archive, "")
+	if errors.Is(err, fs.ErrNotExist) && hasEntry(archive, EncryptedEntry) {
+		return nil, ErrHashEncrypted
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer recording.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, recording)
+	if err != nil {
+		return nil, &replayReader.ContainerError{Entry: RecordingEntry, Err: err}
+	}
+	return hash.Sum(nil), nil
+}
+
+//Copies the archive src to dst, adding a signature of its recording made with key.
+//An existing signature is replaced.
+func Sign(dst io.Writer, src *zip.Reader, key ed25519.PrivateKey) error {
+	hash, err := RecordingHash(src)
+	if err != nil {
+		return err
+	}
+	signature := Signature{
+		Algorithm: "ed25519-sha256",
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Hash:      hash,
+		Signature: ed25519.Sign(key, hash),
+	}
+	archive := zip.NewWriter(dst)
+	err = copyEntries(archive, src, func(name string) bool {
+		return name == SignatureEntry
+	})
+	if err != nil {
+		return err
+	}
+	entry, err := archive.Create(SignatureEntry)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(entry).Encode(signature)
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+//Reads the signature of archive.
+func ReadSignature(archive *zip.Reader) (*Signature, error) {
+	entry, err := archive.Open(SignatureEntry)
+	if err != nil {
+		return nil, ErrNotSigned
+	}
+	defer entry.Close()
+	var signature Signature
+	err = json.NewDecoder(entry).Decode(&signature)
+	if err != nil {
+		return nil, &replayReader.ContainerError{Entry: SignatureEntry, Err: err}
+	}
+	return &signature, nil
+}
+
+//Checks that archive has a signature made with the private key of key, and that its recording hasn't changed since.
+func VerifySignature(archive *zip.Reader, key ed25519.PublicKey) error {
+	signature, err := ReadSignature(archive)
+	if err != nil {
+		return err
+	}
+	if signature.Algorithm != "ed25519-sha256" || !key.Equal(ed25519.PublicKey(signature.PublicKey)) {
+		return ErrBadSignature
+	}
+	hash, err := RecordingHash(archive)
+	if err != nil {
+		return err
+	}
+	if string(hash) != string(signature.Hash) || !ed25519.Verify(key, hash, signature.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+//Opens the archive at path like Open, and verifies its signature with key. The options are passed to the Replay.
+//The archive is only returned if the signature is right.
+func OpenVerified(path string, key ed25519.PublicKey, options ...replayReader.Option) (*Archive, error) {
+	archive, err := Open(path, options...)
+	if err != nil {
+		return nil, err
+	}
+	err = VerifySignature(archive.Zip, key)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return archive, nil
+}
@@ -0,0 +1,245 @@
+//Package metadata decodes entity metadata, the list of indexed values that tells the state of an entity,
+//like whether it is on fire, its custom name, its health or the item it shows.
+//What an index means depends on the kind of entity and the protocol version, this package only decodes the values.
+package metadata
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/item"
+	"github.com/bela333/replayReader/nbt"
+)
+
+//Range of protocol versions ReadEntityMetadata supports, from 1.8 to 1.20.2.
+const (
+	ProtocolOldest = 47
+	ProtocolNewest = 764
+)
+
+var (
+	//Returned for a protocol version ReadEntityMetadata doesn't support.
+	ErrUnsupportedProtocol = errors.New("entity metadata of this protocol version can't be decoded")
+	//Returned for particles, whose data depends on the particle type. The rest of the metadata can't be read.
+	ErrParticle = errors.New("particle metadata can't be decoded")
+)
+
+//TypeError is returned for a type ID that doesn't exist in the protocol version.
+type TypeError struct {
+	Index int
+	ID    int
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("metadata index %d has unknown type %d", e.Index, e.ID)
+}
+
+//Entry is a metadata value. See Type for the Go type of Value.
+type Entry struct {
+	Index int
+	Type  Type
+	Value any
+}
+
+//Rotation is the rotation of an armor stand part, in degrees.
+type Rotation struct {
+	X, Y, Z float32
+}
+
+//VillagerData is the type, profession and level of a villager.
+type VillagerData struct {
+	Type, Profession, Level int
+}
+
+//GlobalPos is a position in a dimension, like where a compass points to.
+type GlobalPos struct {
+	Dimension string
+	Position  replayReader.Position
+}
+
+//Vector3 is the translation or scale of a display entity.
+type Vector3 struct {
+	X, Y, Z float32
+}
+
+//Quaternion is the rotation of a display entity.
+type Quaternion struct {
+	X, Y, Z, W float32
+}
+
+//Reads entity metadata, the way protocol sends it, up to its terminator.
+//On an error the entries decoded before it are returned.
+func ReadEntityMetadata(p *replayReader.Packet, protocol int) ([]Entry, error) {
+	types := typeTable(protocol)
+	if types == nil {
+		return nil, ErrUnsupportedProtocol
+	}
+	var entries []Entry
+	for {
+		var entry Entry
+		var id int
+		if protocol < typeTables[0].protocol {
+			key, err := p.ReaduByte()
+			if err != nil || key == 0x7F {
+				return entries, err
+			}
+			entry.Index, id = int(key&0x1F), int(key>>5)
+		} else {
+			index, err := p.ReaduByte()
+			if err != nil || index == 0xFF {
+				return entries, err
+			}
+			entry.Index = int(index)
+			id, _, err = p.ReadVarInt()
+			if err != nil {
+				return entries, err
+			}
+		}
+		if id < 0 || id >= len(types) {
+			return entries, &TypeError{Index: entry.Index, ID: id}
+		}
+		entry.Type = types[id]
+		var err error
+		entry.Value, err = readValue(p, protocol, entry.Type)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+//Returns the entry with index, ok is false if entries don't have it.
+func Find(entries []Entry, index int) (entry Entry, ok bool) {
+	for _, entry := range entries {
+		if entry.Index == index {
+			return entry, true
+		}
+	}
+	return entry, false
+}
+
+func readValue(p *replayReader.Packet, protocol int, t Type) (any, error) {
+	switch t {
+	case TypeByte:
+		return p.ReaduByte()
+	case TypeShort:
+		return p.ReadShort()
+	case TypeInt:
+		return p.ReadInt()
+	case TypeVarInt, TypeDirection, TypeBlockState, TypeOptBlockState, TypePose, TypeCatVariant,
+		TypeFrogVariant, TypePaintingVariant, TypeSnifferState:
+		value, _, err := p.ReadVarInt()
+		return value, err
+	case TypeVarLong:
+		value, _, err := p.ReadVarLong64()
+		return value, err
+	case TypeFloat:
+		return p.ReadFloat()
+	case TypeString:
+		value, _, err := p.ReadString()
+		return value, err
+	case TypeChat:
+		value, _, err := p.ReadChat()
+		return value, err
+	case TypeOptChat:
+		present, err := p.ReadBool()
+		if err != nil || !present {
+			return (*replayReader.ChatComponent)(nil), err
+		}
+		value, _, err := p.ReadChat()
+		return &value, err
+	case TypeSlot:
+		return item.ReadSlot(p, protocol)
+	case TypeBool:
+		return p.ReadBool()
+	case TypeRotation:
+		var rotation Rotation
+		err := readFloats(p, &rotation.X, &rotation.Y, &rotation.Z)
+		return rotation, err
+	case TypePosition:
+		if protocol < typeTables[0].protocol {
+			return readIntPosition(p)
+		}
+		return p.ReadPosition(protocol)
+	case TypeOptPosition:
+		present, err := p.ReadBool()
+		if err != nil || !present {
+			return (*replayReader.Position)(nil), err
+		}
+		value, err := p.ReadPosition(protocol)
+		return &value, err
+	case TypeOptUUID:
+		present, err := p.ReadBool()
+		if err != nil || !present {
+			return (*replayReader.UUID)(nil), err
+		}
+		value, err := p.ReadUUID()
+		return &value, err
+	case TypeNBT:
+		return nbt.Read(p, protocol)
+	case TypeVillagerData:
+		var data VillagerData
+		for _, value := range []*int{&data.Type, &data.Profession, &data.Level} {
+			var err error
+			*value, _, err = p.ReadVarInt()
+			if err != nil {
+				return data, err
+			}
+		}
+		return data, nil
+	case TypeOptVarInt:
+		//0 means there is no value, others are sent one higher
+		value, _, err := p.ReadVarInt()
+		if err != nil || value == 0 {
+			return (*int)(nil), err
+		}
+		value--
+		return &value, nil
+	case TypeOptGlobalPos:
+		present, err := p.ReadBool()
+		if err != nil || !present {
+			return (*GlobalPos)(nil), err
+		}
+		var pos GlobalPos
+		pos.Dimension, _, err = p.ReadString()
+		if err != nil {
+			return &pos, err
+		}
+		pos.Position, err = p.ReadPosition(protocol)
+		return &pos, err
+	case TypeVector3:
+		var vector Vector3
+		err := readFloats(p, &vector.X, &vector.Y, &vector.Z)
+		return vector, err
+	case TypeQuaternion:
+		var quaternion Quaternion
+		err := readFloats(p, &quaternion.X, &quaternion.Y, &quaternion.Z, &quaternion.W)
+		return quaternion, err
+	}
+	return nil, ErrParticle
+}
+
+func readFloats(p *replayReader.Packet, values ...*float32) error {
+	for _, value := range values {
+		var err error
+		*value, err = p.ReadFloat()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Reads a position sent as three Ints, like 1.8 does in metadata.
+func readIntPosition(p *replayReader.Packet) (replayReader.Position, error) {
+	var position replayReader.Position
+	for _, value := range []*int{&position.X, &position.Y, &position.Z} {
+		coordinate, err := p.ReadInt()
+		if err != nil {
+			return position, err
+		}
+		*value = int(coordinate)
+	}
+	return position, nil
+}
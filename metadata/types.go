@@ -0,0 +1,115 @@
+package metadata
+
+//Type is the type of a metadata value. The same Type is used for every protocol version,
+//even though the IDs they are sent with changed.
+type Type int
+
+//Types of metadata values, and the Go type of their Value.
+const (
+	TypeByte            Type = iota //byte
+	TypeShort                       //int16, before 1.9
+	TypeInt                         //int32, before 1.9
+	TypeVarInt                      //int
+	TypeVarLong                     //int64
+	TypeFloat                       //float32
+	TypeString                      //string
+	TypeChat                        //replayReader.ChatComponent
+	TypeOptChat                     //*replayReader.ChatComponent
+	TypeSlot                        //item.Slot
+	TypeBool                        //bool
+	TypeRotation                    //Rotation
+	TypePosition                    //replayReader.Position
+	TypeOptPosition                 //*replayReader.Position
+	TypeDirection                   //int
+	TypeOptUUID                     //*replayReader.UUID
+	TypeBlockState                  //int
+	TypeOptBlockState               //int, 0 for none
+	TypeNBT                         //any, see the nbt package
+	TypeParticle                    //can't be decoded
+	TypeVillagerData                //VillagerData
+	TypeOptVarInt                   //*int
+	TypePose                        //int
+	TypeCatVariant                  //int
+	TypeFrogVariant                 //int
+	TypeOptGlobalPos                //*GlobalPos
+	TypePaintingVariant             //int
+	TypeSnifferState                //int
+	TypeVector3                     //Vector3
+	TypeQuaternion                  //Quaternion
+)
+
+var typeNames = [...]string{
+	TypeByte: "byte", TypeShort: "short", TypeInt: "int", TypeVarInt: "varint", TypeVarLong: "varlong",
+	TypeFloat: "float", TypeString: "string", TypeChat: "chat", TypeOptChat: "optional chat", TypeSlot: "slot",
+	TypeBool: "boolean", TypeRotation: "rotation", TypePosition: "position", TypeOptPosition: "optional position",
+	TypeDirection: "direction", TypeOptUUID: "optional UUID", TypeBlockState: "block state",
+	TypeOptBlockState: "optional block state", TypeNBT: "NBT", TypeParticle: "particle",
+	TypeVillagerData: "villager data", TypeOptVarInt: "optional varint", TypePose: "pose",
+	TypeCatVariant: "cat variant", TypeFrogVariant: "frog variant", TypeOptGlobalPos: "optional global position",
+	TypePaintingVariant: "painting variant", TypeSnifferState: "sniffer state", TypeVector3: "vector3",
+	TypeQuaternion: "quaternion",
+}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return "unknown"
+	}
+	return typeNames[t]
+}
+
+//Types by the ID they are sent with, for the protocol versions since 1.9.
+//Each table is used from its protocol version until the next one.
+var typeTables = []struct {
+	protocol int
+	types    []Type
+}{
+	//1.9
+	{107, []Type{TypeByte, TypeVarInt, TypeFloat, TypeString, TypeChat, TypeSlot, TypeBool, TypeRotation,
+		TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeOptBlockState}},
+	//1.12
+	{335, []Type{TypeByte, TypeVarInt, TypeFloat, TypeString, TypeChat, TypeSlot, TypeBool, TypeRotation,
+		TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeOptBlockState, TypeNBT}},
+	//1.13
+	{393, []Type{TypeByte, TypeVarInt, TypeFloat, TypeString, TypeChat, TypeOptChat, TypeSlot, TypeBool,
+		TypeRotation, TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeOptBlockState, TypeNBT,
+		TypeParticle}},
+	//1.14
+	{477, []Type{TypeByte, TypeVarInt, TypeFloat, TypeString, TypeChat, TypeOptChat, TypeSlot, TypeBool,
+		TypeRotation, TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeOptBlockState, TypeNBT,
+		TypeParticle, TypeVillagerData, TypeOptVarInt, TypePose}},
+	//1.19
+	{759, []Type{TypeByte, TypeVarInt, TypeFloat, TypeString, TypeChat, TypeOptChat, TypeSlot, TypeBool,
+		TypeRotation, TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeOptBlockState, TypeNBT,
+		TypeParticle, TypeVillagerData, TypeOptVarInt, TypePose, TypeCatVariant, TypeFrogVariant,
+		TypeOptGlobalPos, TypePaintingVariant}},
+	//1.19.3
+	{761, []Type{TypeByte, TypeVarInt, TypeVarLong, TypeFloat, TypeString, TypeChat, TypeOptChat, TypeSlot,
+		TypeBool, TypeRotation, TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeOptBlockState,
+		TypeNBT, TypeParticle, TypeVillagerData, TypeOptVarInt, TypePose, TypeCatVariant, TypeFrogVariant,
+		TypeOptGlobalPos, TypePaintingVariant}},
+	//1.19.4
+	{762, []Type{TypeByte, TypeVarInt, TypeVarLong, TypeFloat, TypeString, TypeChat, TypeOptChat, TypeSlot,
+		TypeBool, TypeRotation, TypePosition, TypeOptPosition, TypeDirection, TypeOptUUID, TypeBlockState,
+		TypeOptBlockState, TypeNBT, TypeParticle, TypeVillagerData, TypeOptVarInt, TypePose, TypeCatVariant,
+		TypeFrogVariant, TypeOptGlobalPos, TypePaintingVariant, TypeSnifferState, TypeVector3, TypeQuaternion}},
+}
+
+//Types of 1.8, where the type is sent in the top 3 bits of the index.
+var legacyTypes = []Type{TypeByte, TypeShort, TypeInt, TypeFloat, TypeString, TypeSlot, TypePosition, TypeRotation}
+
+//Returns the types by ID of protocol, or nil if it isn't supported.
+func typeTable(protocol int) []Type {
+	if protocol < ProtocolOldest || protocol > ProtocolNewest {
+		return nil
+	}
+	if protocol < typeTables[0].protocol {
+		return legacyTypes
+	}
+	var types []Type
+	for _, table := range typeTables {
+		if protocol >= table.protocol {
+			types = table.types
+		}
+	}
+	return types
+}
@@ -0,0 +1,120 @@
+package replayReader
+
+import (
+	"runtime/metrics"
+	"time"
+)
+
+//Stats holds the counters collected by a Replay created with WithMetering.
+//Packets is the number of packets returned by Next.
+//BytesRead is the number of bytes read from the replay file, headers included.
+//BytesSkipped is the number of bytes of data a Replay with WithLazyPayloads skipped without using them.
+//HeaderTime and PayloadTime are the time spent reading the headers and the data of the packets.
+//Allocs and AllocBytes are the heap allocations made since metering started.
+//They are sampled from runtime/metrics, so they count the allocations of the whole program, not only this Replay.
+type Stats struct {
+	Packets      int64
+	BytesRead    int64
+	BytesSkipped int64
+	HeaderTime   time.Duration
+	PayloadTime  time.Duration
+	Allocs       uint64
+	AllocBytes   uint64
+}
+
+//Makes the Replay count packets, bytes, time and allocations. The counters can be read with r.Stats().
+//Metering adds two clock reads per stage to every Next(), so it is off by default.
+func WithMetering() Option {
+	return func(r *Replay) {
+		r.meter = &meter{}
+		r.meter.allocs, r.meter.allocBytes = sampleAllocs()
+	}
+}
+
+//Returns the counters collected so far. If the Replay was created without WithMetering, it returns a zero Stats.
+func (r *Replay) Stats() Stats {
+	if r.meter == nil {
+		return Stats{}
+	}
+	stats := r.meter.stats
+	allocs, allocBytes := sampleAllocs()
+	stats.Allocs = allocs - r.meter.allocs
+	stats.AllocBytes = allocBytes - r.meter.allocBytes
+	return stats
+}
+
+//Sets every counter of r.Stats() back to zero.
+func (r *Replay) ResetStats() {
+	if r.meter == nil {
+		return
+	}
+	*r.meter = meter{}
+	r.meter.allocs, r.meter.allocBytes = sampleAllocs()
+}
+
+//meter collects Stats. All of its methods can be called on a nil *meter, in which case they do nothing.
+type meter struct {
+	stats      Stats
+	allocs     uint64
+	allocBytes uint64
+}
+
+func (m *meter) now() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+//Counts a header of size bytes, which is longer with checksum framing, read since start.
+func (m *meter) header(start time.Time, size int) {
+	if m == nil {
+		return
+	}
+	m.stats.HeaderTime += time.Since(start)
+	m.stats.BytesRead += int64(size)
+}
+
+//Counts len bytes of data read since start.
+func (m *meter) payload(start time.Time, len int) {
+	if m == nil {
+		return
+	}
+	m.stats.PayloadTime += time.Since(start)
+	m.stats.BytesRead += int64(len)
+}
+
+//Counts a packet returned by Next.
+func (m *meter) packet() {
+	if m == nil {
+		return
+	}
+	m.stats.Packets++
+}
+
+//Counts len bytes of data skipped without being used.
+func (m *meter) skip(len int64) {
+	if m == nil {
+		return
+	}
+	m.stats.BytesSkipped += len
+}
+
+var allocSamples = []metrics.Sample{
+	{Name: "/gc/heap/allocs:objects"},
+	{Name: "/gc/heap/allocs:bytes"},
+}
+
+//Returns the number of heap allocations and allocated bytes since the program started.
+func sampleAllocs() (allocs uint64, allocBytes uint64) {
+	samples := make([]metrics.Sample, len(allocSamples))
+	copy(samples, allocSamples)
+	metrics.Read(samples)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		allocs = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		allocBytes = samples[1].Value.Uint64()
+	}
+	return allocs, allocBytes
+}
@@ -0,0 +1,66 @@
+package nbt
+
+import (
+	"encoding/json"
+
+	"github.com/bela333/replayReader"
+)
+
+//First protocol version, 1.20.3, that sends text components as NBT instead of JSON strings.
+const ProtocolChatNBT = 765
+
+//Reads a text component the way protocol sends it: as NBT since 1.20.3, as a JSON string before.
+func ReadChat(p *replayReader.Packet, protocol int) (replayReader.ChatComponent, error) {
+	if protocol < ProtocolChatNBT {
+		component, _, err := p.ReadChat()
+		return component, err
+	}
+	tree, err := Read(p, protocol)
+	if err != nil {
+		return replayReader.ChatComponent{}, err
+	}
+	return Chat(tree)
+}
+
+//Converts a text component decoded by Read into a ChatComponent.
+//Plain strings are components with only a text, like in JSON.
+func Chat(tree any) (replayReader.ChatComponent, error) {
+	data, err := json.Marshal(jsonValue(tree))
+	if err != nil {
+		return replayReader.ChatComponent{}, err
+	}
+	return replayReader.ParseChat(string(data))
+}
+
+//Returns tree the way it would look as JSON. Bytes are the booleans of the styles,
+//and compounds with only an empty key are the elements of lists mixing types, which NBT doesn't allow.
+func jsonValue(tree any) any {
+	switch value := tree.(type) {
+	case int8:
+		if value == 0 || value == 1 {
+			return value == 1
+		}
+	case []byte:
+		values := make([]int, len(value))
+		for i, b := range value {
+			values[i] = int(int8(b))
+		}
+		return values
+	case []any:
+		values := make([]any, len(value))
+		for i, element := range value {
+			values[i] = jsonValue(element)
+		}
+		return values
+	case map[string]any:
+		if inner, ok := value[""]; ok && len(value) == 1 {
+			return jsonValue(inner)
+		}
+		values := make(map[string]any, len(value))
+		for key, element := range value {
+			values[key] = jsonValue(element)
+		}
+		return values
+	}
+	return tree
+}
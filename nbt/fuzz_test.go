@@ -0,0 +1,27 @@
+package nbt_test
+
+import (
+	"testing"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/nbt"
+	"github.com/bela333/replayReader/replaytest"
+)
+
+func FuzzRead(f *testing.F) {
+	//A compound named "" holding the int a = 5, a list of compounds nested in each other and a list that says it is huge
+	f.Add([]byte{0x0A, 0x00, 0x00, 0x03, 0x00, 0x01, 'a', 0x00, 0x00, 0x00, 0x05, 0x00})
+	f.Add([]byte{0x09, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x01, 0x09, 0x00, 0x00, 0x00, 0x01, 0x09})
+	f.Add([]byte{0x09, 0x00, 0x00, 0x01, 0x7F, 0xFF, 0xFF, 0xFF})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file := replaytest.NewReplay().Add(0, data).Bytes()
+		for _, protocol := range []int{578, 764} {
+			replay := replayReader.NewReplayFromBytes(file, replayReader.WithLimits(replayReader.Limits{MaxArrayLen: 1 << 16, MaxNBTDepth: 64}))
+			var packet replayReader.Packet
+			if !replay.Next(&packet) {
+				t.Fatal(replay.Error())
+			}
+			nbt.Read(&packet, protocol)
+		}
+	})
+}
@@ -0,0 +1,291 @@
+//Package nbt decodes the NBT data embedded in packets, like item tags, heightmaps and registry codecs.
+//Tags are decoded into Go values: int8, int16, int32, int64, float32 and float64 for the numbers, string,
+//[]byte, []int32 and []int64 for the arrays, []any for lists and map[string]any for compounds.
+//Unmarshal copies such a tree into a struct.
+package nbt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bela333/replayReader"
+)
+
+//Tag types.
+const (
+	TagEnd byte = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
+)
+
+//First protocol version, 1.20.2, that sends NBT without a name for its root tag.
+const ProtocolNamelessRoot = 764
+
+//Deepest nesting followed when the Replay has no MaxNBTDepth limit, so broken data can't make decoding recurse forever.
+const DefaultMaxDepth = 512
+
+var (
+	//Returned for NBT nested deeper than the limit.
+	ErrTooDeep = errors.New("NBT is nested too deep")
+	//Returned for a tag type that doesn't exist.
+	ErrInvalidType = errors.New("invalid NBT tag type")
+)
+
+//Sizes of the tags with a fixed size, by tag type.
+var sizes = [...]int{TagByte: 1, TagShort: 2, TagInt: 4, TagLong: 8, TagFloat: 4, TagDouble: 8}
+
+//Reads the NBT at the position of p, the way protocol sends it, and returns its root tag.
+//Before 1.20.2 the root tag has a name, which is dropped. A TagEnd in place of the root means there is no NBT, it returns nil.
+func Read(p *replayReader.Packet, protocol int) (any, error) {
+	tagType, err := readRoot(p, protocol)
+	if err != nil || tagType == TagEnd {
+		return nil, err
+	}
+	return readPayload(p, tagType, maxDepth(p), 0)
+}
+
+//Works like Read, but the root tag has to be a compound. It returns nil if there is no NBT.
+func ReadCompound(p *replayReader.Packet, protocol int) (map[string]any, error) {
+	tagType, err := readRoot(p, protocol)
+	if err != nil || tagType == TagEnd {
+		return nil, err
+	}
+	if tagType != TagCompound {
+		return nil, fmt.Errorf("root tag has type %d instead of a compound", tagType)
+	}
+	value, err := readPayload(p, tagType, maxDepth(p), 0)
+	compound, _ := value.(map[string]any)
+	return compound, err
+}
+
+//Skips the NBT at the position of p, the way protocol sends it, without decoding it.
+func Skip(p *replayReader.Packet, protocol int) error {
+	tagType, err := readRoot(p, protocol)
+	if err != nil || tagType == TagEnd {
+		return err
+	}
+	return skipPayload(p, tagType, maxDepth(p), 0)
+}
+
+//Reads the type of the root tag, and its name on versions that send one.
+func readRoot(p *replayReader.Packet, protocol int) (byte, error) {
+	tagType, err := p.ReaduByte()
+	if err != nil || tagType == TagEnd {
+		return tagType, err
+	}
+	if tagType > TagLongArray {
+		return tagType, ErrInvalidType
+	}
+	if protocol < ProtocolNamelessRoot {
+		err = skipString(p)
+	}
+	return tagType, err
+}
+
+func maxDepth(p *replayReader.Packet) int {
+	if depth := p.Limits().MaxNBTDepth; depth > 0 {
+		return depth
+	}
+	return DefaultMaxDepth
+}
+
+//Reads a string, prefixed with its length as an unsigned short.
+//NBT strings are in Java's modified UTF-8, which is the same as UTF-8 for everything but NUL and characters outside the BMP.
+func readString(p *replayReader.Packet) (string, error) {
+	length, err := p.ReaduShort()
+	if err != nil {
+		return "", err
+	}
+	data, _, err := p.ReaduByteArray(int(length))
+	return string(data), err
+}
+
+func skipString(p *replayReader.Packet) error {
+	length, err := p.ReaduShort()
+	if err != nil {
+		return err
+	}
+	return p.Skip(int(length))
+}
+
+//Reads the length of an array or list, which can't be negative.
+func readLength(p *replayReader.Packet) (int, error) {
+	length, err := p.ReadInt()
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, replayReader.ErrInvalidLength
+	}
+	return int(length), nil
+}
+
+func readPayload(p *replayReader.Packet, tagType byte, max, depth int) (any, error) {
+	if depth > max {
+		return nil, ErrTooDeep
+	}
+	switch tagType {
+	case TagByte:
+		return p.ReadByte()
+	case TagShort:
+		return p.ReadShort()
+	case TagInt:
+		return p.ReadInt()
+	case TagLong:
+		return p.ReadLong()
+	case TagFloat:
+		return p.ReadFloat()
+	case TagDouble:
+		return p.ReadDouble()
+	case TagByteArray:
+		length, err := readLength(p)
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := p.ReaduByteArray(length)
+		return data, err
+	case TagString:
+		return readString(p)
+	case TagList:
+		elementType, err := p.ReaduByte()
+		if err != nil {
+			return nil, err
+		}
+		length, err := readLength(p)
+		if err != nil {
+			return nil, err
+		}
+		if elementType > TagLongArray || (elementType == TagEnd && length > 0) {
+			return nil, ErrInvalidType
+		}
+		//Every element takes at least a byte, so the rest of the packet bounds how many there can be
+		list := make([]any, 0, min(length, p.Remaining()))
+		for i := 0; i < length; i++ {
+			element, err := readPayload(p, elementType, max, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, element)
+		}
+		return list, nil
+	case TagCompound:
+		compound := make(map[string]any)
+		for {
+			childType, err := p.ReaduByte()
+			if err != nil {
+				return nil, err
+			}
+			if childType == TagEnd {
+				return compound, nil
+			}
+			if childType > TagLongArray {
+				return nil, ErrInvalidType
+			}
+			name, err := readString(p)
+			if err != nil {
+				return nil, err
+			}
+			compound[name], err = readPayload(p, childType, max, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case TagIntArray:
+		length, err := readLength(p)
+		if err != nil {
+			return nil, err
+		}
+		if length*4 > p.Remaining() {
+			return nil, replayReader.ErrPacketTruncated
+		}
+		array := make([]int32, length)
+		for i := range array {
+			array[i], err = p.ReadInt()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	case TagLongArray:
+		length, err := readLength(p)
+		if err != nil {
+			return nil, err
+		}
+		if length*8 > p.Remaining() {
+			return nil, replayReader.ErrPacketTruncated
+		}
+		array := make([]int64, length)
+		for i := range array {
+			array[i], err = p.ReadLong()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	}
+	return nil, ErrInvalidType
+}
+
+func skipPayload(p *replayReader.Packet, tagType byte, max, depth int) error {
+	if depth > max {
+		return ErrTooDeep
+	}
+	if int(tagType) < len(sizes) && sizes[tagType] > 0 {
+		return p.Skip(sizes[tagType])
+	}
+	switch tagType {
+	case TagByteArray, TagIntArray, TagLongArray:
+		length, err := readLength(p)
+		if err != nil {
+			return err
+		}
+		return p.Skip(length * map[byte]int{TagByteArray: 1, TagIntArray: 4, TagLongArray: 8}[tagType])
+	case TagString:
+		return skipString(p)
+	case TagList:
+		elementType, err := p.ReaduByte()
+		if err != nil {
+			return err
+		}
+		length, err := readLength(p)
+		if err != nil {
+			return err
+		}
+		if elementType > TagLongArray || (elementType == TagEnd && length > 0) {
+			return ErrInvalidType
+		}
+		for i := 0; i < length; i++ {
+			err = skipPayload(p, elementType, max, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case TagCompound:
+		for {
+			childType, err := p.ReaduByte()
+			if err != nil || childType == TagEnd {
+				return err
+			}
+			err = skipString(p)
+			if err != nil {
+				return err
+			}
+			err = skipPayload(p, childType, max, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return ErrInvalidType
+}
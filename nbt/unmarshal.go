@@ -0,0 +1,174 @@
+package nbt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//Copies a tree decoded by Read into v, which has to be a non-nil pointer.
+//Compounds go into structs and maps with string keys, lists and arrays into slices, and numbers into any numeric type,
+//as long as they fit. Bytes can also go into bools. The fields of a struct are matched by their nbt tag, like `nbt:"Count"`,
+//or by their name, ignoring case. Fields tagged `nbt:"-"` and keys without a field are left out.
+func Unmarshal(tree any, v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("nbt: Unmarshal needs a non-nil pointer, got %T", v)
+	}
+	return unmarshal(tree, value.Elem(), "")
+}
+
+//UnmarshalError tells which part of a tree didn't fit into the value it was unmarshalled into.
+//Path is where in the tree it is, like Inventory[2].id.
+type UnmarshalError struct {
+	Path  string
+	Value any
+	Type  reflect.Type
+}
+
+func (e *UnmarshalError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "root"
+	}
+	return fmt.Sprintf("nbt: can't unmarshal %T at %s into %v", e.Value, path, e.Type)
+}
+
+func unmarshal(tree any, v reflect.Value, path string) error {
+	if tree == nil {
+		return nil
+	}
+	mismatch := &UnmarshalError{Path: path, Value: tree, Type: v.Type()}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshal(tree, v.Elem(), path)
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(tree))
+		return nil
+	}
+
+	switch tree := tree.(type) {
+	case int8, int16, int32, int64:
+		number := reflect.ValueOf(tree).Int()
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(number) {
+				return mismatch
+			}
+			v.SetInt(number)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if number < 0 || v.OverflowUint(uint64(number)) {
+				return mismatch
+			}
+			v.SetUint(uint64(number))
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(number))
+		case reflect.Bool:
+			v.SetBool(number != 0)
+		default:
+			return mismatch
+		}
+	case float32, float64:
+		if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+			return mismatch
+		}
+		v.SetFloat(reflect.ValueOf(tree).Float())
+	case string:
+		if v.Kind() != reflect.String {
+			return mismatch
+		}
+		v.SetString(tree)
+	case []byte, []int32, []int64, []any:
+		list := reflect.ValueOf(tree)
+		switch v.Kind() {
+		case reflect.Slice:
+			v.Set(reflect.MakeSlice(v.Type(), list.Len(), list.Len()))
+		case reflect.Array:
+			if v.Len() != list.Len() {
+				return mismatch
+			}
+		default:
+			return mismatch
+		}
+		for i := 0; i < list.Len(); i++ {
+			err := unmarshal(list.Index(i).Interface(), v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		return unmarshalCompound(tree, v, path, mismatch)
+	default:
+		return mismatch
+	}
+	return nil
+}
+
+func unmarshalCompound(compound map[string]any, v reflect.Value, path string, mismatch error) error {
+	child := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return mismatch
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(compound)))
+		}
+		for name, value := range compound {
+			element := reflect.New(v.Type().Elem()).Elem()
+			err := unmarshal(value, element, child(name))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), element)
+		}
+		return nil
+	case reflect.Struct:
+		structType := v.Type()
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Tag.Get("nbt")
+			if name == "-" {
+				continue
+			}
+			value, ok := compound[name]
+			if name == "" {
+				name = field.Name
+				value, ok = lookupFold(compound, name)
+			}
+			if !ok {
+				continue
+			}
+			err := unmarshal(value, v.Field(i), child(name))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return mismatch
+}
+
+//Looks up name in compound, ignoring case if there is no exact match.
+func lookupFold(compound map[string]any, name string) (any, bool) {
+	if value, ok := compound[name]; ok {
+		return value, true
+	}
+	for key, value := range compound {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package replayReader
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+//Size of the buffer NewReplayFromFile reads the file with.
+const fileBuffer = 64 * 1024
+
+//Opens the replay file at path, like a recording.tmcpr, and returns a Replay reading it through a buffer.
+//The Replay can seek in the file, and Close closes it. .mcpr archives are opened with mcpr.Open instead.
+func NewReplayFromFile(path string, options ...Option) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	buffered := &bufferedFile{Reader: bufio.NewReaderSize(file, fileBuffer), file: file}
+	return NewReplayFromReader(buffered, options...), nil
+}
+
+//Returns a Replay reading the replay file in data. data isn't copied, so it shouldn't be changed while the Replay is used.
+func NewReplayFromBytes(data []byte, options ...Option) *Replay {
+	return NewReplayFromReader(bytes.NewReader(data), options...)
+}
+
+//bufferedFile reads a file through a buffer, and drops the buffer when it seeks.
+type bufferedFile struct {
+	*bufio.Reader
+	file *os.File
+}
+
+func (f *bufferedFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent && offset >= 0 && offset <= int64(f.Buffered()) {
+		//Skipping forward inside the buffer keeps it
+		f.Discard(int(offset))
+		position, err := f.file.Seek(0, io.SeekCurrent)
+		return position - int64(f.Buffered()), err
+	}
+	if whence == io.SeekCurrent {
+		//The file is ahead of the reader by what is buffered
+		offset -= int64(f.Buffered())
+	}
+	position, err := f.file.Seek(offset, whence)
+	f.Reset(f.file)
+	return position, err
+}
+
+func (f *bufferedFile) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,348 @@
+//Package optimize has transforms that make replays smaller without changing what they show.
+package optimize
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//DownsampleOptions configure Downsample.
+//Interval returns the least time, in milliseconds, between two movement packets of an entity.
+//spawn is the packet that spawned the entity, or nil if the replay doesn't have it, like for the recording player.
+//Returning 0 or less keeps every movement packet of the entity.
+type DownsampleOptions struct {
+	Protocol int
+	Interval func(spawn *events.EntitySpawnEvent) int
+}
+
+//Returns an Interval function giving every entity the interval of rate packets per second.
+//A rate of 0 or less keeps every packet.
+func Rate(rate int) func(spawn *events.EntitySpawnEvent) int {
+	return func(spawn *events.EntitySpawnEvent) int {
+		if rate <= 0 {
+			return 0
+		}
+		return 1000 / rate
+	}
+}
+
+//Stats tells how much Downsample saved.
+type Stats struct {
+	PacketsIn  int
+	PacketsOut int
+	BytesIn    int64
+	BytesOut   int64
+}
+
+//entity is the movement of an entity that wasn't written yet.
+//Position changes are kept in the units of the protocol, so merging them is exact.
+type entity struct {
+	interval int
+	lastMove int
+	lastHead int
+
+	dx, dy, dz int
+	yaw, pitch byte
+	onGround   bool
+	moved      bool
+	rotated    bool
+
+	head        byte
+	headPending bool
+}
+
+func (e *entity) pending() bool {
+	return e.moved || e.rotated || e.headPending
+}
+
+type downsampler struct {
+	output   *replayReader.ReplayWriter
+	ids      events.MovementIDs
+	protocol int
+	interval func(spawn *events.EntitySpawnEvent) int
+	entities map[int]*entity
+	waiting  map[int]*entity
+	stats    Stats
+}
+
+//Writes a copy of replay to dst, thinning the movement packets of entities to the intervals of options.
+//Dropped position changes are added to the next movement packet written for the entity, and the last rotation
+//of an entity is always written, so entities end up in the same place and facing the same way as in the original.
+//Teleports and every other packet are copied as they are.
+//Protocols the events package doesn't support are copied without changes.
+func Downsample(dst io.Writer, replay *replayReader.Replay, options DownsampleOptions) (Stats, error) {
+	ids, supported := events.Movement(options.Protocol)
+	d := &downsampler{
+		output:   replayReader.NewReplayWriter(dst),
+		ids:      ids,
+		protocol: options.Protocol,
+		interval: options.Interval,
+		entities: make(map[int]*entity),
+		waiting:  make(map[int]*entity),
+	}
+	spawnIDs := events.IDs[events.EntitySpawnEvent](options.Protocol)
+	destroyIDs := events.IDs[events.EntityDestroyEvent](options.Protocol)
+
+	phase := events.PhaseTracker{Protocol: options.Protocol}
+	var p replayReader.Packet
+	lastTime := 0
+	for replay.Next(&p) {
+		d.stats.PacketsIn++
+		d.stats.BytesIn += int64(p.Len)
+		data := p.Bytes()
+		lastTime = p.Time
+		id, _, err := p.ReadVarInt()
+		if err != nil || !supported || !phase.Play(id) {
+			err = d.write(p.Time, data)
+			if err != nil {
+				return d.stats, err
+			}
+			continue
+		}
+		err = d.flush(p.Time, false)
+		if err != nil {
+			return d.stats, err
+		}
+
+		context := events.Context{Header: events.Header{Time: p.Time, Packet: p.Index, ID: id}, Protocol: options.Protocol}
+		keep := true
+		switch {
+		case contains(spawnIDs, id):
+			spawn, ok, _ := events.Decode[events.EntitySpawnEvent](&p, context)
+			if ok {
+				d.entities[spawn.EntityID] = d.newEntity(&spawn)
+				delete(d.waiting, spawn.EntityID)
+			}
+		case contains(destroyIDs, id):
+			destroy, ok, _ := events.Decode[events.EntityDestroyEvent](&p, context)
+			if ok {
+				for _, entityID := range destroy.EntityIDs {
+					delete(d.entities, entityID)
+					delete(d.waiting, entityID)
+				}
+			}
+		case id == ids.Move || id == ids.Rotate || id == ids.MoveRotate:
+			keep, err = d.move(&p, id)
+		case id == ids.HeadLook:
+			keep, err = d.headLook(&p)
+		case id == ids.Teleport:
+			entityID, _, err := p.ReadVarInt()
+			if e, ok := d.entities[entityID]; ok && err == nil {
+				//A teleport sets where the entity is, so changes that weren't written yet don't matter anymore
+				e.dx, e.dy, e.dz, e.moved, e.rotated = 0, 0, 0, false, false
+				e.lastMove = p.Time
+			}
+		}
+		//Packets that can't be decoded are copied, the transform never drops what it doesn't understand
+		if keep || err != nil {
+			err = d.write(p.Time, data)
+			if err != nil {
+				return d.stats, err
+			}
+		}
+	}
+	if err := replay.Error(); err != nil {
+		return d.stats, err
+	}
+	err := d.flush(lastTime, true)
+	if err != nil {
+		return d.stats, err
+	}
+	return d.stats, d.output.Flush()
+}
+
+func contains(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *downsampler) newEntity(spawn *events.EntitySpawnEvent) *entity {
+	interval := 0
+	if d.interval != nil {
+		interval = d.interval(spawn)
+	}
+	return &entity{interval: interval, lastMove: -interval, lastHead: -interval}
+}
+
+//Returns the entity with entityID, for entities moving without having been spawned in the replay.
+func (d *downsampler) entity(entityID int) *entity {
+	e, ok := d.entities[entityID]
+	if !ok {
+		e = d.newEntity(nil)
+		d.entities[entityID] = e
+	}
+	return e
+}
+
+//Handles a movement packet. It returns true if the packet has to be written as it is.
+func (d *downsampler) move(p *replayReader.Packet, id int) (bool, error) {
+	entityID, _, err := p.ReadVarInt()
+	if err != nil {
+		return true, err
+	}
+	e := d.entity(entityID)
+	if e.interval <= 0 {
+		return true, nil
+	}
+	moved, rotated := id != d.ids.Rotate, id != d.ids.Move
+	var dx, dy, dz int
+	if moved {
+		for _, delta := range []*int{&dx, &dy, &dz} {
+			*delta, err = d.readDelta(p)
+			if err != nil {
+				return true, err
+			}
+		}
+		//The merged change has to fit in one packet, otherwise what is waiting is written first
+		if e.moved && (!d.fits(e.dx+dx) || !d.fits(e.dy+dy) || !d.fits(e.dz+dz)) {
+			err = d.writeMove(entityID, e, p.Time)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	var yaw, pitch byte
+	if rotated {
+		yaw, err = p.ReaduByte()
+		if err == nil {
+			pitch, err = p.ReaduByte()
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+	onGround, err := p.ReadBool()
+	if err != nil {
+		return true, err
+	}
+
+	if moved {
+		e.dx, e.dy, e.dz, e.moved = e.dx+dx, e.dy+dy, e.dz+dz, true
+	}
+	if rotated {
+		e.yaw, e.pitch, e.rotated = yaw, pitch, true
+	}
+	e.onGround = onGround
+	if p.Time-e.lastMove >= e.interval {
+		return false, d.writeMove(entityID, e, p.Time)
+	}
+	d.waiting[entityID] = e
+	return false, nil
+}
+
+//Handles a head look packet. It returns true if the packet has to be written as it is.
+func (d *downsampler) headLook(p *replayReader.Packet) (bool, error) {
+	entityID, _, err := p.ReadVarInt()
+	if err != nil {
+		return true, err
+	}
+	e := d.entity(entityID)
+	if e.interval <= 0 || p.Time-e.lastHead >= e.interval {
+		e.lastHead = p.Time
+		e.headPending = false
+		return true, nil
+	}
+	e.head, err = p.ReaduByte()
+	if err != nil {
+		return true, err
+	}
+	e.headPending = true
+	d.waiting[entityID] = e
+	return false, nil
+}
+
+//Writes the movement of the entities that waited long enough, or of every waiting entity if all is true.
+func (d *downsampler) flush(time int, all bool) error {
+	for entityID, e := range d.waiting {
+		if (e.moved || e.rotated) && (all || time-e.lastMove >= e.interval) {
+			err := d.writeMove(entityID, e, time)
+			if err != nil {
+				return err
+			}
+		}
+		if e.headPending && (all || time-e.lastHead >= e.interval) {
+			var packet []byte
+			packet = appendVarInt(packet, d.ids.HeadLook)
+			packet = appendVarInt(packet, entityID)
+			packet = append(packet, e.head)
+			err := d.write(time, packet)
+			if err != nil {
+				return err
+			}
+			e.lastHead = time
+			e.headPending = false
+		}
+		if !e.pending() {
+			delete(d.waiting, entityID)
+		}
+	}
+	return nil
+}
+
+//Writes one packet with the position change and rotation waiting for the entity.
+func (d *downsampler) writeMove(entityID int, e *entity, time int) error {
+	id := d.ids.MoveRotate
+	if !e.rotated {
+		id = d.ids.Move
+	} else if !e.moved {
+		id = d.ids.Rotate
+	}
+	var packet []byte
+	packet = appendVarInt(packet, id)
+	packet = appendVarInt(packet, entityID)
+	if e.moved {
+		for _, delta := range []int{e.dx, e.dy, e.dz} {
+			if d.protocol < 107 {
+				packet = append(packet, byte(int8(delta)))
+			} else {
+				packet = binary.BigEndian.AppendUint16(packet, uint16(int16(delta)))
+			}
+		}
+	}
+	if e.rotated {
+		packet = append(packet, e.yaw, e.pitch)
+	}
+	if e.onGround {
+		packet = append(packet, 1)
+	} else {
+		packet = append(packet, 0)
+	}
+	e.dx, e.dy, e.dz, e.moved, e.rotated = 0, 0, 0, false, false
+	e.lastMove = time
+	return d.write(time, packet)
+}
+
+//Reads a position change in the units of the protocol: 1/32 blocks in a byte until 1.9, 1/4096 blocks in a short since.
+func (d *downsampler) readDelta(p *replayReader.Packet) (int, error) {
+	if d.protocol < 107 {
+		delta, err := p.ReadByte()
+		return int(delta), err
+	}
+	delta, err := p.ReadShort()
+	return int(delta), err
+}
+
+//Reports whether a position change fits in a movement packet.
+func (d *downsampler) fits(delta int) bool {
+	if d.protocol < 107 {
+		return delta >= -128 && delta <= 127
+	}
+	return delta >= -32768 && delta <= 32767
+}
+
+func (d *downsampler) write(time int, data []byte) error {
+	d.stats.PacketsOut++
+	d.stats.BytesOut += int64(len(data))
+	return d.output.WritePacket(time, data)
+}
+
+func appendVarInt(data []byte, value int) []byte {
+	return binary.AppendUvarint(data, uint64(uint32(value)))
+}
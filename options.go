@@ -0,0 +1,87 @@
+package replayReader
+
+//Option changes how a Replay reads its packets. Options are passed to NewReplay.
+type Option func(r *Replay)
+
+//config holds the settings of a Replay. Every Packet returned by Next points to the config of its Replay.
+type config struct {
+	interner      *Interner
+	exactVarInts  bool
+	strictStrings bool
+	littleEndian  bool
+	limits        Limits
+
+	checksums       []uint32
+	checksumFraming bool
+
+	resync         RecoveryOptions
+	partialPackets bool
+	rawPackets     bool
+	setCompression bool
+	lazyPayloads   bool
+	reuseBuffers   bool
+}
+
+var defaultConfig = config{}
+
+//Returns the config of the Replay p was read from, or the default config if p was created by hand.
+func (p *Packet) getConfig() *config {
+	if p.config == nil {
+		return &defaultConfig
+	}
+	return p.config
+}
+
+//Makes ReadString return interned strings from in.
+//Useful for replays full of repeated identifiers and player names.
+func WithInterner(in *Interner) Option {
+	return func(r *Replay) {
+		r.config.interner = in
+	}
+}
+
+//Makes ReadVarInt follow the protocol's 32-bit semantics, so values with the highest bit set come back negative.
+func WithExactVarInts() Option {
+	return func(r *Replay) {
+		r.config.exactVarInts = true
+	}
+}
+
+//Makes the fixed-size numbers of packets, Shorts, Integers, Longs, Floats and Doubles, little-endian,
+//for Bedrock Edition captures stored in the same time and length framing. The framing itself stays big-endian.
+func WithLittleEndian() Option {
+	return func(r *Replay) {
+		r.config.littleEndian = true
+	}
+}
+
+//Makes ReadString check that strings are valid UTF-8 and no longer than MaxStringLength UTF-16 code units.
+func WithStrictStrings() Option {
+	return func(r *Replay) {
+		r.config.strictStrings = true
+	}
+}
+
+//Makes the Replay enforce limits on every variable-length read. See Limits.
+func WithLimits(limits Limits) Option {
+	return func(r *Replay) {
+		r.config.limits = limits
+	}
+}
+
+//Enables every check the protocol allows: StrictLimits and WithStrictStrings.
+//Use this when reading replay files from untrusted sources.
+func WithStrict() Option {
+	return func(r *Replay) {
+		r.config.limits = StrictLimits
+		r.config.strictStrings = true
+	}
+}
+
+//Makes Next return the last packet even if the file ends in the middle of it, with Packet.Truncated set.
+//Without it, Next reports ErrPacketTruncated instead.
+func WithPartialPackets() Option {
+	return func(r *Replay) {
+		r.config.partialPackets = true
+	}
+}
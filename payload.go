@@ -0,0 +1,94 @@
+package replayReader
+
+import (
+	"bytes"
+	"io"
+)
+
+//Returns the whole data of the packet, no matter how much of it was read, without copying it.
+//The slice must not be changed. If the Replay reuses its buffers, it is only valid until the next call of Next.
+//For packets of a lazy Replay, the data is read from the file first; if that fails, the bytes that were there are returned
+//and the error is returned by reads from Data.
+func (p *Packet) Bytes() []byte {
+	switch data := p.Data.(type) {
+	case *lazyPayload:
+		data.load()
+		return data.data
+	case nil:
+		return nil
+	}
+	if p.data != nil {
+		return p.data
+	}
+	//Data was set by hand, so it has to be read
+	position, err := p.Data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	defer p.Data.Seek(position, io.SeekStart)
+	_, err = p.Data.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil
+	}
+	var buffer bytes.Buffer
+	buffer.ReadFrom(p.Data)
+	return buffer.Bytes()
+}
+
+//Returns the next n bytes of the data of the packet, from the current position, without copying them or moving the position.
+//It returns false if they aren't all in the data of the packet, or if Data was set by hand, which Bytes would have to copy.
+func (p *Packet) sliceData(n int) ([]byte, bool) {
+	if _, lazy := p.Data.(*lazyPayload); !lazy && p.data == nil {
+		return nil, false
+	}
+	data, position := p.Bytes(), p.Position()
+	if position < 0 || n < 0 || position+n > len(data) {
+		return nil, false
+	}
+	return data[position : position+n : position+n], true
+}
+
+//Reads from the data of the packet at off, without changing the position of Data. It implements io.ReaderAt.
+func (p *Packet) ReadAt(b []byte, off int64) (int, error) {
+	data := p.Bytes()
+	if off < 0 {
+		return 0, ErrInvalidLength
+	}
+	if off >= int64(len(data)) {
+		return 0, io.EOF
+	}
+	n := copy(b, data[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+//Returns the position in the data of the packet, the number of bytes that were read or skipped, or -1 if it can't be told.
+func (p *Packet) Position() int {
+	position, err := p.Data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return int(position)
+}
+
+//Returns the number of bytes of the packet that weren't read yet.
+func (p *Packet) Remaining() int {
+	remaining := p.remaining()
+	if remaining < 0 {
+		return max(p.Len-p.Position(), 0)
+	}
+	return int(remaining)
+}
+
+//Skips the next n bytes of the packet, like a field that isn't needed.
+//A negative n, or one longer than the rest of the packet, is reported with a *LengthError and nothing is skipped.
+func (p *Packet) Skip(n int) error {
+	remaining := p.Remaining()
+	if n < 0 || n > remaining {
+		return &LengthError{Packet: p.Index, Offset: p.errorOffset(0), Field: "skip", Len: int64(n), Remaining: int64(remaining)}
+	}
+	_, err := p.Data.Seek(int64(n), io.SeekCurrent)
+	return err
+}
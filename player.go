@@ -0,0 +1,180 @@
+package replayReader
+
+import (
+	"sync"
+	"time"
+)
+
+//Player plays a Replay in real time: Next returns each packet when the playback clock reaches its Time.
+//The playback clock follows a Clock, the wall clock by default.
+//The speed of the playback can be changed, and it can be paused and seeked, from any goroutine.
+//Next itself should only be called from one goroutine at a time.
+type Player struct {
+	replay *Replay
+	clock  Clock
+
+	mutex   sync.Mutex
+	changed chan struct{}
+	speed   float64
+	paused  bool
+	stopped bool
+	//The playback time was base when the Clock showed wallBase.
+	base     int
+	wallBase time.Time
+	//Packets before this time are returned without waiting, because of a Seek.
+	fastForward int
+	pending     Packet
+	hasPending  bool
+	error       error
+}
+
+//Returns a Player that starts playing replay at normal speed from its beginning.
+func NewPlayer(replay *Replay) *Player {
+	return NewPlayerWithClock(replay, RealClock)
+}
+
+//Returns a Player like NewPlayer, whose playback follows clock instead of the wall clock.
+func NewPlayerWithClock(replay *Replay, clock Clock) *Player {
+	return &Player{
+		replay:   replay,
+		clock:    clock,
+		changed:  make(chan struct{}),
+		speed:    1,
+		wallBase: clock.Now(),
+	}
+}
+
+//Returns the current time of the playback clock, in milliseconds since the beginning of the Replay.
+func (pl *Player) Position() int {
+	pl.mutex.Lock()
+	defer pl.mutex.Unlock()
+	return pl.position(pl.clock.Now())
+}
+
+//Returns the time of the playback clock when the Clock shows now. pl.mutex has to be held.
+func (pl *Player) position(now time.Time) int {
+	if pl.paused {
+		return pl.base
+	}
+	return pl.base + int(float64(now.Sub(pl.wallBase).Milliseconds())*pl.speed)
+}
+
+//Moves the base of the playback clock to now, then applies change. Wakes up Next, so it sees the change.
+func (pl *Player) update(change func()) {
+	pl.mutex.Lock()
+	defer pl.mutex.Unlock()
+	now := pl.clock.Now()
+	pl.base = pl.position(now)
+	pl.wallBase = now
+	change()
+	close(pl.changed)
+	pl.changed = make(chan struct{})
+}
+
+//Sets the speed of the playback. 1 is real time, 2 is twice as fast. speed has to be more than 0.
+func (pl *Player) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+	pl.update(func() {
+		pl.speed = speed
+	})
+}
+
+//Stops the playback clock until Resume is called.
+func (pl *Player) Pause() {
+	pl.update(func() {
+		pl.paused = true
+	})
+}
+
+//Starts the playback clock again after Pause.
+func (pl *Player) Resume() {
+	pl.update(func() {
+		pl.paused = false
+	})
+}
+
+//Reports whether the playback is paused.
+func (pl *Player) Paused() bool {
+	pl.mutex.Lock()
+	defer pl.mutex.Unlock()
+	return pl.paused
+}
+
+//Makes Next return false, even if it is waiting for a packet.
+func (pl *Player) Stop() {
+	pl.update(func() {
+		pl.stopped = true
+	})
+}
+
+//Moves the playback clock to time.
+//The packets before time, that weren't returned yet, are returned by Next without waiting, so consumers can keep their state.
+//Seeking backwards starts reading the Replay again from the beginning, which only works if its file is an io.Seeker.
+func (pl *Player) Seek(time int) error {
+	var err error
+	pl.update(func() {
+		if time < pl.base {
+			err = pl.replay.rewind()
+			if err != nil {
+				return
+			}
+			pl.hasPending = false
+		}
+		pl.base = time
+		pl.fastForward = time
+	})
+	return err
+}
+
+//Sets p to the next packet of the Replay once the playback clock reaches its Time.
+//It returns false at the end of the Replay, after Stop, or if reading failed, in which case pl.Error() returns the error.
+func (pl *Player) Next(p *Packet) bool {
+	pl.mutex.Lock()
+	for {
+		if pl.stopped {
+			pl.mutex.Unlock()
+			return false
+		}
+		if !pl.hasPending {
+			if !pl.replay.Next(&pl.pending) {
+				pl.error = pl.replay.Error()
+				pl.mutex.Unlock()
+				return false
+			}
+			pl.hasPending = true
+		}
+		position := pl.position(pl.clock.Now())
+		if pl.pending.Time < pl.fastForward || pl.pending.Time <= position {
+			*p = pl.pending
+			pl.hasPending = false
+			pl.mutex.Unlock()
+			return true
+		}
+		changed := pl.changed
+		var timer Timer
+		var timeout <-chan time.Time
+		if !pl.paused {
+			wait := time.Duration(float64(pl.pending.Time-position)/pl.speed) * time.Millisecond
+			timer = pl.clock.NewTimer(wait)
+			timeout = timer.C()
+		}
+		pl.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		pl.mutex.Lock()
+	}
+}
+
+//Returns the error that stopped the latest Next()
+func (pl *Player) Error() error {
+	pl.mutex.Lock()
+	defer pl.mutex.Unlock()
+	return pl.error
+}
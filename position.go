@@ -0,0 +1,62 @@
+package replayReader
+
+//First protocol version, 1.14, that packs block positions as x, z, y instead of x, y, z.
+const ProtocolPositionXZY = 477
+
+//Position is the position of a block.
+type Position struct {
+	X, Y, Z int
+}
+
+//Reads a block position packed into a Long, in the layout used by protocol:
+//26 bits of x, 12 of y and 26 of z before 1.14, and 26 bits of x, 26 of z and 12 of y since. Len: 8 bytes
+func (p *Packet) ReadPosition(protocol int) (Position, error) {
+	value, err := p.ReadLong()
+	if err != nil {
+		return Position{}, err
+	}
+	return unpackPosition(value, protocol), nil
+}
+
+//Reads an angle, a byte counting 1/256 of a turn, and returns it in degrees, from 0 up to 360. Len: 1 byte
+func (p *Packet) ReadAngle() (float64, error) {
+	angle, err := p.ReaduByte()
+	return float64(angle) * 360 / 256, err
+}
+
+//Number of fixed-point units in a block, for the coordinates of entities before 1.9.
+const FixedPointScale = 32
+
+//Reads an entity coordinate sent as a fixed-point Int in 1/32 blocks, like versions before 1.9 do, in blocks. Len: 4 bytes
+func (p *Packet) ReadFixedPoint() (float64, error) {
+	value, err := p.ReadInt()
+	return float64(value) / FixedPointScale, err
+}
+
+//Reads a position change sent as a fixed-point Byte in 1/32 blocks, like relative moves before 1.9, in blocks. Len: 1 byte
+func (p *Packet) ReadFixedPointByte() (float64, error) {
+	value, err := p.ReadByte()
+	return float64(value) / FixedPointScale, err
+}
+
+func unpackPosition(value int64, protocol int) Position {
+	position := Position{X: int(value >> 38)}
+	if protocol < ProtocolPositionXZY {
+		position.Y = int(value << 26 >> 52)
+		position.Z = int(value << 38 >> 38)
+	} else {
+		position.Y = int(value << 52 >> 52)
+		position.Z = int(value << 26 >> 38)
+	}
+	return position
+}
+
+func packPosition(position Position, protocol int) int64 {
+	x := int64(position.X) & (1<<26 - 1)
+	y := int64(position.Y) & (1<<12 - 1)
+	z := int64(position.Z) & (1<<26 - 1)
+	if protocol < ProtocolPositionXZY {
+		return x<<38 | y<<26 | z
+	}
+	return x<<38 | z<<12 | y
+}
@@ -0,0 +1,193 @@
+package protocol
+
+import (
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/nbt"
+)
+
+//First protocol version, 1.20.5, that sends registries entry by entry, and the packets of its configuration state.
+const ProtocolRegistryEntries = 766
+
+//ConfigurationIDs are the IDs of the clientbound configuration packets of a protocol version.
+//Packets the version doesn't have are -1. Before 1.20.3, AddResourcePack is the Resource Pack packet.
+type ConfigurationIDs struct {
+	PluginMessage      int
+	Disconnect         int
+	Finish             int
+	KeepAlive          int
+	Ping               int
+	RegistryData       int
+	RemoveResourcePack int
+	AddResourcePack    int
+	FeatureFlags       int
+	UpdateTags         int
+}
+
+//Returns the IDs of the configuration packets of protocol. It returns false before 1.20.2, which has no configuration state.
+func Configuration(protocol int) (ConfigurationIDs, bool) {
+	if protocol < ProtocolConfiguration {
+		return ConfigurationIDs{}, false
+	}
+	names := configurationNames(protocol)
+	id := func(name string) int {
+		for i, other := range names {
+			if other == name {
+				return i
+			}
+		}
+		return -1
+	}
+	ids := ConfigurationIDs{
+		PluginMessage:      id("Plugin Message"),
+		Disconnect:         id("Disconnect"),
+		Finish:             id("Finish Configuration"),
+		KeepAlive:          id("Keep Alive"),
+		Ping:               id("Ping"),
+		RegistryData:       id("Registry Data"),
+		RemoveResourcePack: id("Remove Resource Pack"),
+		AddResourcePack:    id("Add Resource Pack"),
+		FeatureFlags:       id("Feature Flags"),
+		UpdateTags:         id("Update Tags"),
+	}
+	if ids.AddResourcePack < 0 {
+		ids.AddResourcePack = id("Resource Pack")
+	}
+	return ids, true
+}
+
+//RegistryData is a Registry Data packet. Before 1.20.5 it holds every registry in Codec,
+//later ones send one registry per packet, with its name in Registry and its entries in Entries.
+type RegistryData struct {
+	Registry string
+	Entries  []RegistryEntry
+	Codec    map[string]any
+}
+
+//RegistryEntry is an entry of a registry. Data is nil if the entry comes from a data pack the client already knows.
+type RegistryEntry struct {
+	ID   string
+	Data map[string]any
+}
+
+//ResourcePack asks the client to use a resource pack. ID is only sent since 1.20.3, which can use several packs at once.
+//Prompt is the message shown to the player, or nil if there is none.
+type ResourcePack struct {
+	ID     replayReader.UUID
+	URL    string
+	Hash   string
+	Forced bool
+	Prompt *replayReader.ChatComponent
+}
+
+//RemoveResourcePack removes the pack with ID, or every pack if ID is nil.
+type RemoveResourcePack struct {
+	ID *replayReader.UUID
+}
+
+//FinishConfiguration ends the configuration state, the packets after it are in the play state.
+type FinishConfiguration struct{}
+
+//FeatureFlags are the experimental features the server turned on, like minecraft:vanilla and minecraft:bundle.
+type FeatureFlags struct {
+	Flags []string
+}
+
+//ConfigurationDisconnect is a Disconnect packet sent during configuration.
+type ConfigurationDisconnect struct {
+	Reason replayReader.ChatComponent
+}
+
+//PluginMessage is a Plugin Message packet: data sent on a channel, like minecraft:brand.
+type PluginMessage struct {
+	Channel string
+	Data    []byte
+}
+
+//Decodes a packet of the configuration state, which p has to be in, positioned after its ID like Reader.Next leaves it.
+//It returns a *RegistryData, *ResourcePack, *RemoveResourcePack, *FinishConfiguration, *FeatureFlags,
+//*ConfigurationDisconnect or *PluginMessage, or nil for other packets, like Keep Alive and Update Tags.
+func DecodeConfiguration(p *Packet, protocol int) (any, error) {
+	ids, ok := Configuration(protocol)
+	if !ok || p.State != StateConfiguration || p.ID < 0 {
+		return nil, nil
+	}
+	packet := &p.Packet
+	switch p.ID {
+	case ids.RegistryData:
+		return readRegistryData(packet, protocol)
+	case ids.AddResourcePack:
+		return readResourcePack(packet, protocol)
+	case ids.RemoveResourcePack:
+		id, err := replayReader.ReadOptional(packet, (*replayReader.Packet).ReadUUID)
+		return &RemoveResourcePack{ID: id}, err
+	case ids.Finish:
+		return &FinishConfiguration{}, nil
+	case ids.FeatureFlags:
+		flags, err := replayReader.ReadVarIntPrefixedArray(packet, replayReader.IgnoreLen((*replayReader.Packet).ReadIdentifier))
+		return &FeatureFlags{Flags: flags}, err
+	case ids.Disconnect:
+		reason, err := nbt.ReadChat(packet, protocol)
+		return &ConfigurationDisconnect{Reason: reason}, err
+	case ids.PluginMessage:
+		channel, _, err := packet.ReadIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := packet.ReadRest()
+		return &PluginMessage{Channel: channel, Data: data}, err
+	}
+	return nil, nil
+}
+
+func readRegistryData(p *replayReader.Packet, protocol int) (*RegistryData, error) {
+	if protocol < ProtocolRegistryEntries {
+		codec, err := nbt.ReadCompound(p, protocol)
+		return &RegistryData{Codec: codec}, err
+	}
+	registry, _, err := p.ReadIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := replayReader.ReadVarIntPrefixedArray(p, func(p *replayReader.Packet) (RegistryEntry, error) {
+		var entry RegistryEntry
+		id, _, err := p.ReadIdentifier()
+		if err != nil {
+			return entry, err
+		}
+		entry.ID = id
+		present, err := p.ReadBool()
+		if err != nil || !present {
+			return entry, err
+		}
+		entry.Data, err = nbt.ReadCompound(p, protocol)
+		return entry, err
+	})
+	return &RegistryData{Registry: registry, Entries: entries}, err
+}
+
+func readResourcePack(p *replayReader.Packet, protocol int) (*ResourcePack, error) {
+	var pack ResourcePack
+	var err error
+	if protocol >= nbt.ProtocolChatNBT {
+		pack.ID, err = p.ReadUUID()
+		if err != nil {
+			return nil, err
+		}
+	}
+	pack.URL, _, err = p.ReadString()
+	if err == nil {
+		pack.Hash, _, err = p.ReadString()
+	}
+	if err == nil {
+		pack.Forced, err = p.ReadBool()
+	}
+	if err == nil {
+		pack.Prompt, err = replayReader.ReadOptional(p, func(p *replayReader.Packet) (replayReader.ChatComponent, error) {
+			return nbt.ReadChat(p, protocol)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
@@ -0,0 +1,129 @@
+package protocol
+
+//Returns the name of the clientbound packet with id in state on protocol, like "Entity Teleport",
+//or "" if it isn't known. Play packets are known for 1.8.9, 1.12.2, 1.15.2, 1.16.5 and 1.20.1,
+//the names are the ones the wiki used for each version.
+func PacketName(protocol int, state State, id int) string {
+	var names []string
+	switch state {
+	case StateStatus:
+		names = statusNames
+	case StateLogin:
+		names = loginNames
+		if protocol < 393 {
+			names = names[:4]
+		}
+	case StateConfiguration:
+		names = configurationNames(protocol)
+	case StatePlay:
+		names = playNames[protocol]
+	}
+	if id < 0 || id >= len(names) {
+		return ""
+	}
+	return names[id]
+}
+
+//Returns the names of the configuration packets of protocol, or nil if it has no configuration state.
+func configurationNames(protocol int) []string {
+	switch {
+	case protocol < ProtocolConfiguration:
+		return nil
+	case protocol == ProtocolConfiguration:
+		return configurationNames764
+	case protocol < ProtocolRegistryEntries:
+		return configurationNames765
+	}
+	return configurationNames766
+}
+
+//Returns the protocol versions PacketName knows the play packets of, oldest first.
+func KnownVersions() []int {
+	return []int{47, 340, 578, 754, 763}
+}
+
+//Names of the packets, by ID.
+var (
+	statusNames = []string{"Status Response", "Ping Response"}
+	//Login Plugin Request was added in 1.13.
+	loginNames            = []string{"Disconnect", "Encryption Request", "Login Success", "Set Compression", "Login Plugin Request"}
+	configurationNames764 = []string{"Plugin Message", "Disconnect", "Finish Configuration", "Keep Alive", "Ping",
+		"Registry Data", "Resource Pack", "Feature Flags", "Update Tags"}
+	configurationNames765 = []string{"Plugin Message", "Disconnect", "Finish Configuration", "Keep Alive", "Ping",
+		"Registry Data", "Remove Resource Pack", "Add Resource Pack", "Feature Flags", "Update Tags"}
+	//1.20.5 added cookies, transfers and known packs
+	configurationNames766 = []string{"Cookie Request", "Plugin Message", "Disconnect", "Finish Configuration", "Keep Alive", "Ping",
+		"Reset Chat", "Registry Data", "Remove Resource Pack", "Add Resource Pack", "Store Cookie", "Transfer", "Feature Flags",
+		"Update Tags", "Known Packs"}
+)
+
+var playNames = map[int][]string{
+	47: {
+		"Keep Alive", "Join Game", "Chat Message", "Time Update", "Entity Equipment", "Spawn Position", "Update Health", "Respawn",
+		"Player Position And Look", "Held Item Change", "Use Bed", "Animation", "Spawn Player", "Collect Item", "Spawn Object", "Spawn Mob",
+		"Spawn Painting", "Spawn Experience Orb", "Entity Velocity", "Destroy Entities", "Entity", "Entity Relative Move", "Entity Look", "Entity Look And Relative Move",
+		"Entity Teleport", "Entity Head Look", "Entity Status", "Attach Entity", "Entity Metadata", "Entity Effect", "Remove Entity Effect", "Set Experience",
+		"Entity Properties", "Chunk Data", "Multi Block Change", "Block Change", "Block Action", "Block Break Animation", "Map Chunk Bulk", "Explosion",
+		"Effect", "Sound Effect", "Particle", "Change Game State", "Spawn Global Entity", "Open Window", "Close Window", "Set Slot",
+		"Window Items", "Window Property", "Confirm Transaction", "Update Sign", "Map", "Update Block Entity", "Sign Editor Open", "Statistics",
+		"Player List Item", "Player Abilities", "Tab-Complete", "Scoreboard Objective", "Update Score", "Display Scoreboard", "Teams", "Plugin Message",
+		"Disconnect", "Server Difficulty", "Combat Event", "Camera", "World Border", "Title", "Set Compression", "Player List Header And Footer",
+		"Resource Pack Send", "Update Entity NBT",
+	},
+	340: {
+		"Spawn Object", "Spawn Experience Orb", "Spawn Global Entity", "Spawn Mob", "Spawn Painting", "Spawn Player", "Animation", "Statistics",
+		"Block Break Animation", "Update Block Entity", "Block Action", "Block Change", "Boss Bar", "Server Difficulty", "Tab-Complete", "Chat Message",
+		"Multi Block Change", "Confirm Transaction", "Close Window", "Open Window", "Window Items", "Window Property", "Set Slot", "Set Cooldown",
+		"Plugin Message", "Named Sound Effect", "Disconnect", "Entity Status", "Explosion", "Unload Chunk", "Change Game State", "Keep Alive",
+		"Chunk Data", "Effect", "Particle", "Join Game", "Map", "Entity", "Entity Relative Move", "Entity Look And Relative Move",
+		"Entity Look", "Vehicle Move", "Open Sign Editor", "Craft Recipe Response", "Player Abilities", "Combat Event", "Player List Item", "Player Position And Look",
+		"Use Bed", "Unlock Recipes", "Destroy Entities", "Remove Entity Effect", "Resource Pack Send", "Respawn", "Entity Head Look", "Select Advancement Tab",
+		"World Border", "Camera", "Held Item Change", "Display Scoreboard", "Entity Metadata", "Attach Entity", "Entity Velocity", "Entity Equipment",
+		"Set Experience", "Update Health", "Scoreboard Objective", "Set Passengers", "Teams", "Update Score", "Spawn Position", "Time Update",
+		"Title", "Sound Effect", "Player List Header And Footer", "Collect Item", "Entity Teleport", "Advancements", "Entity Properties", "Entity Effect",
+	},
+	578: {
+		"Spawn Entity", "Spawn Experience Orb", "Spawn Weather Entity", "Spawn Living Entity", "Spawn Painting", "Spawn Player", "Entity Animation", "Statistics",
+		"Acknowledge Player Digging", "Block Break Animation", "Block Entity Data", "Block Action", "Block Change", "Boss Bar", "Server Difficulty", "Chat Message",
+		"Multi Block Change", "Tab-Complete", "Declare Commands", "Window Confirmation", "Close Window", "Window Items", "Window Property", "Set Slot",
+		"Set Cooldown", "Plugin Message", "Named Sound Effect", "Disconnect", "Entity Status", "Explosion", "Unload Chunk", "Change Game State",
+		"Open Horse Window", "Keep Alive", "Chunk Data", "Effect", "Particle", "Update Light", "Join Game", "Map Data",
+		"Trade List", "Entity Position", "Entity Position and Rotation", "Entity Rotation", "Entity Movement", "Vehicle Move", "Open Book", "Open Window",
+		"Open Sign Editor", "Craft Recipe Response", "Player Abilities", "Combat Event", "Player Info", "Face Player", "Player Position And Look", "Unlock Recipes",
+		"Destroy Entities", "Remove Entity Effect", "Resource Pack Send", "Respawn", "Entity Head Look", "Select Advancement Tab", "World Border", "Camera",
+		"Held Item Change", "Update View Position", "Update View Distance", "Display Scoreboard", "Entity Metadata", "Attach Entity", "Entity Velocity", "Entity Equipment",
+		"Set Experience", "Update Health", "Scoreboard Objective", "Set Passengers", "Teams", "Update Score", "Spawn Position", "Time Update",
+		"Title", "Entity Sound Effect", "Sound Effect", "Stop Sound", "Player List Header And Footer", "NBT Query Response", "Collect Item", "Entity Teleport",
+		"Advancements", "Entity Properties", "Entity Effect", "Declare Recipes", "Tags",
+	},
+	754: {
+		"Spawn Entity", "Spawn Experience Orb", "Spawn Living Entity", "Spawn Painting", "Spawn Player", "Entity Animation", "Statistics", "Acknowledge Player Digging",
+		"Block Break Animation", "Block Entity Data", "Block Action", "Block Change", "Boss Bar", "Server Difficulty", "Chat Message", "Tab-Complete",
+		"Declare Commands", "Window Confirmation", "Close Window", "Window Items", "Window Property", "Set Slot", "Set Cooldown", "Plugin Message",
+		"Named Sound Effect", "Disconnect", "Entity Status", "Explosion", "Unload Chunk", "Change Game State", "Open Horse Window", "Keep Alive",
+		"Chunk Data", "Effect", "Particle", "Update Light", "Join Game", "Map Data", "Trade List", "Entity Position",
+		"Entity Position and Rotation", "Entity Rotation", "Entity Movement", "Vehicle Move", "Open Book", "Open Window", "Open Sign Editor", "Craft Recipe Response",
+		"Player Abilities", "Combat Event", "Player Info", "Face Player", "Player Position And Look", "Unlock Recipes", "Destroy Entities", "Remove Entity Effect",
+		"Resource Pack Send", "Respawn", "Entity Head Look", "Multi Block Change", "Select Advancement Tab", "World Border", "Camera", "Held Item Change",
+		"Update View Position", "Update View Distance", "Spawn Position", "Display Scoreboard", "Entity Metadata", "Attach Entity", "Entity Velocity", "Entity Equipment",
+		"Set Experience", "Update Health", "Scoreboard Objective", "Set Passengers", "Teams", "Update Score", "Time Update", "Title",
+		"Entity Sound Effect", "Sound Effect", "Stop Sound", "Player List Header And Footer", "NBT Query Response", "Collect Item", "Entity Teleport", "Advancements",
+		"Entity Properties", "Entity Effect", "Declare Recipes", "Tags",
+	},
+	763: {
+		"Bundle Delimiter", "Spawn Entity", "Spawn Experience Orb", "Spawn Player", "Entity Animation", "Award Statistics", "Acknowledge Block Change", "Set Block Destroy Stage",
+		"Block Entity Data", "Block Action", "Block Update", "Boss Bar", "Change Difficulty", "Chunk Biomes", "Clear Titles", "Command Suggestions Response",
+		"Commands", "Close Container", "Set Container Content", "Set Container Property", "Set Container Slot", "Set Cooldown", "Chat Suggestions", "Plugin Message",
+		"Damage Event", "Delete Message", "Disconnect", "Disguised Chat Message", "Entity Event", "Explosion", "Unload Chunk", "Game Event",
+		"Open Horse Screen", "Hurt Animation", "Initialize World Border", "Keep Alive", "Chunk Data and Update Light", "World Event", "Particle", "Update Light",
+		"Login", "Map Data", "Merchant Offers", "Update Entity Position", "Update Entity Position and Rotation", "Update Entity Rotation", "Move Vehicle", "Open Book",
+		"Open Screen", "Open Sign Editor", "Ping", "Place Ghost Recipe", "Player Abilities", "Player Chat Message", "End Combat", "Enter Combat",
+		"Combat Death", "Player Info Remove", "Player Info Update", "Look At", "Synchronize Player Position", "Update Recipe Book", "Remove Entities", "Remove Entity Effect",
+		"Resource Pack", "Respawn", "Set Head Rotation", "Update Section Blocks", "Select Advancements Tab", "Server Data", "Set Action Bar Text", "Set Border Center",
+		"Set Border Lerp Size", "Set Border Size", "Set Border Warning Delay", "Set Border Warning Distance", "Set Camera", "Set Held Item", "Set Center Chunk", "Set Render Distance",
+		"Set Default Spawn Position", "Display Objective", "Set Entity Metadata", "Link Entities", "Set Entity Velocity", "Set Equipment", "Set Experience", "Set Health",
+		"Update Objectives", "Set Passengers", "Update Teams", "Update Score", "Set Simulation Distance", "Set Subtitle Text", "Update Time", "Set Title Text",
+		"Set Title Animation Times", "Entity Sound Effect", "Sound Effect", "Stop Sound", "System Chat Message", "Set Tab List Header And Footer", "Tag Query Response", "Pick Up Item",
+		"Teleport Entity", "Update Advancements", "Update Attributes", "Feature Flags", "Entity Effect", "Update Recipes", "Update Tags",
+	},
+}
@@ -0,0 +1,53 @@
+package protocol
+
+import "testing"
+
+func TestPacketName(t *testing.T) {
+	tests := []struct {
+		protocol int
+		state    State
+		id       int
+		want     string
+	}{
+		{47, StatePlay, 0x00, "Keep Alive"},
+		{47, StatePlay, 0x1D, "Entity Effect"},
+		{47, StatePlay, 0x49, "Update Entity NBT"},
+		{47, StatePlay, 0x4A, ""},
+		{340, StatePlay, 0x4C, "Entity Teleport"},
+		{340, StatePlay, 0x4F, "Entity Effect"},
+		{340, StatePlay, 0x50, ""},
+		{578, StatePlay, 0x5A, "Entity Effect"},
+		{578, StatePlay, 0x5C, "Tags"},
+		{578, StatePlay, 0x5D, ""},
+		{754, StatePlay, 0x59, "Entity Effect"},
+		{754, StatePlay, 0x5B, "Tags"},
+		{754, StatePlay, 0x5C, ""},
+		{763, StatePlay, 0x68, "Teleport Entity"},
+		{763, StatePlay, 0x6B, "Feature Flags"},
+		{763, StatePlay, 0x6C, "Entity Effect"},
+		{763, StatePlay, 0x6D, "Update Recipes"},
+		{763, StatePlay, 0x6E, "Update Tags"},
+		{763, StatePlay, 0x6F, ""},
+		{763, StateLogin, 0x02, "Login Success"},
+		{340, StateLogin, 0x04, ""},
+		{764, StateConfiguration, 0x02, "Finish Configuration"},
+		{766, StateConfiguration, 0x03, "Finish Configuration"},
+		{47, StatePlay, -1, ""},
+	}
+	for _, test := range tests {
+		got := PacketName(test.protocol, test.state, test.id)
+		if got != test.want {
+			t.Errorf("PacketName(%d, %v, 0x%02X) = %q, want %q", test.protocol, test.state, test.id, got, test.want)
+		}
+	}
+}
+
+//Every version has a name for each ID up to the last one of its table.
+func TestPlayNamesLength(t *testing.T) {
+	want := map[int]int{47: 0x4A, 340: 0x50, 578: 0x5D, 754: 0x5C, 763: 0x6F}
+	for _, version := range KnownVersions() {
+		if got := len(playNames[version]); got != want[version] {
+			t.Errorf("protocol %d has %d play packet names, want %d", version, got, want[version])
+		}
+	}
+}
@@ -0,0 +1,34 @@
+//Package protocol knows about the Minecraft protocol as a whole: the states of a connection and how to follow them,
+//the names of the packets of the major versions, and which version a recording was made with.
+package protocol
+
+//State is the state of a connection, which tells what the packet IDs mean.
+type State int
+
+const (
+	StateHandshaking State = iota
+	StateStatus
+	StateLogin
+	//Configuration comes between login and play since 1.20.2.
+	StateConfiguration
+	StatePlay
+)
+
+//First protocol version, 1.20.2, with the configuration state.
+const ProtocolConfiguration = 764
+
+func (s State) String() string {
+	switch s {
+	case StateHandshaking:
+		return "handshaking"
+	case StateStatus:
+		return "status"
+	case StateLogin:
+		return "login"
+	case StateConfiguration:
+		return "configuration"
+	case StatePlay:
+		return "play"
+	}
+	return "unknown"
+}
@@ -0,0 +1,143 @@
+package protocol
+
+import "github.com/bela333/replayReader"
+
+//StateTracker follows the state of a recorded connection from packet to packet.
+//Recordings start in the login state, Login Success moves them to configuration, or to play before 1.20.2,
+//and Finish Configuration then moves them to play. Since 1.20.2 the server can send Start Configuration
+//to go back to configuration during play.
+type StateTracker struct {
+	protocol int
+	state    State
+}
+
+//Returns a StateTracker at the start of a recording made with protocol.
+func NewStateTracker(protocol int) *StateTracker {
+	return &StateTracker{protocol: protocol, state: StateLogin}
+}
+
+//Returns the state the next packet is in.
+func (t *StateTracker) State() State {
+	return t.state
+}
+
+//Returns the state the clientbound packet with id is in, and moves to the next state if the packet ends its state.
+//Recordings without a login state are playing from their first packet, which shows as an ID that doesn't exist in login.
+func (t *StateTracker) Packet(id int) State {
+	state := t.state
+	switch state {
+	case StateLogin:
+		switch {
+		case id == 0x02 && t.protocol >= ProtocolConfiguration:
+			t.state = StateConfiguration
+		case id == 0x02:
+			t.state = StatePlay
+		case id > 0x04:
+			state, t.state = StatePlay, StatePlay
+		}
+	case StateConfiguration:
+		if id == finishConfigurationID(t.protocol) {
+			t.state = StatePlay
+		}
+	case StatePlay:
+		if start, ok := startConfigurationID(t.protocol); ok && id == start {
+			t.state = StateConfiguration
+		}
+	}
+	return state
+}
+
+//Returns the ID of Finish Configuration. 1.20.5 added Cookie Request before it.
+func finishConfigurationID(protocol int) int {
+	if protocol >= 766 {
+		return 0x03
+	}
+	return 0x02
+}
+
+//Returns the ID of the Start Configuration play packet, if protocol has it.
+func startConfigurationID(protocol int) (int, bool) {
+	switch {
+	case protocol < ProtocolConfiguration:
+		return 0, false
+	case protocol == ProtocolConfiguration:
+		return 0x65, true
+	case protocol <= 765:
+		return 0x67, true
+	}
+	return 0x69, true
+}
+
+//Packet is a packet of a recording with the state it was sent in and its ID.
+//Its Data is after the ID. ID is -1 if the packet doesn't start with a valid VarInt.
+type Packet struct {
+	replayReader.Packet
+	State State
+	ID    int
+}
+
+//Reader reads a Replay, telling the state of the connection each packet was sent in.
+type Reader struct {
+	Replay  *replayReader.Replay
+	tracker *StateTracker
+}
+
+//Returns a Reader reading replay from its current position, which should be its start.
+//protocol is the version the replay was recorded with, see replayReader.Replay.DetectProtocol.
+func NewReader(replay *replayReader.Replay, protocol int) *Reader {
+	return &Reader{Replay: replay, tracker: NewStateTracker(protocol)}
+}
+
+//Sets p to the next packet of the Replay, see replayReader.Replay.Next.
+func (r *Reader) Next(p *Packet) bool {
+	if !r.Replay.Next(&p.Packet) {
+		return false
+	}
+	id, _, err := p.ReadVarInt()
+	if err != nil {
+		p.State, p.ID = r.tracker.State(), -1
+		return true
+	}
+	p.State, p.ID = r.tracker.Packet(id), id
+	return true
+}
+
+//Returns the state the next packet is in.
+func (r *Reader) State() State {
+	return r.tracker.State()
+}
+
+//Returns the error of the Replay.
+func (r *Reader) Error() error {
+	return r.Replay.Error()
+}
+
+//First protocol version, 1.19.4, with bundles: the play packets between two Bundle Delimiters are applied together.
+const ProtocolBundles = 762
+
+//ID of the Bundle Delimiter play packet.
+const bundleDelimiterID = 0x00
+
+//Reads the next packet of the Replay, or the next bundle of packets, and appends them to bundle[:0].
+//A bundle is returned without its delimiters. If the Replay ends before the end of a bundle, the packets read are returned.
+//It returns false if there are no more packets, r.Error() then tells if reading failed.
+//Unlike a Packet set by Next, the packets of the bundle are only valid if the Replay doesn't reuse its buffers.
+func (r *Reader) NextBundle(bundle []Packet) ([]Packet, bool) {
+	bundle = bundle[:0]
+	var p Packet
+	if !r.Next(&p) {
+		return bundle, false
+	}
+	if !r.delimiter(&p) {
+		return append(bundle, p), true
+	}
+	for r.Next(&p) && !r.delimiter(&p) {
+		bundle = append(bundle, p)
+	}
+	return bundle, true
+}
+
+//Reports whether p is a Bundle Delimiter.
+func (r *Reader) delimiter(p *Packet) bool {
+	return r.tracker.protocol >= ProtocolBundles && p.State == StatePlay && p.ID == bundleDelimiterID
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+//Operators, longest first, so "<=" isn't read as "<" and "=".
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!"}
+
+//Splits expression into tokens.
+func lex(expression string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(expression) {
+		c := rune(expression[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLeftParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRightParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(expression) && rune(expression[end]) != c {
+				if expression[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expression) {
+				return nil, &SyntaxError{Position: i, Message: "unterminated string"}
+			}
+			text := expression[i : end+1]
+			if c == '\'' {
+				text = `"` + strings.ReplaceAll(text[1:len(text)-1], `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, &SyntaxError{Position: i, Message: "invalid string"}
+			}
+			tokens = append(tokens, token{tokenString, value, i})
+			i = end + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(expression) && unicode.IsDigit(rune(expression[i+1]))):
+			end := i + 1
+			for end < len(expression) && (unicode.IsDigit(rune(expression[end])) || expression[end] == '.' || expression[end] == 'x' || isHexDigit(expression[end])) {
+				end++
+			}
+			tokens = append(tokens, token{tokenNumber, expression[i:end], i})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i + 1
+			for end < len(expression) && (unicode.IsLetter(rune(expression[end])) || unicode.IsDigit(rune(expression[end])) || expression[end] == '_' || expression[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{tokenIdent, expression[i:end], i})
+			i = end
+		default:
+			matched := false
+			for _, operator := range operators {
+				if strings.HasPrefix(expression[i:], operator) {
+					tokens = append(tokens, token{tokenOperator, operator, i})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SyntaxError{Position: i, Message: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	tokens = append(tokens, token{tokenEOF, "", len(expression)})
+	return tokens, nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
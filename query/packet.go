@@ -0,0 +1,150 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/protocol"
+)
+
+//Returns the Fields of p: time, len, index, offset and id, the packet ID at the start of its data.
+//Other fields are looked up in extra, which can be nil. That is where decoded fields like entity_id come from.
+//ProtocolPacketFields adds the name of the packet.
+//Reading id doesn't move the position in p.
+func PacketFields(p *replayReader.Packet, extra Fields) Fields {
+	return FieldsFunc(func(name string) (any, bool) {
+		switch name {
+		case "time":
+			return p.Time, true
+		case "len":
+			return p.Len, true
+		case "index":
+			return p.Index, true
+		case "offset":
+			return p.Offset, true
+		case "id":
+			position, err := p.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, false
+			}
+			defer p.Seek(position, io.SeekStart)
+			_, err = p.Seek(0, io.SeekStart)
+			if err != nil {
+				return nil, false
+			}
+			id, _, err := p.ReadVarInt()
+			return id, err == nil
+		}
+		if extra == nil {
+			return nil, false
+		}
+		return extra.Field(name)
+	})
+}
+
+//Reports whether p matches the Query, using PacketFields(p, extra).
+func (q *Query) MatchPacket(p *replayReader.Packet, extra Fields) bool {
+	return q.Match(PacketFields(p, extra))
+}
+
+//Returns the Fields of p like PacketFields, with two more: state, the state p was sent in, like play,
+//and name, its name from protocol.PacketName in snake case, like entity_teleport or player_chat_message.
+//version is the protocol version the replay was recorded with. name is missing for packets PacketName doesn't know.
+func ProtocolPacketFields(p *protocol.Packet, version int, extra Fields) Fields {
+	fields := PacketFields(&p.Packet, extra)
+	return FieldsFunc(func(name string) (any, bool) {
+		switch name {
+		case "state":
+			return p.State.String(), true
+		case "name":
+			packetName := protocol.PacketName(version, p.State, p.ID)
+			if packetName == "" {
+				return nil, false
+			}
+			return snakeCase(packetName), true
+		}
+		return fields.Field(name)
+	})
+}
+
+//Returns Fields made of the exported fields of values, which are structs like decoded events, named in snake case:
+//EntityID is entity_id. Fields of embedded structs are included, and the first of values with a field gives its value.
+//Only numbers, strings, bools and fmt.Stringers like UUIDs are fields, the others are left out.
+func StructFields(values ...any) Fields {
+	return FieldsFunc(func(name string) (any, bool) {
+		for _, value := range values {
+			if field, ok := structField(reflect.ValueOf(value), name); ok {
+				return field, true
+			}
+		}
+		return nil, false
+	})
+}
+
+//Returns the field of the struct v named name in snake case.
+func structField(v reflect.Value, name string) (any, bool) {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous {
+			if value, ok := structField(v.Field(i), name); ok {
+				return value, true
+			}
+			continue
+		}
+		if snakeCase(splitWords(field.Name)) != name {
+			continue
+		}
+		value := v.Field(i)
+		if stringer, ok := value.Interface().(fmt.Stringer); ok {
+			return stringer.String(), true
+		}
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return value.Int(), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return int64(value.Uint()), true
+		case reflect.Float32, reflect.Float64:
+			return value.Float(), true
+		case reflect.String:
+			return value.String(), true
+		case reflect.Bool:
+			return value.Bool(), true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+//Puts spaces between the words of a Go name like EntityID or HTTPServer.
+func splitWords(name string) string {
+	var words strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			if !unicode.IsUpper(previous) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				words.WriteByte(' ')
+			}
+		}
+		words.WriteRune(r)
+	}
+	return words.String()
+}
+
+//Turns a packet name like "Tab-Complete" or "Entity Teleport" into tab_complete or entity_teleport.
+func snakeCase(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+}
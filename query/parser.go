@@ -0,0 +1,161 @@
+package query
+
+import "fmt"
+
+//parser turns tokens into nodes by recursive descent. From the lowest precedence up:
+//||, &&, !, comparisons and in, operands.
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != tokenEOF {
+		p.position++
+	}
+	return t
+}
+
+func (p *parser) isOperator(text string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.text == text
+}
+
+func (p *parser) isKeyword(text string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && t.text == text
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") || p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") || p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOperator("!") || (p.isKeyword("not") && p.tokens[p.position+1].kind != tokenEOF) {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return not{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch {
+	case t.kind == tokenOperator && t.text != "&&" && t.text != "||" && t.text != "!":
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return comparison{operator: t.text, left: left, right: right}, nil
+	case p.isKeyword("in"):
+		p.next()
+		return p.parseList(left, false)
+	case p.isKeyword("not"):
+		p.next()
+		if !p.isKeyword("in") {
+			found := p.peek()
+			return nil, &SyntaxError{Position: found.position, Message: fmt.Sprintf("expected in after not, found %q", found.text)}
+		}
+		p.next()
+		return p.parseList(left, true)
+	}
+	return left, nil
+}
+
+func (p *parser) parseList(value node, negated bool) (node, error) {
+	if t := p.next(); t.kind != tokenLeftParen {
+		return nil, &SyntaxError{Position: t.position, Message: "expected ( after in"}
+	}
+	list := []node{}
+	for p.peek().kind != tokenRightParen {
+		element, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, element)
+		if p.peek().kind == tokenComma {
+			p.next()
+		} else if p.peek().kind != tokenRightParen {
+			t := p.peek()
+			return nil, &SyntaxError{Position: t.position, Message: fmt.Sprintf("expected , or ) in list, found %q", t.text)}
+		}
+	}
+	p.next()
+	return in{negated: negated, value: value, list: list}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		value, err := parseNumber(t.text)
+		if err != nil {
+			return nil, &SyntaxError{Position: t.position, Message: fmt.Sprintf("invalid number %q", t.text)}
+		}
+		return literal{value}, nil
+	case tokenString:
+		return literal{t.text}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literal{true}, nil
+		case "false":
+			return literal{false}, nil
+		}
+		return field{t.text}, nil
+	case tokenLeftParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, &SyntaxError{Position: closing.position, Message: "expected )"}
+		}
+		return inner, nil
+	case tokenEOF:
+		return nil, &SyntaxError{Position: t.position, Message: "unexpected end of expression"}
+	}
+	return nil, &SyntaxError{Position: t.position, Message: fmt.Sprintf("unexpected %q", t.text)}
+}
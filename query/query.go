@@ -0,0 +1,295 @@
+//Package query compiles small filter expressions over packets, like
+//
+//	name == "player_chat_message" && time > 60000
+//	entity_id in (12, 40, 52) || !(len < 100)
+//
+//Expressions are made of field names, numbers, strings (in single or double quotes), true and false,
+//the comparisons == != < <= > >=, "in" and "not in" lists, and the boolean operators && || !.
+//Comparisons with a field that isn't there are false.
+package query
+
+import (
+	"fmt"
+	"strconv"
+)
+
+//Fields gives the values of the fields used in an expression.
+//Values can be any integer or float type, string or bool.
+type Fields interface {
+	Field(name string) (value any, ok bool)
+}
+
+//FieldsFunc lets a function be used as Fields.
+type FieldsFunc func(name string) (any, bool)
+
+func (f FieldsFunc) Field(name string) (any, bool) {
+	return f(name)
+}
+
+//Map lets a map be used as Fields.
+type Map map[string]any
+
+func (m Map) Field(name string) (any, bool) {
+	value, ok := m[name]
+	return value, ok
+}
+
+//SyntaxError is returned by Compile when an expression can't be parsed.
+//Position is the byte in the expression where the problem is.
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %d: %s", e.Position, e.Message)
+}
+
+//Query is a compiled expression.
+type Query struct {
+	expression string
+	root       node
+}
+
+//Compiles expression into a Query.
+func Compile(expression string) (*Query, error) {
+	tokens, err := lex(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := parser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := parser.peek(); next.kind != tokenEOF {
+		return nil, &SyntaxError{Position: next.position, Message: fmt.Sprintf("unexpected %q", next.text)}
+	}
+	return &Query{expression: expression, root: root}, nil
+}
+
+//Like Compile, but panics if expression can't be parsed. Useful for expressions that are constants.
+func MustCompile(expression string) *Query {
+	query, err := Compile(expression)
+	if err != nil {
+		panic(err)
+	}
+	return query
+}
+
+//Reports whether fields match the Query.
+func (q *Query) Match(fields Fields) bool {
+	value, ok := q.root.eval(fields)
+	result, isBool := value.(bool)
+	return ok && isBool && result
+}
+
+//Returns the expression the Query was compiled from.
+func (q *Query) String() string {
+	return q.expression
+}
+
+//Returns q as a filter function.
+func (q *Query) Filter() func(Fields) bool {
+	return q.Match
+}
+
+//node is a part of a compiled expression. eval returns false as ok if a field it needs isn't there.
+type node interface {
+	eval(fields Fields) (value any, ok bool)
+}
+
+type literal struct {
+	value any
+}
+
+func (n literal) eval(Fields) (any, bool) {
+	return n.value, true
+}
+
+type field struct {
+	name string
+}
+
+func (n field) eval(fields Fields) (any, bool) {
+	value, ok := fields.Field(n.name)
+	if !ok {
+		return nil, false
+	}
+	return normalize(value)
+}
+
+type not struct {
+	operand node
+}
+
+func (n not) eval(fields Fields) (any, bool) {
+	value, ok := n.operand.eval(fields)
+	result, isBool := value.(bool)
+	return !(ok && isBool && result), true
+}
+
+type logical struct {
+	and         bool
+	left, right node
+}
+
+func (n logical) eval(fields Fields) (any, bool) {
+	left := truthy(n.left.eval(fields))
+	if n.and && !left {
+		return false, true
+	}
+	if !n.and && left {
+		return true, true
+	}
+	return truthy(n.right.eval(fields)), true
+}
+
+type comparison struct {
+	operator    string
+	left, right node
+}
+
+func (n comparison) eval(fields Fields) (any, bool) {
+	left, ok := n.left.eval(fields)
+	if !ok {
+		return false, true
+	}
+	right, ok := n.right.eval(fields)
+	if !ok {
+		return false, true
+	}
+	order, comparable := compare(left, right)
+	if !comparable {
+		return n.operator == "!=", true
+	}
+	switch n.operator {
+	case "==":
+		return order == 0, true
+	case "!=":
+		return order != 0, true
+	case "<":
+		return order < 0, true
+	case "<=":
+		return order <= 0, true
+	case ">":
+		return order > 0, true
+	case ">=":
+		return order >= 0, true
+	}
+	return false, true
+}
+
+type in struct {
+	negated bool
+	value   node
+	list    []node
+}
+
+func (n in) eval(fields Fields) (any, bool) {
+	value, ok := n.value.eval(fields)
+	if !ok {
+		return false, true
+	}
+	for _, element := range n.list {
+		elementValue, ok := element.eval(fields)
+		if !ok {
+			continue
+		}
+		if order, comparable := compare(value, elementValue); comparable && order == 0 {
+			return !n.negated, true
+		}
+	}
+	return n.negated, true
+}
+
+func truthy(value any, ok bool) bool {
+	result, isBool := value.(bool)
+	return ok && isBool && result
+}
+
+//Turns every number into an int64 or float64, so they can be compared.
+func normalize(value any) (any, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case float32:
+		return float64(v), true
+	case float64, string, bool:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	}
+	return nil, false
+}
+
+//Returns -1, 0 or 1 as a is less than, equal to or more than b, and whether they can be compared at all.
+func compare(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return compareOrdered(a, b), true
+		case float64:
+			return compareOrdered(float64(a), b), true
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return compareOrdered(a, float64(b)), true
+		case float64:
+			return compareOrdered(a, b), true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return compareOrdered(a, b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			if a == b {
+				return 0, true
+			}
+			if !a {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered[T int64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+//Parses a number literal as an int64 if it can, as a float64 otherwise.
+func parseNumber(text string) (any, error) {
+	if integer, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return integer, nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
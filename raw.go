@@ -0,0 +1,166 @@
+package replayReader
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+//RecordingMode tells how the packets of a recording were stored.
+//The Replay Mod normally stores processed packets, as the game sees them once the connection decompressed them.
+//A raw recording stores them as they came over the network, with the compression framing of the protocol:
+//a VarInt holding the uncompressed length, or 0 if the packet isn't compressed, followed by the zlib-compressed packet.
+type RecordingMode int
+
+const (
+	ModeUnknown RecordingMode = iota
+	ModeProcessed
+	ModeRaw
+	//Packets are stored as they came over the network, like ModeRaw, but the recording starts before the
+	//Set Compression packet of the login, so only the packets after it have the compression framing.
+	ModeSetCompression
+)
+
+func (m RecordingMode) String() string {
+	switch m {
+	case ModeProcessed:
+		return "processed"
+	case ModeRaw:
+		return "raw"
+	case ModeSetCompression:
+		return "set compression"
+	}
+	return "unknown"
+}
+
+//Largest uncompressed packet the protocol allows. Raw packets claiming to be longer are rejected.
+const maxUncompressedLen = 8388608
+
+//Tells the Replay how its packets were stored. With ModeRaw, Next removes the compression framing of every packet,
+//so the packets it returns look the same as the ones of a processed recording.
+//Len is then the length of the decompressed packet, Offset is still the position of the stored packet in the file.
+//With ModeSetCompression, Next looks for the Set Compression packet in the login, returns it as it is,
+//and removes the compression framing of every packet after it. A login ending without one leaves the packets as they are.
+func WithRecordingMode(mode RecordingMode) Option {
+	return func(r *Replay) {
+		r.config.rawPackets = mode == ModeRaw
+		r.config.setCompression = mode == ModeSetCompression
+	}
+}
+
+//compression tells whether the packets of a ModeSetCompression replay have the compression framing.
+type compression int
+
+const (
+	//No Set Compression packet was found yet, and the login isn't over
+	compressionUnknown compression = iota
+	compressionOn
+	compressionOff
+)
+
+//Tells what the uncompressed packet data means for the packets after it.
+//Set Compression, ID 0x03 in the login, holds only the threshold, compression is turned off by a negative one.
+//Login Success, ID 0x02, and any ID that doesn't exist in the login show that the login is over.
+func findSetCompression(data []byte) compression {
+	p := Packet{Data: bytes.NewReader(data)}
+	id, _, err := p.ReadVarInt()
+	switch {
+	case err != nil:
+		return compressionUnknown
+	case id == 0x03:
+		threshold, _, err := p.ReadVarInt32()
+		if err != nil || p.Remaining() != 0 || threshold < 0 {
+			return compressionOff
+		}
+		return compressionOn
+	case id == 0x02 || id > 0x04:
+		return compressionOff
+	}
+	return compressionUnknown
+}
+
+//Removes the compression framing of a raw packet.
+func (r *Replay) normalizeRaw(data []byte) ([]byte, error) {
+	packet, _, err := decompressRaw(data)
+	if err != nil {
+		return nil, &CompressionError{Packet: r.packets, Offset: r.offset, Err: err}
+	}
+	return packet, nil
+}
+
+//Returns the packet held by the raw packet data, and whether it was compressed.
+func decompressRaw(data []byte) ([]byte, bool, error) {
+	framing := Packet{Data: bytes.NewReader(data)}
+	uncompressed, count, err := framing.ReadVarInt32()
+	if err != nil {
+		return nil, false, err
+	}
+	if uncompressed == 0 {
+		return data[count:], false, nil
+	}
+	if uncompressed < 0 || uncompressed > maxUncompressedLen {
+		return nil, true, ErrInvalidLength
+	}
+	inflater, err := zlib.NewReader(framing.Data)
+	if err != nil {
+		return nil, true, err
+	}
+	packet := make([]byte, uncompressed)
+	_, err = io.ReadFull(inflater, packet)
+	if err != nil {
+		return nil, true, err
+	}
+	//The uncompressed length has to be exact
+	if n, _ := inflater.Read(make([]byte, 1)); n > 0 {
+		return nil, true, errors.New("packet is longer than its uncompressed length")
+	}
+	return packet, true, nil
+}
+
+//Tells the mode of a recording by looking at up to sample of its packets.
+//A recording is raw if at least one packet is a valid compressed one and every packet has valid compression framing.
+//Uncompressed raw packets look the same as processed packets with the ID 0, so a raw recording
+//without any compressed packet in the sample is reported as processed.
+//A recording is ModeSetCompression if it has a Set Compression packet in its login, and valid compression framing after it.
+//If there are no packets, it returns ModeUnknown.
+func DetectMode(file io.Reader, sample int) (RecordingMode, error) {
+	replay := NewReplayFromReader(file)
+	var p Packet
+	packets, compressed := 0, 0
+	login := compressionUnknown
+	for packets < sample && replay.Next(&p) {
+		packets++
+		data := make([]byte, p.Len)
+		_, err := io.ReadFull(p.Data, data)
+		if err != nil {
+			return ModeUnknown, err
+		}
+		if login == compressionUnknown {
+			login = findSetCompression(data)
+			if login == compressionOn {
+				continue
+			}
+		}
+		_, isCompressed, err := decompressRaw(data)
+		if err != nil {
+			return ModeProcessed, nil
+		}
+		if isCompressed {
+			compressed++
+		}
+	}
+	if err := replay.Error(); err != nil {
+		return ModeUnknown, err
+	}
+	if packets == 0 {
+		return ModeUnknown, nil
+	}
+	if login == compressionOn {
+		return ModeSetCompression, nil
+	}
+	if compressed == 0 {
+		return ModeProcessed, nil
+	}
+	return ModeRaw, nil
+}
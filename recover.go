@@ -0,0 +1,135 @@
+package replayReader
+
+import "encoding/binary"
+
+//RecoveryOptions controls what ScanIndexRecover and Next in resync mode consider a plausible packet header.
+//MaxTimeRegression is how many milliseconds a packet's Time may be lower than the one before it.
+//MaxTimeJump is how many milliseconds a packet's Time may be higher than the one before it. Zero means no limit.
+//MaxPacketLen is the longest packet that is believed to be real. Zero means no limit.
+//Confirm is the number of consecutive plausible packets needed to accept a position after corruption.
+type RecoveryOptions struct {
+	MaxTimeRegression int
+	MaxTimeJump       int
+	MaxPacketLen      int
+	Confirm           int
+}
+
+//DefaultRecoveryOptions works well for recordings made by the Replay Mod.
+var DefaultRecoveryOptions = RecoveryOptions{
+	MaxTimeRegression: 1000,
+	MaxTimeJump:       10 * 60 * 1000,
+	MaxPacketLen:      8388608,
+	Confirm:           3,
+}
+
+//SkippedSpan is a part of a replay file that didn't contain plausible packets, so it was skipped.
+//Offset is the position of the first skipped byte, Len is the number of skipped bytes.
+type SkippedSpan struct {
+	Offset int64
+	Len    int64
+}
+
+//Works like ScanIndex, but doesn't stop at corrupted data.
+//When a header is implausible, it searches forward for the next position where options.Confirm packets
+//in a row look right, skips to it, and keeps going. All skipped parts of data are returned as SkippedSpans.
+func ScanIndexRecover(data []byte, options RecoveryOptions) (Index, []SkippedSpan) {
+	if options.Confirm < 1 {
+		options.Confirm = 1
+	}
+	index := make(Index, 0, len(data)/256)
+	var skipped []SkippedSpan
+	offset := 0
+	lastTime := -1
+	for offset < len(data) {
+		time, length, ok := plausibleHeader(data, offset, lastTime, options, true, headerLen)
+		if ok {
+			index = append(index, IndexEntry{Time: time, Offset: int64(offset), Len: length})
+			lastTime = time
+			offset += headerLen + length
+			continue
+		}
+		next := resync(data, offset+1, lastTime, options)
+		skipped = append(skipped, SkippedSpan{Offset: int64(offset), Len: int64(next - offset)})
+		offset = next
+	}
+	return index, skipped
+}
+
+//Returns the first position from start on, where options.Confirm plausible packets follow each other,
+//or where the plausible packets go on until the end of data. If there is none, it returns len(data).
+func resync(data []byte, start int, lastTime int, options RecoveryOptions) int {
+	for candidate := start; candidate < len(data); candidate++ {
+		if ok, _ := confirmPackets(data[candidate:], lastTime, options, headerLen, true); ok {
+			return candidate
+		}
+	}
+	return len(data)
+}
+
+//Reports whether data starts with options.Confirm plausible packets following each other after a packet at lastTime,
+//or with plausible packets going on until its end. headerSize is the size of the headers, which is more than headerLen
+//with checksum framing. If eof is false, data isn't the end of the file, and when it is too short to tell,
+//confirmPackets returns false with the number of bytes it needs. Otherwise need is 0.
+func confirmPackets(data []byte, lastTime int, options RecoveryOptions, headerSize int, eof bool) (ok bool, need int) {
+	offset := 0
+	time := lastTime
+	checkJump := false
+	for confirmed := 0; confirmed < max(options.Confirm, 1); confirmed++ {
+		if offset == len(data) && eof {
+			return confirmed > 0, 0
+		}
+		if !eof {
+			if len(data)-offset < headerSize {
+				return false, offset + headerSize
+			}
+			length := int64(binary.BigEndian.Uint32(data[offset+4:]))
+			if options.MaxPacketLen > 0 && length > int64(options.MaxPacketLen) {
+				return false, 0
+			}
+			if length > int64(len(data)-offset-headerSize) {
+				return false, offset + headerSize + int(length)
+			}
+		}
+		var length int
+		var ok bool
+		time, length, ok = plausibleHeader(data, offset, time, options, checkJump, headerSize)
+		if !ok {
+			return false, 0
+		}
+		checkJump = true
+		offset += headerSize + length
+	}
+	return true, 0
+}
+
+//Reads the header at offset and reports whether it looks like a real packet coming after a packet at lastTime.
+//lastTime is -1 if there is no packet before it. The jump limit is only checked when checkJump is true.
+//headerSize is the size of the header, the time and the length are its first 8 bytes.
+func plausibleHeader(data []byte, offset int, lastTime int, options RecoveryOptions, checkJump bool, headerSize int) (time int, length int, ok bool) {
+	if len(data)-offset < headerSize {
+		return 0, 0, false
+	}
+	time64 := int64(binary.BigEndian.Uint32(data[offset:]))
+	length64 := int64(binary.BigEndian.Uint32(data[offset+4:]))
+	if length64 > int64(len(data)-offset-headerSize) {
+		return 0, 0, false
+	}
+	if options.MaxPacketLen > 0 && length64 > int64(options.MaxPacketLen) {
+		return 0, 0, false
+	}
+	if !timeFollows(time64, int64(lastTime), options, checkJump) {
+		return 0, 0, false
+	}
+	return int(time64), int(length64), true
+}
+
+//Reports whether a packet at time can come after a packet at lastTime, which is -1 if there is none.
+func timeFollows(time int64, lastTime int64, options RecoveryOptions, checkJump bool) bool {
+	if lastTime < 0 {
+		return true
+	}
+	if time < lastTime-int64(options.MaxTimeRegression) {
+		return false
+	}
+	return !checkJump || options.MaxTimeJump <= 0 || time <= lastTime+int64(options.MaxTimeJump)
+}
@@ -0,0 +1,190 @@
+//Package render draws pictures of the world rebuilt from a replay.
+package render
+
+import (
+	"errors"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/bela333/replayReader/tracking"
+)
+
+//Palette returns the color of a block state. Transparent colors leave the block out, so the block below it shows.
+type Palette func(blockState int) color.Color
+
+//Point is a position on the map, in block coordinates.
+type Point struct {
+	X, Z float64
+}
+
+//Options controls TopDown.
+//Scale is the number of pixels per block, at least 1.
+//Palette gives the colors of blocks. If it is nil, HashPalette is used.
+//Path is drawn over the map in PathColor, for example the movement of the player.
+type Options struct {
+	Scale     int
+	Palette   Palette
+	Path      []Point
+	PathColor color.Color
+}
+
+//Colors every block state with a color of its own, made from a hash of its ID. Air (0) is left out.
+//It works with every protocol version, but the colors mean nothing.
+func HashPalette(blockState int) color.Color {
+	if blockState == 0 {
+		return color.Transparent
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte{byte(blockState), byte(blockState >> 8), byte(blockState >> 16), byte(blockState >> 24)})
+	sum := hash.Sum32()
+	return color.RGBA{R: byte(sum), G: byte(sum >> 8), B: byte(sum >> 16), A: 255}
+}
+
+//Colors of common blocks before 1.13, by block ID.
+var legacyColors = map[int]color.RGBA{
+	1:  {125, 125, 125, 255},
+	2:  {95, 159, 53, 255},
+	3:  {134, 96, 67, 255},
+	4:  {110, 110, 110, 255},
+	5:  {157, 128, 79, 255},
+	8:  {47, 67, 244, 255},
+	9:  {47, 67, 244, 255},
+	10: {207, 91, 20, 255},
+	11: {207, 91, 20, 255},
+	12: {219, 211, 160, 255},
+	13: {126, 124, 122, 255},
+	17: {102, 81, 51, 255},
+	18: {60, 120, 30, 255},
+	24: {216, 203, 155, 255},
+	31: {80, 140, 40, 255},
+	78: {240, 250, 250, 255},
+	79: {160, 180, 250, 255},
+	80: {240, 250, 250, 255},
+	82: {158, 164, 176, 255},
+	87: {111, 54, 52, 255},
+}
+
+//Colors the common blocks of versions before 1.13, where a block state is the block ID times 16 plus its metadata.
+//Other blocks get a HashPalette color of their block ID.
+func LegacyPalette(blockState int) color.Color {
+	id := blockState >> 4
+	if id == 0 {
+		return color.Transparent
+	}
+	if c, ok := legacyColors[id]; ok {
+		return c
+	}
+	return HashPalette(id)
+}
+
+//Returned by TopDown when there are no blocks and no Path to draw.
+var ErrEmpty = errors.New("render: nothing to draw")
+
+//Draws the top surface of the blocks of world, as seen from above, with north at the top.
+//Every column shows its highest opaque block, shaded lighter or darker when it is higher or lower than the one north of it.
+//The picture covers the blocks and the Path, even where no chunk was loaded.
+func TopDown(world *tracking.World, options Options) (*image.RGBA, error) {
+	if options.Scale < 1 {
+		options.Scale = 1
+	}
+	if options.Palette == nil {
+		options.Palette = HashPalette
+	}
+	type column struct {
+		x, z int
+	}
+	type top struct {
+		y     int
+		color color.RGBA
+	}
+	surface := make(map[column]top)
+	bounds := image.Rectangle{}
+	first := true
+	extend := func(x, z int) {
+		point := image.Rectangle{Min: image.Pt(x, z), Max: image.Pt(x+1, z+1)}
+		if first {
+			bounds = point
+			first = false
+		} else {
+			bounds = bounds.Union(point)
+		}
+	}
+	//Each goes down every chunk, so the first opaque block of a column is its top
+	world.Each(func(pos tracking.BlockPos, state int) {
+		key := column{pos.X, pos.Z}
+		if _, ok := surface[key]; ok {
+			return
+		}
+		c := color.RGBAModel.Convert(options.Palette(state)).(color.RGBA)
+		if c.A == 0 {
+			return
+		}
+		surface[key] = top{pos.Y, c}
+		extend(pos.X, pos.Z)
+	})
+	for _, point := range options.Path {
+		extend(int(math.Floor(point.X)), int(math.Floor(point.Z)))
+	}
+	if first {
+		return nil, ErrEmpty
+	}
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*options.Scale, bounds.Dy()*options.Scale))
+	for key, block := range surface {
+		c := block.color
+		if north, ok := surface[column{key.x, key.z - 1}]; ok {
+			switch {
+			case block.y > north.y:
+				c = shade(c, 1.15)
+			case block.y < north.y:
+				c = shade(c, 0.85)
+			}
+		}
+		x0, z0 := (key.x-bounds.Min.X)*options.Scale, (key.z-bounds.Min.Y)*options.Scale
+		for dz := 0; dz < options.Scale; dz++ {
+			for dx := 0; dx < options.Scale; dx++ {
+				img.SetRGBA(x0+dx, z0+dz, c)
+			}
+		}
+	}
+	if len(options.Path) > 1 {
+		pathColor := options.PathColor
+		if pathColor == nil {
+			pathColor = color.RGBA{255, 0, 0, 255}
+		}
+		toPixel := func(p Point) (float64, float64) {
+			return (p.X - float64(bounds.Min.X)) * float64(options.Scale), (p.Z - float64(bounds.Min.Y)) * float64(options.Scale)
+		}
+		for i := 1; i < len(options.Path); i++ {
+			x0, y0 := toPixel(options.Path[i-1])
+			x1, y1 := toPixel(options.Path[i])
+			drawLine(img, x0, y0, x1, y1, pathColor)
+		}
+	}
+	return img, nil
+}
+
+//Multiplies the brightness of c by factor.
+func shade(c color.RGBA, factor float64) color.RGBA {
+	scale := func(v uint8) uint8 {
+		return uint8(math.Min(255, float64(v)*factor))
+	}
+	return color.RGBA{scale(c.R), scale(c.G), scale(c.B), c.A}
+}
+
+//Draws a line from (x0, y0) to (x1, y1) on img.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.Color) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		img.Set(int(x0+(x1-x0)*t), int(y0+(y1-y0)*t), c)
+	}
+}
+
+//Writes img to w as a PNG file.
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
@@ -3,52 +3,184 @@ package replayReader
 import (
 	"bytes"
 	"encoding/binary"
+	"hash"
 	"io"
 	"math"
 )
 
-func NewReplay(r io.ReadCloser) *Replay {
-	replay := Replay{r, nil}
+func NewReplay(r io.ReadCloser, options ...Option) *Replay {
+	return NewReplayFromReader(r, options...)
+}
+
+//Returns a Replay reading from r. Unlike NewReplay, r doesn't have to be an io.Closer,
+//but if it is one, Close closes it. If r is an io.Seeker, methods going back in the file can be used.
+func NewReplayFromReader(r io.Reader, options ...Option) *Replay {
+	replay := Replay{replayFile: r}
+	for _, option := range options {
+		option(&replay)
+	}
 	return &replay
 }
 
 type Replay struct {
-	replayFile io.ReadCloser
+	replayFile io.Reader
 	error      error
+	config     config
+	meter      *meter
+	hash       hash.Hash
+	packets    int
+	offset     int64
+	lastTime   int
+	resyncs    []Resync
+	pending    *lazyPayload
+	compressed compression
+	buffer     []byte
+	reader     *bytes.Reader
+	header     [headerLen + 4]byte
 }
 
 //Sets p to the next element in the Replay file.
 //If reading the packet was successful, it returns true.
 //If it wasn't successful, you should run r.Error(), to get the error Next() returned.
 //If Next() got to EOF, it returns false and r.Error() returns nil.
+//If the file ends in the middle of a packet, r.Error() returns an error that matches both ErrPacketTruncated and io.ErrUnexpectedEOF.
+//If reading the file fails for another reason, r.Error() returns a *ReplayError telling which packet was being read.
 func (r *Replay) Next(p *Packet) (success bool) {
-	var time uint32
-	err := binary.Read(r.replayFile, binary.BigEndian, &time)
+	if reader, ok := r.replayFile.(*resyncReader); ok {
+		reader.mark()
+	}
+	if r.pending != nil {
+		if err := r.skipPending(); err != nil {
+			r.error = err
+			return false
+		}
+	}
+	headerStart := r.meter.now()
+	header := r.header[:r.headerSize()]
+	_, err := io.ReadFull(r.replayFile, header)
 	if err != nil {
 		if err == io.EOF {
 			return false
 		}
-		r.error = err
-		return false
+		return r.corrupt(p, r.truncated(err, "header"))
+	}
+	time := binary.BigEndian.Uint32(header)
+	length := binary.BigEndian.Uint32(header[4:])
+	var checksum uint32
+	if r.config.checksumFraming {
+		checksum = binary.BigEndian.Uint32(header[8:])
+	}
+	r.meter.header(headerStart, len(header))
+	if max := r.config.limits.MaxPacketLen; max > 0 && uint64(length) > uint64(max) {
+		return r.corrupt(p, &LimitError{Packet: r.packets, Offset: r.offset, Limit: "packet length", Len: int(length), Max: max})
+	}
+	if _, ok := r.replayFile.(*resyncReader); ok {
+		if err := r.checkHeader(int(time), int(length)); err != nil {
+			return r.corrupt(p, err)
+		}
 	}
 
-	var len uint32
-	err = binary.Read(r.replayFile, binary.BigEndian, &len)
+	if int32(length) < 0 {
+		return r.corrupt(p, &LengthError{Packet: r.packets, Offset: r.offset, Field: "packet length", Len: int64(int32(length)), Remaining: -1})
+	}
+	if length > payloadChunk {
+		if seeker, ok := r.replayFile.(io.Seeker); ok {
+			if remaining := remainingIn(seeker); remaining >= 0 && int64(length) > remaining {
+				return r.corrupt(p, &LengthError{Packet: r.packets, Offset: r.offset, Field: "packet length", Len: int64(length), Remaining: remaining})
+			}
+		}
+	}
+
+	if r.lazy() {
+		return r.nextLazy(p, int(time), int64(length))
+	}
+	payloadStart := r.meter.now()
+	data, err := r.readData(int64(length))
+	isTruncated := false
 	if err != nil {
-		r.error = err
-		return false
+		if r.config.partialPackets && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+			isTruncated = true
+		} else {
+			return r.corrupt(p, r.truncated(err, "data"))
+		}
 	}
+	r.hashPacket(time, length, checksum, data)
+	err = r.verifyChecksum(data, checksum)
+	if err != nil {
+		return r.corrupt(p, err)
+	}
+	stored := int64(len(data))
+	if (r.config.rawPackets || r.compressed == compressionOn) && !isTruncated {
+		data, err = r.normalizeRaw(data)
+		if err != nil {
+			return r.corrupt(p, err)
+		}
+		length = uint32(len(data))
+	} else if r.config.setCompression && r.compressed == compressionUnknown {
+		r.compressed = findSetCompression(data)
+	}
+	dataReader := r.dataReader(data)
+	r.meter.payload(payloadStart, int(stored))
+	r.meter.packet()
+
+	headerSize := int64(r.headerSize())
+	*p = Packet{
+		Time:       int(time),
+		Len:        int(length),
+		Data:       dataReader,
+		Truncated:  isTruncated,
+		Index:      r.packets,
+		Offset:     r.offset,
+		dataOffset: r.offset + headerSize,
+		config:     &r.config,
+		data:       data,
+	}
+	r.packets++
+	r.offset += headerSize + stored
+	r.lastTime = int(time)
+	return true
+}
 
-	data := make([]byte, len)
-	_, err = io.ReadAtLeast(r.replayFile, data, int(len))
+//Returns the length of the header of a packet in the replay file.
+func (r *Replay) headerSize() int {
+	if r.config.checksumFraming {
+		return headerLen + 4
+	}
+	return headerLen
+}
+
+//Starts reading the Replay again from the beginning of its file.
+func (r *Replay) rewind() error {
+	seeker, ok := r.replayFile.(io.Seeker)
+	if !ok {
+		return ErrNotSeekable
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
 	if err != nil {
-		r.error = err
-		return false
+		return err
+	}
+	r.error = nil
+	r.packets = 0
+	r.offset = 0
+	r.lastTime = 0
+	r.resyncs = nil
+	r.compressed = compressionUnknown
+	if r.pending != nil {
+		r.pending.err = ErrDataSkipped
+		r.pending = nil
+	}
+	if r.hash != nil {
+		r.hash.Reset()
 	}
-	dataReader := bytes.NewReader(data)
+	return nil
+}
 
-	*p = Packet{Time: int(time), Len: int(len), Data: dataReader}
-	return true
+//Closes the file of the Replay, if it is an io.Closer. Next never closes it on its own.
+func (r *Replay) Close() error {
+	if closer, ok := r.replayFile.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
 }
 
 //Returns the error that happened after the latest Next()
@@ -60,17 +192,34 @@ func (r Replay) Error() (err error) {
 //Time is the milliseconds elapsed since the beginning of the Replay.
 //Len is the length of the packet.
 //Data is an io.ReadSeeker containing all the information of the packet.
+//Truncated is true if the file ended before all Len bytes of the packet, so Data only holds the ones that were there.
+//Next only returns truncated packets if the Replay was created with WithPartialPackets.
+//Index is the number of the packet in the Replay, starting at 0.
+//Offset is the position of the packet's header in the replay file.
 type Packet struct {
-	Time int
-	Len  int
-	Data io.ReadSeeker
+	Time      int
+	Len       int
+	Data      io.ReadSeeker
+	Truncated bool
+	Index     int
+	Offset    int64
+
+	dataOffset int64
+	config     *config
+	scratch    [8]byte
+	data       []byte
 }
 
 //Reads an unsigned byte from the packet. Len: 1 byte
 func (p *Packet) ReaduByte() (byte, error) {
-	byteArray := make([]byte, 1)
-	_, err := p.Data.Read(byteArray)
-	return byteArray[0], err
+	if reader, ok := p.Data.(io.ByteReader); ok {
+		return reader.ReadByte()
+	}
+	data, err := p.readFixed(1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
 }
 
 //Reads a signed byte from the packet. Len: 1 byte
@@ -81,46 +230,83 @@ func (p *Packet) ReadByte() (int8, error) {
 
 //Reads a short from the packet. Len: 2 bytes
 func (p *Packet) ReadShort() (int16, error) {
-	var output int16
-	err := binary.Read(p.Data, binary.BigEndian, &output)
-	return output, err
+	output, err := p.ReaduShort()
+	return int16(output), err
 }
 
 //Reads an unsigned short from the packet. Len: 2 bytes
 func (p *Packet) ReaduShort() (uint16, error) {
-	var output uint16
-	err := binary.Read(p.Data, binary.BigEndian, &output)
-	return output, err
+	data, err := p.readFixed(2)
+	if err != nil {
+		return 0, err
+	}
+	return p.uint16(data), nil
 }
 
 //Reads an Integer from the packet. Len: 4 bytes
 func (p *Packet) ReadInt() (int32, error) {
-	var output int32
-	err := binary.Read(p.Data, binary.BigEndian, &output)
-	return output, err
+	data, err := p.readFixed(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(p.uint32(data)), nil
 }
 
 //Reads a Long from the packet. Len: 8 bytes
 func (p *Packet) ReadLong() (int64, error) {
-	var output int64
-	err := binary.Read(p.Data, binary.BigEndian, &output)
-	return output, err
+	data, err := p.readFixed(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(p.uint64(data)), nil
 }
 
 //Reads a Float from the packet. Len: 4 bytes
 func (p *Packet) ReadFloat() (float32, error) {
-	var uint32Form uint32
-	err := binary.Read(p.Data, binary.BigEndian, &uint32Form)
-	float32Form := math.Float32frombits(uint32Form)
-	return float32Form, err
+	data, err := p.readFixed(4)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(p.uint32(data)), nil
 }
 
 //Reads a Double-precision Float from the packet. Len: 8 bytes
 func (p *Packet) ReadDouble() (float64, error) {
-	var uint64Form uint64
-	err := binary.Read(p.Data, binary.BigEndian, &uint64Form)
-	float64Form := math.Float64frombits(uint64Form)
-	return float64Form, err
+	data, err := p.readFixed(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(p.uint64(data)), nil
+}
+
+//Decode the numbers read by readFixed, in the byte order of the Replay.
+func (p *Packet) uint16(data []byte) uint16 {
+	if p.getConfig().littleEndian {
+		return binary.LittleEndian.Uint16(data)
+	}
+	return binary.BigEndian.Uint16(data)
+}
+
+func (p *Packet) uint32(data []byte) uint32 {
+	if p.getConfig().littleEndian {
+		return binary.LittleEndian.Uint32(data)
+	}
+	return binary.BigEndian.Uint32(data)
+}
+
+func (p *Packet) uint64(data []byte) uint64 {
+	if p.getConfig().littleEndian {
+		return binary.LittleEndian.Uint64(data)
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+//Reads n bytes, at most 8, into the scratch buffer of the packet. The result is only valid until the next read.
+//Like binary.Read, it returns io.EOF if no bytes were left and io.ErrUnexpectedEOF if some were.
+func (p *Packet) readFixed(n int) ([]byte, error) {
+	data := p.scratch[:n]
+	_, err := io.ReadFull(p.Data, data)
+	return data, err
 }
 
 //Reads a Boolean from the packet. Len: 1 byte
@@ -130,7 +316,12 @@ func (p *Packet) ReadBool() (bool, error) {
 }
 
 //Reads a Variable-length Integer from the packet. Len: len bytes
+//If the Replay was created with WithExactVarInts, the result wraps around like a Java int, see ReadVarInt32.
 func (p *Packet) ReadVarInt() (n int, len int, err error) {
+	if p.getConfig().exactVarInts {
+		n32, len, err := p.ReadVarInt32()
+		return int(n32), len, err
+	}
 	count := 0
 	result := int(0)
 	last := byte(128)
@@ -138,7 +329,7 @@ func (p *Packet) ReadVarInt() (n int, len int, err error) {
 	for (last & 128) != 0 {
 
 		if count >= 5 {
-			return result, count, VarIntTooBigError
+			return result, count, p.varIntError(count)
 		}
 
 		last, err = p.ReaduByte()
@@ -155,6 +346,7 @@ func (p *Packet) ReadVarInt() (n int, len int, err error) {
 }
 
 //Reads a Variable-length Long from the packet. Len: len bytes
+//Since the result is 64 bits wide it already has the protocol's semantics, it returns the same as ReadVarLong64.
 func (p *Packet) ReadVarLong() (n int64, len int, err error) {
 	count := 0
 	result := int64(0)
@@ -163,7 +355,7 @@ func (p *Packet) ReadVarLong() (n int64, len int, err error) {
 	for (last & 128) != 0 {
 
 		if count >= 10 {
-			return result, count, VarIntTooBigError
+			return result, count, p.varIntError(count)
 		}
 
 		last, err = p.ReaduByte()
@@ -180,24 +372,157 @@ func (p *Packet) ReadVarLong() (n int64, len int, err error) {
 }
 
 //Reads a byte array from the packet. Len: len bytes
+//If the Replay has a MaxArrayLen limit, longer arrays are reported with a *LimitError.
+//Negative lengths and lengths longer than the rest of the packet are reported with a *LengthError.
 func (p *Packet) ReaduByteArray(n int) (bytes []byte, len int, error error) {
+	return p.readByteArray(n, "array length")
+}
+
+//Reads a byte array of length n, whose length was read from the field called field.
+func (p *Packet) readByteArray(n int, field string) ([]byte, int, error) {
+	err := p.checkLength(n, field)
+	if err != nil {
+		return nil, 0, err
+	}
 	outputByteArray := make([]byte, n)
-	n, err := io.ReadAtLeast(p.Data, outputByteArray, n)
+	n, err = io.ReadAtLeast(p.Data, outputByteArray, n)
 	return outputByteArray, n, err
 }
 
+//Checks that n bytes, whose number was read from the field called field, can be read from the packet.
+func (p *Packet) checkLength(n int, field string) error {
+	if max := p.getConfig().limits.MaxArrayLen; max > 0 && (n > max || n < 0) {
+		return &LimitError{Packet: p.Index, Offset: p.errorOffset(0), Limit: field, Len: n, Max: max}
+	}
+	remaining := p.remaining()
+	if n < 0 || (remaining >= 0 && int64(n) > remaining) {
+		return &LengthError{Packet: p.Index, Offset: p.errorOffset(0), Field: field, Len: int64(n), Remaining: remaining}
+	}
+	return nil
+}
+
+//Reads the rest of the packet, from the current position to its end. Len: len bytes
+//It is for fields that take up whatever is left of the packet, like the data of plugin messages.
+//If the Replay has a MaxArrayLen limit, longer rests are reported with a *LimitError.
+func (p *Packet) ReadRest() (bytes []byte, len int, err error) {
+	return p.readByteArray(p.Remaining(), "rest of packet")
+}
+
 //Reads a string from the packet. Len: len bytes
+//If the Replay was created with WithStrictStrings, invalid strings are reported with an *InvalidStringError.
+//Strings longer than the MaxStringLen limit of the Replay, or DefaultMaxStringLen if it has none,
+//are reported with a *LimitError, before anything is allocated for them.
 func (p *Packet) ReadString() (result string, len int, error error) {
+	return p.readString(p.maxStringLen())
+}
+
+//Works like ReadString, but strings longer than max UTF-16 code units are reported with a *LimitError,
+//whatever the limits of the Replay are. Use it for fields the protocol gives a shorter maximum, like player names.
+func (p *Packet) ReadStringMax(max int) (result string, len int, error error) {
+	return p.readString(max)
+}
+
+func (p *Packet) maxStringLen() int {
+	max := p.getConfig().limits.MaxStringLen
+	if max == 0 {
+		return DefaultMaxStringLen
+	}
+	return max
+}
+
+//Reads a string of at most max UTF-16 code units, or of any length if max is negative.
+func (p *Packet) readString(max int) (string, int, error) {
 	stringLen, stringLenLen, err := p.ReadVarInt()
-	if error != nil {
+	if err != nil {
 		return "", stringLenLen, err
 	}
-	outputString, byteArrayLen, err := p.ReaduByteArray(stringLen)
-	return string(outputString), stringLenLen + byteArrayLen, err
-
+	config := p.getConfig()
+	if config.strictStrings && stringLen > MaxStringLength*3 {
+		return "", stringLenLen, &InvalidStringError{Packet: p.Index, Offset: p.errorOffset(stringLenLen), Len: stringLen, TooLong: true, Reason: "too long"}
+	}
+	//A unit takes up to 3 bytes, so longer strings can be refused from their length alone
+	if max >= 0 && (stringLen > max*3 || stringLen < 0) {
+		return "", stringLenLen, &LimitError{Packet: p.Index, Offset: p.errorOffset(stringLenLen), Limit: "string length", Len: stringLen, Max: max * 3}
+	}
+	outputString, byteArrayLen, err := p.readByteArray(stringLen, "string length")
+	length := stringLenLen + byteArrayLen
+	if err == nil && max >= 0 && stringLen > max {
+		if units := utf16Len(outputString); units > max {
+			return "", length, &LimitError{Packet: p.Index, Offset: p.errorOffset(length), Limit: "string length", Len: units, Max: max}
+		}
+	}
+	if err == nil && config.strictStrings {
+		err = validateString(outputString)
+		if err != nil {
+			stringErr := err.(*InvalidStringError)
+			stringErr.Packet, stringErr.Offset = p.Index, p.errorOffset(length)
+			return "", length, err
+		}
+	}
+	if config.interner != nil && err == nil {
+		return config.interner.Intern(outputString), length, err
+	}
+	return string(outputString), length, err
 }
 
 //Same as io.Seeker.Seek
 func (p *Packet) Seek(offset int64, whence int) (int64, error) {
 	return p.Data.Seek(offset, whence)
 }
+
+//Payloads up to this size are allocated at once. Longer ones grow while they are read,
+//so a corrupted length can't allocate more memory than the file actually has.
+const payloadChunk = 1 << 20
+
+//Reads n bytes of packet data from r.
+//If r ends before that, the bytes that were read are returned along with the error.
+func readPayload(r io.Reader, n int64) ([]byte, error) {
+	if n <= payloadChunk {
+		data := make([]byte, n)
+		read, err := io.ReadFull(r, data)
+		return data[:read], err
+	}
+	if int64(int(n)) != n {
+		return nil, ErrInvalidLength
+	}
+	var buffer bytes.Buffer
+	buffer.Grow(payloadChunk)
+	read, err := io.CopyN(&buffer, r, n)
+	if read < n && err == io.EOF {
+		if read == 0 {
+			return nil, io.EOF
+		}
+		return buffer.Bytes(), io.ErrUnexpectedEOF
+	}
+	return buffer.Bytes(), err
+}
+
+//Returns the number of unread bytes in the packet, or -1 if it can't be told.
+func (p *Packet) remaining() int64 {
+	if lener, ok := p.Data.(interface{ Len() int }); ok {
+		return int64(lener.Len())
+	}
+	return remainingIn(p.Data)
+}
+
+//Returns the number of bytes between the current position of s and its end, or -1 if it can't be told.
+func remainingIn(s io.Seeker) int64 {
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+	_, err = s.Seek(current, io.SeekStart)
+	if err != nil {
+		return -1
+	}
+	return end - current
+}
+
+//Returns the error for a VarInt that is still going on after consumed bytes.
+func (p *Packet) varIntError(consumed int) error {
+	return &VarIntError{Packet: p.Index, Offset: p.errorOffset(consumed), Len: consumed}
+}
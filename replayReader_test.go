@@ -0,0 +1,311 @@
+package replayReader_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/replaytest"
+)
+
+//Returns a replay of count Keep Alive packets, 10ms apart.
+func keepAlives(count int) *replaytest.Builder {
+	replay := replaytest.NewReplay()
+	for i := range count {
+		replay.AddPacket(i*10, replaytest.NewPacket(0x00).VarInt(i))
+	}
+	return replay
+}
+
+//Reads every packet of replay, and returns their Times and whether the last one was truncated.
+func readAll(replay *replayReader.Replay) ([]int, bool) {
+	var times []int
+	truncated := false
+	var p replayReader.Packet
+	for replay.Next(&p) {
+		times = append(times, p.Time)
+		truncated = p.Truncated
+	}
+	return times, truncated
+}
+
+func TestNextFraming(t *testing.T) {
+	valid := keepAlives(3).Bytes()
+	tests := []struct {
+		name      string
+		file      []byte
+		options   []replayReader.Option
+		packets   int
+		truncated bool
+		err       []error
+	}{
+		{"valid", valid, nil, 3, false, nil},
+		{"empty", nil, nil, 0, false, nil},
+		{"header cut short", valid[:len(valid)-5], nil, 2, false, []error{replayReader.ErrPacketTruncated, io.ErrUnexpectedEOF, replayReader.ErrCorrupt}},
+		{"data cut short", valid[:len(valid)-1], nil, 2, false, []error{replayReader.ErrPacketTruncated, io.ErrUnexpectedEOF}},
+		{"partial packet", valid[:len(valid)-1], []replayReader.Option{replayReader.WithPartialPackets()}, 3, true, nil},
+		{"negative length", []byte{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF}, nil, 0, false, []error{replayReader.ErrInvalidLength, replayReader.ErrCorrupt}},
+		{"too long", replaytest.NewReplay().Add(0, make([]byte, 100)).Bytes(), []replayReader.Option{replayReader.WithLimits(replayReader.Limits{MaxPacketLen: 10})}, 0, false, []error{replayReader.ErrPacketTooLarge}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replay := replayReader.NewReplayFromBytes(test.file, test.options...)
+			times, truncated := readAll(replay)
+			if len(times) != test.packets || truncated != test.truncated {
+				t.Errorf("got %d packets, truncated %v, want %d, truncated %v", len(times), truncated, test.packets, test.truncated)
+			}
+			err := replay.Error()
+			if test.err == nil && err != nil {
+				t.Errorf("got error %v, want none", err)
+			}
+			for _, target := range test.err {
+				if !errors.Is(err, target) {
+					t.Errorf("error %v doesn't match %v", err, target)
+				}
+			}
+		})
+	}
+}
+
+func TestNextTruncatedMatchesScanIndex(t *testing.T) {
+	file := keepAlives(3).Bytes()
+	file = file[:len(file)-1]
+	var framing *replayReader.FramingError
+	replay := replayReader.NewReplayFromBytes(file)
+	readAll(replay)
+	if !errors.As(replay.Error(), &framing) {
+		t.Fatalf("Next: got %v, want a *FramingError", replay.Error())
+	}
+	_, err := replayReader.ScanIndex(file)
+	var scanned *replayReader.FramingError
+	if !errors.As(err, &scanned) || *scanned != *framing {
+		t.Errorf("ScanIndex: got %v, want %v", err, framing)
+	}
+}
+
+func TestResync(t *testing.T) {
+	garbage := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	before := keepAlives(3).Bytes()
+	after := replaytest.NewReplay().
+		AddPacket(30, replaytest.NewPacket(0x00).VarInt(3)).
+		AddPacket(40, replaytest.NewPacket(0x00).VarInt(4)).
+		Bytes()
+	tests := []struct {
+		name    string
+		file    []byte
+		times   []int
+		resyncs []replayReader.Resync
+	}{
+		{"clean", keepAlives(5).Bytes(), []int{0, 10, 20, 30, 40}, nil},
+		{"garbage between packets", concat(before, garbage, after), []int{0, 10, 20, 30, 40}, []replayReader.Resync{{Packet: 3, Offset: int64(len(before)), Len: int64(len(garbage))}}},
+		{"garbage at the end", concat(before, garbage), []int{0, 10, 20}, []replayReader.Resync{{Packet: 3, Offset: int64(len(before)), Len: int64(len(garbage))}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replay := replayReader.NewReplayFromBytes(test.file, replayReader.WithResync(replayReader.DefaultRecoveryOptions))
+			times, _ := readAll(replay)
+			if replay.Error() != nil {
+				t.Fatal(replay.Error())
+			}
+			if !slices.Equal(times, test.times) {
+				t.Errorf("got times %v, want %v", times, test.times)
+			}
+			resyncs := replay.Resyncs()
+			if len(resyncs) != len(test.resyncs) {
+				t.Fatalf("got %d resyncs, want %d", len(resyncs), len(test.resyncs))
+			}
+			for i, resync := range resyncs {
+				want := test.resyncs[i]
+				if resync.Packet != want.Packet || resync.Offset != want.Offset || resync.Len != want.Len {
+					t.Errorf("resync %d is packet %d, offset %d, len %d, want packet %d, offset %d, len %d",
+						i, resync.Packet, resync.Offset, resync.Len, want.Packet, want.Offset, want.Len)
+				}
+			}
+		})
+	}
+}
+
+func TestWriterRoundtrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		packets []replaytest.PacketBuilder
+	}{
+		{"none", nil},
+		{"one", []replaytest.PacketBuilder{*replaytest.NewPacket(0x02).String("hello")}},
+		{"several", []replaytest.PacketBuilder{*replaytest.NewPacket(0x00).VarInt(1), *replaytest.NewPacket(0x0F).Long(-1).Bool(true), *replaytest.NewPacket(0x21)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var file bytes.Buffer
+			writer := replayReader.NewReplayWriter(&file)
+			for i := range test.packets {
+				if err := writer.WritePacket(i*50, test.packets[i].Bytes()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+			replay := replayReader.NewReplayFromBytes(file.Bytes())
+			var p replayReader.Packet
+			read := 0
+			for replay.Next(&p) {
+				if p.Time != read*50 || !bytes.Equal(p.Bytes(), test.packets[read].Bytes()) {
+					t.Errorf("packet %d is %d %x, want %d %x", read, p.Time, p.Bytes(), read*50, test.packets[read].Bytes())
+				}
+				read++
+			}
+			if replay.Error() != nil || read != len(test.packets) {
+				t.Errorf("read %d packets with error %v, want %d", read, replay.Error(), len(test.packets))
+			}
+			//And back again, through WriteFrom
+			var copied bytes.Buffer
+			writer = replayReader.NewReplayWriter(&copied)
+			replay = replayReader.NewReplayFromBytes(file.Bytes())
+			for replay.Next(&p) {
+				if err := writer.WriteFrom(&p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			writer.Close()
+			if !bytes.Equal(copied.Bytes(), file.Bytes()) {
+				t.Errorf("WriteFrom wrote %x, want %x", copied.Bytes(), file.Bytes())
+			}
+		})
+	}
+	if err := replayReader.NewReplayWriter(io.Discard).WritePacket(-1, nil); err != replayReader.ErrInvalidTime {
+		t.Errorf("negative time: got %v, want ErrInvalidTime", err)
+	}
+}
+
+//Returns the first packet of a replay holding one packet with data.
+func packetOf(t *testing.T, data []byte, options ...replayReader.Option) *replayReader.Packet {
+	t.Helper()
+	replay := replaytest.NewReplay().Add(0, data).Replay(options...)
+	var p replayReader.Packet
+	if !replay.Next(&p) {
+		t.Fatal(replay.Error())
+	}
+	return &p
+}
+
+func TestReadVarInt(t *testing.T) {
+	exact := []replayReader.Option{replayReader.WithExactVarInts()}
+	tests := []struct {
+		data    []byte
+		options []replayReader.Option
+		want    int
+		len     int
+		err     error
+	}{
+		{[]byte{0x00}, nil, 0, 1, nil},
+		{[]byte{0x01}, nil, 1, 1, nil},
+		{[]byte{0x7F}, nil, 127, 1, nil},
+		{[]byte{0x80, 0x01}, nil, 128, 2, nil},
+		{[]byte{0xDD, 0xC7, 0x01}, nil, 25565, 3, nil},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x07}, nil, 2147483647, 5, nil},
+		//Without WithExactVarInts the fifth byte isn't cut to 32 bits
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}, nil, 4294967295, 5, nil},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}, exact, -1, 5, nil},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x08}, exact, -2147483648, 5, nil},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}, nil, 0, 0, replayReader.ErrVarIntTooBig},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}, exact, 0, 0, replayReader.ErrVarIntTooBig},
+		{[]byte{0x80}, nil, 0, 0, io.EOF},
+	}
+	for _, test := range tests {
+		got, n, err := packetOf(t, test.data, test.options...).ReadVarInt()
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("ReadVarInt(%x): got error %v, want %v", test.data, err, test.err)
+			}
+			continue
+		}
+		if err != nil || got != test.want || n != test.len {
+			t.Errorf("ReadVarInt(%x) = %d, %d, %v, want %d, %d", test.data, got, n, err, test.want, test.len)
+		}
+	}
+}
+
+func TestReadZigZag(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want int64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x01}, -1},
+		{[]byte{0x02}, 1},
+		{[]byte{0x03}, -2},
+		{[]byte{0xFE, 0xFF, 0xFF, 0xFF, 0x0F}, 2147483647},
+		{[]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}, -2147483648},
+	}
+	for _, test := range tests {
+		got, _, err := packetOf(t, test.data).ReadZigZagVarInt()
+		if err != nil || int64(got) != test.want {
+			t.Errorf("ReadZigZagVarInt(%x) = %d, %v, want %d", test.data, got, err, test.want)
+		}
+		long, _, err := packetOf(t, test.data).ReadZigZagVarLong()
+		if err != nil || long != test.want {
+			t.Errorf("ReadZigZagVarLong(%x) = %d, %v, want %d", test.data, long, err, test.want)
+		}
+	}
+}
+
+func TestReadString16(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		options []replayReader.Option
+		want    string
+		len     int
+		err     error
+	}{
+		{"empty", []byte{0, 0}, nil, "", 2, nil},
+		{"ascii", []byte{0, 2, 0, 'h', 0, 'i'}, nil, "hi", 6, nil},
+		{"little endian", []byte{2, 0, 'h', 0, 'i', 0}, []replayReader.Option{replayReader.WithLittleEndian()}, "hi", 6, nil},
+		{"surrogate pair", []byte{0, 2, 0xD8, 0x3D, 0xDE, 0x00}, nil, "\U0001F600", 6, nil},
+		{"unpaired surrogate", []byte{0, 1, 0xD8, 0x3D}, nil, "�", 4, nil},
+		{"cut short", []byte{0, 2, 0, 'h'}, nil, "", 0, io.ErrUnexpectedEOF},
+		{"over the limit", []byte{0, 2, 0, 'h', 0, 'i'}, []replayReader.Option{replayReader.WithLimits(replayReader.Limits{MaxStringLen: 1})}, "", 0, replayReader.ErrStringTooLong},
+		{"negative length", []byte{0xFF, 0xFF}, nil, "", 0, replayReader.ErrInvalidLength},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, n, err := packetOf(t, test.data, test.options...).ReadString16()
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Errorf("got error %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil || got != test.want || n != test.len {
+				t.Errorf("got %q, %d, %v, want %q, %d", got, n, err, test.want, test.len)
+			}
+		})
+	}
+}
+
+func TestReadRawString(t *testing.T) {
+	for _, options := range [][]replayReader.Option{nil, {replayReader.WithLazyPayloads()}} {
+		p := packetOf(t, []byte{1, 2, 'h', 'i', 7}, options...)
+		p.Skip(1)
+		got, n, err := p.ReadRawString()
+		if err != nil || !got.Equals("hi") || n != 3 || p.Remaining() != 1 {
+			t.Fatalf("got %q, %d, %v, %d remaining", got, n, err, p.Remaining())
+		}
+		//The string is not a copy, but the data of the packet
+		if &got[0] != &p.Bytes()[2] {
+			t.Errorf("string was copied")
+		}
+		_, _, err = p.ReadRawString()
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("reading past the end got %v", err)
+		}
+	}
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
@@ -0,0 +1,77 @@
+package replaytest
+
+import "github.com/bela333/replayReader"
+
+//Fixture describes a protocol version well enough to build a small replay recorded on it.
+//The IDs are the clientbound packet IDs of that version.
+type Fixture struct {
+	Protocol  int
+	Version   string
+	KeepAlive int
+	Chat      int
+}
+
+//Fixtures for some of the most common protocol versions.
+var (
+	Protocol47  = Fixture{Protocol: 47, Version: "1.8.9", KeepAlive: 0x00, Chat: 0x02}
+	Protocol340 = Fixture{Protocol: 340, Version: "1.12.2", KeepAlive: 0x1F, Chat: 0x0F}
+	Protocol578 = Fixture{Protocol: 578, Version: "1.15.2", KeepAlive: 0x21, Chat: 0x0F}
+	Protocol754 = Fixture{Protocol: 754, Version: "1.16.5", KeepAlive: 0x1F, Chat: 0x0E}
+	Protocol763 = Fixture{Protocol: 763, Version: "1.20.1", KeepAlive: 0x23, Chat: 0x64}
+)
+
+//All the Fixtures, oldest first.
+var Fixtures = []Fixture{Protocol47, Protocol340, Protocol578, Protocol754, Protocol763}
+
+//The UUID and name of the player in the fixture replays.
+var (
+	PlayerUUID = replayReader.UUID{0x06, 0x9a, 0x79, 0xf4, 0x44, 0xe9, 0x40, 0x26, 0xbf, 0xec, 0x97, 0x07, 0x0c, 0x8e, 0x6f, 0x7c}
+	PlayerName = "Steve"
+)
+
+//Returns the Login Success packet of the fixture's version.
+func (f Fixture) LoginSuccess() *PacketBuilder {
+	p := NewPacket(0x02)
+	if f.Protocol < 735 {
+		p.String(PlayerUUID.String())
+	} else {
+		p.UUID(PlayerUUID)
+	}
+	p.String(PlayerName)
+	if f.Protocol >= 759 {
+		p.VarInt(0)
+	}
+	return p
+}
+
+//Returns a chat packet of the fixture's version, showing the JSON chat component message in the chat box.
+func (f Fixture) ChatMessage(message string) *PacketBuilder {
+	p := NewPacket(f.Chat).String(message)
+	switch {
+	case f.Protocol >= 759:
+		p.Bool(false)
+	case f.Protocol >= 735:
+		p.UByte(0).UUID(replayReader.UUID{})
+	default:
+		p.UByte(0)
+	}
+	return p
+}
+
+//Returns a Keep Alive packet of the fixture's version.
+func (f Fixture) KeepAliveMessage(id int64) *PacketBuilder {
+	p := NewPacket(f.KeepAlive)
+	if f.Protocol < 340 {
+		return p.VarInt(int(id))
+	}
+	return p.Long(id)
+}
+
+//Returns a small replay of the fixture's version:
+//Login Success at 0ms, a chat message at 1000ms and a Keep Alive at 2000ms.
+func (f Fixture) Replay() *Builder {
+	return NewReplay().
+		AddPacket(0, f.LoginSuccess()).
+		AddPacket(1000, f.ChatMessage(`{"text":"Hello"}`)).
+		AddPacket(2000, f.KeepAliveMessage(1))
+}
@@ -0,0 +1,45 @@
+package replaytest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of replaytest.Golden")
+
+//Compares got to the golden file testdata/name.golden and fails t if they differ.
+//Running the tests with -update writes got to the golden file instead.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, got, 0644)
+		}
+		if err != nil {
+			t.Fatalf("updating golden file %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: got %d bytes that differ from the %d bytes of the golden file, starting at byte %d", path, len(got), len(want), firstDifference(got, want))
+	}
+}
+
+//Returns the position of the first byte where a and b differ.
+func firstDifference(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return min(len(a), len(b))
+}
@@ -0,0 +1,145 @@
+//Package replaytest builds synthetic replays for tests, so they don't need real recordings.
+package replaytest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/bela333/replayReader"
+)
+
+//Builder builds a replay file in memory, one packet at a time.
+type Builder struct {
+	buffer bytes.Buffer
+}
+
+//Returns an empty Builder.
+func NewReplay() *Builder {
+	return &Builder{}
+}
+
+//Adds a packet with the given Time and data to the replay.
+func (b *Builder) Add(time int, data []byte) *Builder {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(time))
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	b.buffer.Write(header)
+	b.buffer.Write(data)
+	return b
+}
+
+//Adds a packet built by a PacketBuilder to the replay.
+func (b *Builder) AddPacket(time int, p *PacketBuilder) *Builder {
+	return b.Add(time, p.Bytes())
+}
+
+//Adds raw bytes to the replay, without a header. Useful for building corrupted files.
+func (b *Builder) AddRaw(data []byte) *Builder {
+	b.buffer.Write(data)
+	return b
+}
+
+//Returns the replay file built so far.
+func (b *Builder) Bytes() []byte {
+	return bytes.Clone(b.buffer.Bytes())
+}
+
+//Returns a reader of the replay file built so far.
+func (b *Builder) Reader() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(b.Bytes()))
+}
+
+//Returns a Replay reading the replay file built so far.
+func (b *Builder) Replay(options ...replayReader.Option) *replayReader.Replay {
+	return replayReader.NewReplayFromBytes(b.Bytes(), options...)
+}
+
+//PacketBuilder builds the data of a single packet, starting with its packet ID.
+//It is a replayReader.PacketBuilder with shorter names.
+type PacketBuilder struct {
+	packet replayReader.PacketBuilder
+}
+
+//Returns a PacketBuilder whose packet starts with the VarInt id.
+func NewPacket(id int) *PacketBuilder {
+	p := &PacketBuilder{}
+	return p.VarInt(id)
+}
+
+//Returns the data of the packet.
+func (p *PacketBuilder) Bytes() []byte {
+	return p.packet.Bytes()
+}
+
+//Appends raw bytes to the packet.
+func (p *PacketBuilder) Raw(data []byte) *PacketBuilder {
+	p.packet.WriteuByteArray(data)
+	return p
+}
+
+//Appends an unsigned byte to the packet.
+func (p *PacketBuilder) UByte(v byte) *PacketBuilder {
+	p.packet.WriteuByte(v)
+	return p
+}
+
+//Appends a Boolean to the packet.
+func (p *PacketBuilder) Bool(v bool) *PacketBuilder {
+	p.packet.WriteBool(v)
+	return p
+}
+
+//Appends a short to the packet.
+func (p *PacketBuilder) Short(v int16) *PacketBuilder {
+	p.packet.WriteShort(v)
+	return p
+}
+
+//Appends an Integer to the packet.
+func (p *PacketBuilder) Int(v int32) *PacketBuilder {
+	p.packet.WriteInt(v)
+	return p
+}
+
+//Appends a Long to the packet.
+func (p *PacketBuilder) Long(v int64) *PacketBuilder {
+	p.packet.WriteLong(v)
+	return p
+}
+
+//Appends a Float to the packet.
+func (p *PacketBuilder) Float(v float32) *PacketBuilder {
+	p.packet.WriteFloat(v)
+	return p
+}
+
+//Appends a Double-precision Float to the packet.
+func (p *PacketBuilder) Double(v float64) *PacketBuilder {
+	p.packet.WriteDouble(v)
+	return p
+}
+
+//Appends a Variable-length Integer to the packet.
+func (p *PacketBuilder) VarInt(v int) *PacketBuilder {
+	p.packet.WriteVarInt(v)
+	return p
+}
+
+//Appends a Variable-length Long to the packet.
+func (p *PacketBuilder) VarLong(v int64) *PacketBuilder {
+	p.packet.WriteVarLong(v)
+	return p
+}
+
+//Appends a VarInt prefixed string to the packet.
+func (p *PacketBuilder) String(v string) *PacketBuilder {
+	p.packet.WriteString(v)
+	return p
+}
+
+//Appends a UUID, as two Longs, to the packet.
+func (p *PacketBuilder) UUID(v replayReader.UUID) *PacketBuilder {
+	p.packet.WriteUUID(v)
+	return p
+}
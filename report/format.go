@@ -0,0 +1,140 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+//Formats a time in milliseconds since the beginning of the recording as h:mm:ss.
+func timestamp(milliseconds int) string {
+	seconds := milliseconds / 1000
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+//Returns the title of the report.
+func (s *Session) title() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	if s.Server != "" {
+		return "Session on " + s.Server
+	}
+	return "Session report"
+}
+
+//Returns the rows of the overview table.
+func (s *Session) overview() [][2]string {
+	var rows [][2]string
+	if s.Server != "" {
+		rows = append(rows, [2]string{"Server", s.Server})
+	}
+	if s.Version != "" {
+		rows = append(rows, [2]string{"Version", s.Version})
+	}
+	if s.Protocol != 0 {
+		rows = append(rows, [2]string{"Protocol", fmt.Sprint(s.Protocol)})
+	}
+	if !s.Date.IsZero() {
+		rows = append(rows, [2]string{"Date", s.Date.Format(time.RFC1123)})
+	}
+	rows = append(rows,
+		[2]string{"Duration", timestamp(int(s.Duration.Milliseconds()))},
+		[2]string{"Packets", fmt.Sprint(s.Packets)},
+		[2]string{"Size", fmt.Sprintf("%d bytes", s.Bytes)},
+		[2]string{"Chat messages", fmt.Sprint(len(s.Chat))},
+		[2]string{"Deaths", fmt.Sprint(len(s.Deaths))},
+	)
+	return rows
+}
+
+//Escapes the characters that mean something in Markdown.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "|", `\|`, "#", `\#`)
+
+//Writes the report as Markdown to w.
+func (s *Session) Markdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n| | |\n|---|---|\n", markdownEscaper.Replace(s.title()))
+	for _, row := range s.overview() {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], markdownEscaper.Replace(row[1]))
+	}
+	if len(s.Markers) > 0 {
+		b.WriteString("\n## Markers\n\n")
+		for _, marker := range s.Markers {
+			fmt.Fprintf(&b, "- `%s` %s\n", timestamp(marker.Time), markdownEscaper.Replace(marker.Name))
+		}
+	}
+	if len(s.Players) > 0 {
+		b.WriteString("\n## Joins and leaves\n\n")
+		for _, player := range s.Players {
+			action := "left"
+			if player.Joined {
+				action = "joined"
+			}
+			fmt.Fprintf(&b, "- `%s` %s %s\n", timestamp(player.Time), markdownEscaper.Replace(player.Name), action)
+		}
+	}
+	if len(s.Deaths) > 0 {
+		b.WriteString("\n## Deaths\n\n")
+		for _, death := range s.Deaths {
+			fmt.Fprintf(&b, "- `%s` %s\n", timestamp(death.Time), markdownEscaper.Replace(death.Text))
+		}
+	}
+	if len(s.Chat) > 0 {
+		b.WriteString("\n## Chat\n\n")
+		for _, line := range s.Chat {
+			fmt.Fprintf(&b, "- `%s` %s\n", timestamp(line.Time), markdownEscaper.Replace(line.Text))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"timestamp": timestamp}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; }
+table { border-collapse: collapse; }
+td { border: 1px solid #ccc; padding: 0.2em 0.6em; }
+.time { font-family: monospace; color: #666; margin-right: 0.6em; }
+ul { list-style: none; padding: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+{{range .Overview}}<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{with .Session.Markers}}<h2>Markers</h2>
+<ul>
+{{range .}}<li><span class="time">{{timestamp .Time}}</span>{{.Name}}</li>
+{{end}}</ul>
+{{end}}{{with .Session.Players}}<h2>Joins and leaves</h2>
+<ul>
+{{range .}}<li><span class="time">{{timestamp .Time}}</span>{{.Name}} {{if .Joined}}joined{{else}}left{{end}}</li>
+{{end}}</ul>
+{{end}}{{with .Session.Deaths}}<h2>Deaths</h2>
+<ul>
+{{range .}}<li><span class="time">{{timestamp .Time}}</span>{{.Text}}</li>
+{{end}}</ul>
+{{end}}{{with .Session.Chat}}<h2>Chat</h2>
+<ul>
+{{range .}}<li><span class="time">{{timestamp .Time}}</span>{{.Text}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+//Writes the report as a standalone HTML page to w.
+func (s *Session) HTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, struct {
+		Title    string
+		Overview [][2]string
+		Session  *Session
+	}{s.title(), s.overview(), s})
+}
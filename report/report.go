@@ -0,0 +1,143 @@
+//Package report writes a shareable summary of a recorded session, as Markdown or HTML.
+package report
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/mcpr"
+)
+
+//Session is everything that goes into a report.
+//Collect fills in what can be read from the packets, the rest, like Server, Date and Markers,
+//comes from the metadata of the recording, see ReadArchive.
+type Session struct {
+	Title    string
+	Server   string
+	Version  string
+	Protocol int
+	Date     time.Time
+	Duration time.Duration
+	Packets  int
+	Bytes    int64
+
+	Chat    []ChatLine
+	Players []PlayerEvent
+	Deaths  []ChatLine
+	Markers []Marker
+}
+
+//ChatLine is a message shown in the chat at Time milliseconds.
+type ChatLine struct {
+	Time int
+	Text string
+}
+
+//PlayerEvent is a player joining or leaving the server at Time milliseconds.
+type PlayerEvent struct {
+	Time   int
+	Name   string
+	Joined bool
+}
+
+//Marker is a marker placed in the recording at Time milliseconds.
+type Marker struct {
+	Time int
+	Name string
+}
+
+//Reads every remaining packet of replay, recorded with protocol, and collects the chat, joins, leaves and deaths.
+//Joins, leaves and deaths are recognized by the translated messages the vanilla server sends for them.
+//Messages of players are shown the way the vanilla client shows them, <name> message.
+func Collect(replay *replayReader.Replay, protocol int) (*Session, error) {
+	session := &Session{Protocol: protocol}
+	bus := events.NewBus(protocol)
+	events.Subscribe(bus, func(event events.ChatEvent) {
+		if event.Position == 2 {
+			return
+		}
+		text, translate, with := event.Message, "", []string(nil)
+		if component, err := event.Component(); err == nil {
+			text, translate, with = component.PlainText(), component.Translate, component.Arguments()
+		}
+		session.Chat = append(session.Chat, ChatLine{Time: event.Time, Text: text})
+		switch {
+		case translate == "multiplayer.player.joined" && len(with) > 0:
+			session.Players = append(session.Players, PlayerEvent{Time: event.Time, Name: with[0], Joined: true})
+		case translate == "multiplayer.player.left" && len(with) > 0:
+			session.Players = append(session.Players, PlayerEvent{Time: event.Time, Name: with[0], Joined: false})
+		case strings.HasPrefix(translate, "death."):
+			session.Deaths = append(session.Deaths, ChatLine{Time: event.Time, Text: text})
+		}
+	})
+	events.Subscribe(bus, func(event events.PlayerChatEvent) {
+		text := plainText(event.Message)
+		if name := plainText(event.Name); name != "" {
+			text = "<" + name + "> " + text
+		}
+		session.Chat = append(session.Chat, ChatLine{Time: event.Time, Text: text})
+	})
+	err := bus.Run(replay)
+	cursor := replay.Cursor()
+	session.Packets = cursor.Packet
+	session.Bytes = cursor.Offset
+	session.Duration = time.Duration(cursor.Time) * time.Millisecond
+	return session, err
+}
+
+//Returns the plain text of a JSON chat component, or the message as it is if it isn't one.
+func plainText(message string) string {
+	component, err := replayReader.ParseChat(message)
+	if err != nil {
+		return message
+	}
+	return component.PlainText()
+}
+
+//Fills in Server, Version, Date and Markers from the metaData.json and markers.json of archive.
+//The custom server name is used when the player gave one.
+func (s *Session) ReadArchive(archive *mcpr.Archive) error {
+	metadata, err := archive.Metadata()
+	if err != nil {
+		return err
+	}
+	s.Server = metadata.ServerName
+	if metadata.CustomServerName != "" {
+		s.Server = metadata.CustomServerName
+	}
+	if metadata.MCVersion != "" {
+		s.Version = metadata.MCVersion
+	}
+	if metadata.Date > 0 {
+		s.Date = time.UnixMilli(metadata.Date)
+	}
+	markers, err := archive.Markers()
+	if err != nil {
+		return err
+	}
+	s.Markers = s.Markers[:0]
+	for _, marker := range markers {
+		s.Markers = append(s.Markers, Marker{Time: marker.Time, Name: marker.Name})
+	}
+	return nil
+}
+
+//Opens the .mcpr archive at path and returns the report of the session it recorded, with Collect and ReadArchive.
+func CollectArchive(path string) (*Session, error) {
+	archive, err := mcpr.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	protocol, err := archive.DetectProtocol()
+	if err != nil {
+		return nil, err
+	}
+	session, err := Collect(archive.Replay, protocol)
+	if err != nil {
+		return session, err
+	}
+	return session, session.ReadArchive(archive)
+}
@@ -0,0 +1,233 @@
+package replayReader
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+//Resync tells about a corrupt part of the replay file that Next skipped in resync mode.
+//Offset is the position in the file the skipped part starts at and Len is the number of bytes skipped.
+//Packet is the Index of the packet after the skipped part, and Err is the error the corrupt packet caused.
+type Resync struct {
+	Packet int
+	Offset int64
+	Len    int64
+	Err    error
+}
+
+//TimeError is returned in resync mode when the Time of a packet can't follow the Time of the packet before it,
+//because it goes back or jumps forward more than the RecoveryOptions allow. It matches ErrCorrupt.
+type TimeError struct {
+	Packet   int
+	Offset   int64
+	Time     int
+	Previous int
+}
+
+func (e *TimeError) Error() string {
+	return fmt.Sprintf("packet %d at offset %d: time %d can't follow time %d", e.Packet, e.Offset, e.Time, e.Previous)
+}
+
+func (e *TimeError) Unwrap() error {
+	return ErrCorrupt
+}
+
+//Makes Next skip corrupt packets instead of stopping at them. After a packet with a broken header or data,
+//Next searches the rest of the file for the next plausible header and goes on from there.
+//Plausible headers are found like ScanIndexRecover finds them with options, DefaultRecoveryOptions works for most recordings.
+//A MaxPacketLen of 0 is taken as the largest packet the protocol allows, since the packets are read ahead to check them.
+//In resync mode, a packet whose Time can't follow the one before it counts as corrupt too. Errors of the file itself still stop Next.
+//Every skipped part of the file is listed by r.Resyncs().
+//Useful for salvaging recordings that were cut short or damaged by a crash.
+func WithResync(options RecoveryOptions) Option {
+	return func(r *Replay) {
+		if options.MaxPacketLen <= 0 {
+			options.MaxPacketLen = maxUncompressedLen
+		}
+		r.config.resync = options
+		if _, ok := r.replayFile.(*resyncReader); !ok {
+			r.replayFile = &resyncReader{file: r.replayFile}
+		}
+	}
+}
+
+//Returns the parts of the file Next skipped in resync mode.
+func (r *Replay) Resyncs() []Resync {
+	return r.resyncs
+}
+
+//Handles a corrupt packet found by Next. Outside resync mode, or if err is an error of the file itself, it stops Next.
+//Otherwise it skips to the next plausible packet and returns it.
+func (r *Replay) corrupt(p *Packet, err error) bool {
+	reader, ok := r.replayFile.(*resyncReader)
+	var readErr *ReplayError
+	if !ok || errors.As(err, &readErr) {
+		r.error = err
+		return false
+	}
+	resync := Resync{Packet: r.packets, Offset: r.offset, Err: err}
+	if len(reader.packet) > 0 {
+		reader.unread(reader.packet[1:])
+		resync.Len = 1
+	}
+	headerSize := r.headerSize()
+	for {
+		candidate, readErr := reader.peek(headerSize)
+		if readErr != nil {
+			r.error = &ReplayError{Packet: r.packets, Offset: r.offset + resync.Len, Stage: "header", Err: readErr}
+			return false
+		}
+		if len(candidate) < headerSize {
+			//Nothing plausible is left
+			resync.Len += int64(len(candidate))
+			reader.discard(len(candidate))
+			r.resyncs = append(r.resyncs, resync)
+			r.offset += resync.Len
+			return false
+		}
+		if r.plausible(reader) {
+			break
+		}
+		reader.discard(1)
+		resync.Len++
+	}
+	r.resyncs = append(r.resyncs, resync)
+	r.offset += resync.Len
+	return r.Next(p)
+}
+
+//Reports whether the next bytes of reader start with packets that are plausible after the last one Next returned,
+//checked like ScanIndexRecover does with the RecoveryOptions of the Replay, reading ahead as far as needed.
+//With checksum framing, the checksum of the first packet has to match too.
+func (r *Replay) plausible(reader *resyncReader) bool {
+	headerSize := r.headerSize()
+	need := headerSize
+	var data []byte
+	for {
+		var err error
+		data, err = reader.peek(need)
+		if err != nil {
+			return false
+		}
+		ok, more := confirmPackets(data, r.previousTime(), r.config.resync, headerSize, len(data) < need)
+		if ok {
+			break
+		}
+		if more <= len(data) {
+			return false
+		}
+		need = more
+	}
+	if !r.config.checksumFraming {
+		return true
+	}
+	length := int(binary.BigEndian.Uint32(data[4:]))
+	return Checksum(data[headerSize:headerSize+length]) == binary.BigEndian.Uint32(data[8:])
+}
+
+//Returns the Time of the last packet Next returned, or -1 if there is none.
+func (r *Replay) previousTime() int {
+	if r.packets == 0 {
+		return -1
+	}
+	return r.lastTime
+}
+
+//Returns the error a header with time and length causes in resync mode, if it can't follow the last packet.
+func (r *Replay) checkHeader(time, length int) error {
+	options := r.config.resync
+	if !timeFollows(int64(time), int64(r.previousTime()), options, true) {
+		return &TimeError{Packet: r.packets, Offset: r.offset, Time: time, Previous: r.lastTime}
+	}
+	if options.MaxPacketLen > 0 && length > options.MaxPacketLen {
+		return &LimitError{Packet: r.packets, Offset: r.offset, Limit: "packet length", Len: length, Max: options.MaxPacketLen}
+	}
+	return nil
+}
+
+//resyncReader reads the replay file in resync mode. It keeps the bytes of the packet being read,
+//so they can be searched again for a header, and can look ahead in the file without consuming it.
+type resyncReader struct {
+	file     io.Reader
+	buffered []byte
+	//The bytes read since the start of the current packet
+	packet []byte
+}
+
+func (b *resyncReader) Read(p []byte) (int, error) {
+	var n int
+	var err error
+	if len(b.buffered) > 0 {
+		n = copy(p, b.buffered)
+		b.buffered = b.buffered[n:]
+	} else {
+		n, err = b.file.Read(p)
+	}
+	b.packet = append(b.packet, p[:n]...)
+	return n, err
+}
+
+//Starts a new packet.
+func (b *resyncReader) mark() {
+	b.packet = b.packet[:0]
+}
+
+//Returns the next n bytes without consuming them, or fewer if the file ends before.
+func (b *resyncReader) peek(n int) ([]byte, error) {
+	for len(b.buffered) < n {
+		chunk := make([]byte, max(n-len(b.buffered), 4096))
+		read, err := b.file.Read(chunk)
+		b.buffered = append(b.buffered, chunk[:read]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.buffered[:min(n, len(b.buffered))], nil
+}
+
+//Consumes n bytes that were looked at with peek.
+func (b *resyncReader) discard(n int) {
+	b.buffered = b.buffered[n:]
+}
+
+//Puts data back in front of the unread bytes.
+func (b *resyncReader) unread(data []byte) {
+	b.buffered = append(append([]byte(nil), data...), b.buffered...)
+}
+
+func (b *resyncReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := b.file.(io.Seeker)
+	if !ok {
+		return 0, ErrNotSeekable
+	}
+	if whence == io.SeekCurrent {
+		//The file is ahead of the reader by what is buffered
+		offset -= int64(len(b.buffered))
+	}
+	position, err := seeker.Seek(offset, whence)
+	if err == nil {
+		b.buffered = nil
+	}
+	return position, err
+}
+
+func (b *resyncReader) Close() error {
+	if closer, ok := b.file.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (b *resyncReader) SetReadDeadline(t time.Time) error {
+	if file, ok := b.file.(deadlineReader); ok {
+		return file.SetReadDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
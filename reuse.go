@@ -0,0 +1,47 @@
+package replayReader
+
+import (
+	"bytes"
+	"io"
+)
+
+//Makes Next read every packet into the same buffer and return the same Data reader for every packet,
+//instead of allocating new ones for each packet. The buffer only grows, to the length of the longest packet.
+//The Data of a packet is only valid until the next call of Next, so packets can't be kept for later,
+//like Stream and FindAll do. Values read from the Data, like strings and byte arrays, are copies and can be kept.
+func WithBufferReuse() Option {
+	return func(r *Replay) {
+		r.config.reuseBuffers = true
+	}
+}
+
+//Reads the data of a packet of length bytes from the file.
+func (r *Replay) readData(length int64) ([]byte, error) {
+	if !r.config.reuseBuffers {
+		return readPayload(r.replayFile, length)
+	}
+	if length > payloadChunk && length > int64(cap(r.buffer)) {
+		//Lengths that big are read in chunks, so a broken length doesn't allocate all of it at once
+		data, err := readPayload(r.replayFile, length)
+		r.buffer = data
+		return data, err
+	}
+	if int64(cap(r.buffer)) < length {
+		r.buffer = make([]byte, length)
+	}
+	data := r.buffer[:length]
+	n, err := io.ReadFull(r.replayFile, data)
+	return data[:n], err
+}
+
+//Returns a reader of data, to be used as the Data of a packet.
+func (r *Replay) dataReader(data []byte) *bytes.Reader {
+	if !r.config.reuseBuffers {
+		return bytes.NewReader(data)
+	}
+	if r.reader == nil {
+		r.reader = new(bytes.Reader)
+	}
+	r.reader.Reset(data)
+	return r.reader
+}
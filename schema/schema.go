@@ -0,0 +1,544 @@
+//Package schema decodes packets with a protocol description loaded at runtime, the protocol.json of
+//PrismarineJS/minecraft-data, so tools can support new versions of the game without being rebuilt.
+//Packets are decoded into trees: containers become map[string]any, arrays []any, missing options and switches nil,
+//and the rest the Go values of the matching Read methods, like int32 for VarInts and replayReader.UUID for UUIDs.
+//For typed structs generated from the same file, see cmd/protogen.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/item"
+	"github.com/bela333/replayReader/nbt"
+)
+
+//Returned by Decode for a packet ID the schema doesn't have.
+var ErrUnknownPacket = errors.New("schema: unknown packet ID")
+
+//Schema is the description of the packets of one state and direction of a protocol version.
+type Schema struct {
+	protocol int
+	types    map[string]any
+	names    map[int]string
+	packets  map[int]string
+}
+
+//Loads the packets of state, like play, sent in direction, toClient or toServer, from a protocol.json of minecraft-data.
+//protocol is the version the file describes, it tells how positions, NBT and slots are sent.
+func Load(data []byte, protocol int, state, direction string) (*Schema, error) {
+	var file map[string]json.RawMessage
+	err := json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{protocol: protocol, types: make(map[string]any), names: make(map[int]string), packets: make(map[int]string)}
+	var global map[string]any
+	if raw, ok := file["types"]; ok {
+		err = json.Unmarshal(raw, &global)
+		if err != nil {
+			return nil, fmt.Errorf("schema: types: %w", err)
+		}
+	}
+	var states map[string]struct {
+		Types map[string]any `json:"types"`
+	}
+	raw, ok := file[state]
+	if !ok {
+		return nil, fmt.Errorf("schema: no state %q", state)
+	}
+	err = json.Unmarshal(raw, &states)
+	if err != nil {
+		return nil, fmt.Errorf("schema: %s: %w", state, err)
+	}
+	types, ok := states[direction]
+	if !ok {
+		return nil, fmt.Errorf("schema: no direction %q in state %q", direction, state)
+	}
+	for name, definition := range global {
+		s.types[name] = definition
+	}
+	for name, definition := range types.Types {
+		s.types[name] = definition
+	}
+	err = s.loadPackets()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+//Reads the packet IDs and names from the mapper of the packet type, and the types from its switch.
+func (s *Schema) loadPackets() error {
+	fields, ok := containerFields(s.types["packet"])
+	if !ok || len(fields) != 2 {
+		return fmt.Errorf("schema: no packet container with a name and params")
+	}
+	mapper, ok1 := compound(fields[0]["type"], "mapper")
+	params, ok2 := compound(fields[1]["type"], "switch")
+	mappings, ok3 := mapper["mappings"].(map[string]any)
+	cases, ok4 := params["fields"].(map[string]any)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return fmt.Errorf("schema: packet name isn't a mapper or its params aren't a switch")
+	}
+	for idString, name := range mappings {
+		id, err := strconv.ParseInt(idString, 0, 32)
+		if err != nil {
+			return fmt.Errorf("schema: packet ID %q: %w", idString, err)
+		}
+		name, _ := name.(string)
+		typeName, ok := cases[name].(string)
+		if !ok {
+			continue
+		}
+		s.names[int(id)] = name
+		s.packets[int(id)] = typeName
+	}
+	return nil
+}
+
+//Returns the name of the packet with id, like spawn_entity. ok is false if the schema doesn't have it.
+func (s *Schema) Name(id int) (name string, ok bool) {
+	name, ok = s.names[id]
+	return name, ok
+}
+
+//Returns the IDs of the packets of the schema, in order.
+func (s *Schema) IDs() []int {
+	ids := make([]int, 0, len(s.names))
+	for id := range s.names {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+//Decodes the packet with id from the position of p after its ID, and returns its name and fields.
+//Errors tell the field that couldn't be read with a *replayReader.DecodeError.
+func (s *Schema) Decode(p *replayReader.Packet, id int) (name string, fields map[string]any, err error) {
+	typeName, ok := s.packets[id]
+	if !ok {
+		return "", nil, ErrUnknownPacket
+	}
+	d := &decoder{schema: s, p: p}
+	value, err := d.read(typeName, nil, "")
+	fields, _ = value.(map[string]any)
+	return s.names[id], fields, err
+}
+
+//scope holds the fields of the containers being read, so switches and counts can refer to them.
+type scope struct {
+	fields map[string]any
+	parent *scope
+}
+
+//Looks up a field by a path like ../action, relative to the scope.
+func (s *scope) lookup(path string) (any, bool) {
+	current := s
+	var value any
+	found := false
+	for _, segment := range strings.Split(path, "/") {
+		if segment == ".." {
+			if current == nil {
+				return nil, false
+			}
+			current = current.parent
+			continue
+		}
+		if found {
+			fields, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			value, found = fields[segment]
+		} else if current != nil {
+			value, found = current.fields[segment]
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return value, found
+}
+
+//void is the value of fields that aren't sent.
+type void struct{}
+
+type decoder struct {
+	schema *Schema
+	p      *replayReader.Packet
+}
+
+func (d *decoder) fail(path string, err error) error {
+	var decodeErr *replayReader.DecodeError
+	if errors.As(err, &decodeErr) {
+		return err
+	}
+	if path == "" {
+		path = "packet"
+	}
+	return &replayReader.DecodeError{Field: path, Err: err}
+}
+
+func (d *decoder) read(t any, s *scope, path string) (any, error) {
+	switch t := t.(type) {
+	case string:
+		value, err, ok := d.native(t)
+		if ok {
+			if err != nil {
+				return value, d.fail(path, err)
+			}
+			return value, nil
+		}
+		definition, defined := d.schema.types[t]
+		if !defined || definition == "native" {
+			return nil, d.fail(path, fmt.Errorf("unsupported type %s", t))
+		}
+		return d.read(definition, s, path)
+	case []any:
+		if len(t) != 2 {
+			break
+		}
+		kind, _ := t[0].(string)
+		return d.compound(kind, t[1], s, path)
+	}
+	return nil, d.fail(path, fmt.Errorf("invalid type %v", t))
+}
+
+//Reads a native type. ok is false if name isn't one.
+func (d *decoder) native(name string) (value any, err error, ok bool) {
+	p := d.p
+	switch name {
+	case "void":
+		return void{}, nil, true
+	case "bool":
+		value, err = p.ReadBool()
+	case "i8":
+		value, err = p.ReadByte()
+	case "u8":
+		value, err = p.ReaduByte()
+	case "i16":
+		value, err = p.ReadShort()
+	case "u16":
+		value, err = p.ReaduShort()
+	case "i32":
+		value, err = p.ReadInt()
+	case "u32":
+		var v int32
+		v, err = p.ReadInt()
+		value = uint32(v)
+	case "i64":
+		value, err = p.ReadLong()
+	case "u64":
+		var v int64
+		v, err = p.ReadLong()
+		value = uint64(v)
+	case "f32":
+		value, err = p.ReadFloat()
+	case "f64":
+		value, err = p.ReadDouble()
+	case "varint":
+		value, _, err = p.ReadVarInt32()
+	case "optvarint":
+		//0 means there is no value, others are sent one higher
+		var v int32
+		v, _, err = p.ReadVarInt32()
+		if v != 0 {
+			value = v - 1
+		}
+	case "varlong":
+		value, _, err = p.ReadVarLong64()
+	case "UUID":
+		value, err = p.ReadUUID()
+	case "position":
+		value, err = p.ReadPosition(d.schema.protocol)
+	case "restBuffer":
+		value, _, err = p.ReadRest()
+	case "cstring":
+		value, err = readCString(p)
+	case "nbt", "optionalNbt":
+		value, err = nbt.Read(p, d.schema.protocol)
+	case "anonymousNbt", "anonOptionalNbt":
+		value, err = nbt.Read(p, max(d.schema.protocol, nbt.ProtocolNamelessRoot))
+	case "slot":
+		//Only versions that don't describe slots as a container use the native slot
+		if _, described := d.schema.types["slot"].([]any); described {
+			return nil, nil, false
+		}
+		value, err = item.ReadSlot(p, d.schema.protocol)
+	default:
+		return nil, nil, false
+	}
+	return value, err, true
+}
+
+func (d *decoder) compound(kind string, args any, s *scope, path string) (any, error) {
+	switch kind {
+	case "container":
+		fields, ok := containerFields([]any{kind, args})
+		if !ok {
+			break
+		}
+		inner := &scope{fields: make(map[string]any, len(fields)), parent: s}
+		for _, field := range fields {
+			name, _ := field["name"].(string)
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			value, err := d.read(field["type"], inner, fieldPath)
+			if err != nil {
+				return inner.fields, err
+			}
+			if _, isVoid := value.(void); isVoid {
+				continue
+			}
+			if anonymous, _ := field["anon"].(bool); anonymous {
+				if values, ok := value.(map[string]any); ok {
+					for key, value := range values {
+						inner.fields[key] = value
+					}
+				}
+				continue
+			}
+			inner.fields[name] = value
+		}
+		return inner.fields, nil
+	case "option":
+		present, err := d.p.ReadBool()
+		if err != nil || !present {
+			return nil, d.failIf(path, err)
+		}
+		return d.read(args, s, path)
+	case "switch":
+		args, _ := args.(map[string]any)
+		compareTo, _ := args["compareTo"].(string)
+		value, _ := s.lookup(compareTo)
+		cases, _ := args["fields"].(map[string]any)
+		//Cases are keyed by the mapped names, numbers and true or false
+		if t, ok := cases[fmt.Sprint(value)]; ok {
+			return d.read(t, s, path)
+		}
+		if t, ok := args["default"]; ok {
+			return d.read(t, s, path)
+		}
+		return void{}, nil
+	case "mapper":
+		args, _ := args.(map[string]any)
+		value, err := d.read(args["type"], s, path)
+		if err != nil {
+			return value, err
+		}
+		mappings, _ := args["mappings"].(map[string]any)
+		return mapValue(value, mappings), nil
+	case "array":
+		args, _ := args.(map[string]any)
+		count, err := d.count(args, s, path)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, 0, count)
+		for i := 0; i < count; i++ {
+			value, err := d.read(args["type"], s, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return values, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case "pstring", "buffer":
+		args, _ := args.(map[string]any)
+		length, err := d.count(args, s, path)
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := d.p.ReaduByteArray(length)
+		if err != nil {
+			return nil, d.fail(path, err)
+		}
+		if kind == "pstring" {
+			return string(data), nil
+		}
+		return data, nil
+	case "bitfield":
+		return d.bitfield(args, path)
+	case "entityMetadataLoop":
+		args, _ := args.(map[string]any)
+		end, _ := args["endVal"].(float64)
+		var values []any
+		for i := 0; ; i++ {
+			next, err := d.p.ReaduByte()
+			if err != nil {
+				return values, d.fail(path, err)
+			}
+			if int(next) == int(end) {
+				return values, nil
+			}
+			_, err = d.p.Data.Seek(-1, io.SeekCurrent)
+			if err != nil {
+				return values, d.fail(path, err)
+			}
+			value, err := d.read(args["type"], s, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return values, err
+			}
+			values = append(values, value)
+		}
+	}
+	return nil, d.fail(path, fmt.Errorf("unsupported type %s", kind))
+}
+
+func (d *decoder) failIf(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return d.fail(path, err)
+}
+
+//Returns the number of elements of an array or bytes of a buffer: sent before it as countType,
+//fixed as count, or taken from the field named by count.
+func (d *decoder) count(args map[string]any, s *scope, path string) (int, error) {
+	if countType, ok := args["countType"]; ok {
+		value, err := d.read(countType, s, path)
+		if err != nil {
+			return 0, err
+		}
+		count, ok := toInt(value)
+		if !ok || count < 0 || count > d.p.Remaining() {
+			return 0, d.fail(path, fmt.Errorf("%w: count %v with %d bytes left", replayReader.ErrInvalidLength, value, d.p.Remaining()))
+		}
+		return count, nil
+	}
+	switch count := args["count"].(type) {
+	case float64:
+		return int(count), nil
+	case string:
+		value, _ := s.lookup(count)
+		if count, ok := toInt(value); ok && count >= 0 {
+			return count, nil
+		}
+	}
+	return 0, d.fail(path, fmt.Errorf("%w: no count", replayReader.ErrInvalidLength))
+}
+
+//Reads a bitfield, whose fields are packed from the most significant bit of a big-endian number.
+func (d *decoder) bitfield(args any, path string) (any, error) {
+	fields, _ := args.([]any)
+	bits := 0
+	for _, field := range fields {
+		field, _ := field.(map[string]any)
+		size, _ := field["size"].(float64)
+		bits += int(size)
+	}
+	if bits%8 != 0 || bits > 64 {
+		return nil, d.fail(path, fmt.Errorf("unsupported bitfield of %d bits", bits))
+	}
+	data, _, err := d.p.ReaduByteArray(bits / 8)
+	if err != nil {
+		return nil, d.fail(path, err)
+	}
+	var packed uint64
+	for _, b := range data {
+		packed = packed<<8 | uint64(b)
+	}
+	values := make(map[string]any, len(fields))
+	shift := bits
+	for _, field := range fields {
+		field, _ := field.(map[string]any)
+		name, _ := field["name"].(string)
+		size, _ := field["size"].(float64)
+		signed, _ := field["signed"].(bool)
+		shift -= int(size)
+		value := int64(packed>>shift) & (1<<int(size) - 1)
+		if signed && value&(1<<(int(size)-1)) != 0 {
+			value -= 1 << int(size)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+//Reads a string ended by a NUL byte.
+func readCString(p *replayReader.Packet) (string, error) {
+	var builder strings.Builder
+	for {
+		b, err := p.ReaduByte()
+		if err != nil || b == 0 {
+			return builder.String(), err
+		}
+		builder.WriteByte(b)
+	}
+}
+
+//Returns the fields of a container definition.
+func containerFields(definition any) ([]map[string]any, bool) {
+	list, ok := definition.([]any)
+	if !ok || len(list) != 2 || list[0] != "container" {
+		return nil, false
+	}
+	raw, ok := list[1].([]any)
+	if !ok {
+		return nil, false
+	}
+	fields := make([]map[string]any, 0, len(raw))
+	for _, field := range raw {
+		field, ok := field.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	return fields, true
+}
+
+//Returns the arguments of a definition like ["mapper", {...}] of kind.
+func compound(definition any, kind string) (map[string]any, bool) {
+	list, ok := definition.([]any)
+	if !ok || len(list) != 2 || list[0] != kind {
+		return nil, false
+	}
+	args, ok := list[1].(map[string]any)
+	return args, ok
+}
+
+//Returns the name value is mapped to, or value itself if it isn't mapped.
+func mapValue(value any, mappings map[string]any) any {
+	number, ok := toInt(value)
+	if !ok {
+		return value
+	}
+	for key, name := range mappings {
+		if id, err := strconv.ParseInt(key, 0, 64); err == nil && int(id) == number {
+			return name
+		}
+	}
+	return value
+}
+
+func toInt(value any) (int, bool) {
+	switch value := value.(type) {
+	case int8:
+		return int(value), true
+	case uint8:
+		return int(value), true
+	case int16:
+		return int(value), true
+	case uint16:
+		return int(value), true
+	case int32:
+		return int(value), true
+	case uint32:
+		return int(value), true
+	case int64:
+		return int(value), true
+	case uint64:
+		return int(value), true
+	}
+	return 0, false
+}
@@ -0,0 +1,273 @@
+//Package split cuts replays into smaller replays.
+package split
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/mcpr"
+)
+
+//Segment is a part of a replay spent in one dimension.
+//Name is the dimension and the number of the visit, like Nether-1. Visit counts from 1 for every dimension.
+//Start and End are the time of the first and last packet of the segment in the original replay.
+type Segment struct {
+	Name      string
+	Dimension string
+	Visit     int
+	Start     int
+	End       int
+	Packets   int
+}
+
+//Returns a short name of a dimension: Overworld, Nether and End for the vanilla ones, the path of its identifier otherwise.
+func dimensionName(dimension string) string {
+	switch dimension {
+	case "minecraft:overworld":
+		return "Overworld"
+	case "minecraft:the_nether":
+		return "Nether"
+	case "minecraft:the_end":
+		return "End"
+	case "":
+		return "Unknown"
+	}
+	_, name := replayReader.SplitIdentifier(dimension)
+	return name
+}
+
+type frame struct {
+	time int
+	data []byte
+}
+
+//Splits replay into one replay for every visit to a dimension, recorded with protocol.
+//create is called when a segment starts, and the packets of the segment are written to the writer it returns,
+//which is closed when the segment ends. Segment.End and Segment.Packets aren't known yet when create is called.
+//So every segment can be played on its own, the ones after the first start with the packets up to and including
+//the Join Game packet, and their times start at 0. The segments are returned once the replay was read.
+func ByDimension(replay *replayReader.Replay, protocol int, create func(segment Segment) (io.WriteCloser, error)) ([]Segment, error) {
+	dimensionIDs := events.IDs[events.DimensionEvent](protocol)
+	var segments []Segment
+	var prefix []frame
+	var output *replayReader.ReplayWriter
+	joined := false
+	visits := make(map[string]int)
+
+	end := func() error {
+		if output == nil {
+			return nil
+		}
+		err := output.Close()
+		output = nil
+		return err
+	}
+	start := func(dimension string, time int) error {
+		err := end()
+		if err != nil {
+			return err
+		}
+		visits[dimension]++
+		segment := Segment{Name: fmt.Sprintf("%s-%d", dimensionName(dimension), visits[dimension]), Dimension: dimension, Visit: visits[dimension], Start: time}
+		writer, err := create(segment)
+		if err != nil {
+			return err
+		}
+		output = replayReader.NewReplayWriter(writer)
+		segments = append(segments, segment)
+		if len(segments) > 1 {
+			for _, packet := range prefix {
+				err = output.WritePacket(0, packet.data)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	phase := events.PhaseTracker{Protocol: protocol}
+	var p replayReader.Packet
+	for replay.Next(&p) {
+		data := p.Bytes()
+		id, _, err := p.ReadVarInt()
+		if err == nil && phase.Play(id) && contains(dimensionIDs, id) {
+			context := events.Context{Header: events.Header{Time: p.Time, Packet: p.Index, ID: id}, Protocol: protocol}
+			event, ok, _ := events.Decode[events.DimensionEvent](&p, context)
+			current := ""
+			if len(segments) > 0 {
+				current = segments[len(segments)-1].Dimension
+			}
+			if ok && !joined {
+				joined = true
+				prefix = append(prefix, frame{p.Time, data})
+				err = start(event.Dimension, 0)
+				if err == nil {
+					//The packets before Join Game go to the first segment as well
+					for _, packet := range prefix {
+						err = output.WritePacket(packet.time, packet.data)
+						if err != nil {
+							break
+						}
+					}
+				}
+				if err != nil {
+					end()
+					return segments, err
+				}
+				segments[0].Packets = len(prefix)
+				segments[0].End = p.Time
+				continue
+			}
+			if ok && event.Dimension != current {
+				err = start(event.Dimension, p.Time)
+				if err != nil {
+					end()
+					return segments, err
+				}
+			}
+		}
+		if !joined {
+			prefix = append(prefix, frame{p.Time, data})
+			continue
+		}
+		segment := &segments[len(segments)-1]
+		time := p.Time
+		if len(segments) > 1 {
+			time -= segment.Start
+		}
+		err = output.WritePacket(time, data)
+		if err != nil {
+			end()
+			return segments, err
+		}
+		segment.Packets++
+		segment.End = p.Time
+	}
+	if err := replay.Error(); err != nil {
+		end()
+		return segments, err
+	}
+	if !joined && len(prefix) > 0 {
+		//The replay never joined a dimension, so it is one segment
+		err := start("", 0)
+		for _, packet := range prefix {
+			if err == nil {
+				err = output.WritePacket(packet.time, packet.data)
+			}
+		}
+		if err != nil {
+			end()
+			return segments, err
+		}
+		segments[0].Packets = len(prefix)
+		segments[0].End = prefix[len(prefix)-1].time
+	}
+	return segments, end()
+}
+
+func contains(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+//Splits the .mcpr archive at path by dimension, writing every segment next to it as name-Segment.mcpr,
+//like session-Nether-1.mcpr. Every segment gets the metadata of the original, with the duration of the segment.
+//It returns the segments and the paths they were written to.
+func Files(path string, protocol int) ([]Segment, []string, error) {
+	//Opened as an Archive, so seekable recordings are read too
+	archive, err := mcpr.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer archive.Close()
+	metadata, err := archive.Metadata()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	var paths []string
+	segments, err := ByDimension(archive.Replay, protocol, func(segment Segment) (io.WriteCloser, error) {
+		output := base + "-" + segment.Name + ".mcpr"
+		paths = append(paths, output)
+		return newSegmentWriter(output, *metadata, segment)
+	})
+	return segments, paths, err
+}
+
+//segmentWriter writes a segment to a new .mcpr archive. The metadata is written on Close, once the segment is complete.
+type segmentWriter struct {
+	file      *os.File
+	archive   *zip.Writer
+	recording io.Writer
+	metadata  mcpr.Metadata
+	last      int
+
+	header    [8]byte
+	headerLen int
+	skip      int
+}
+
+func newSegmentWriter(path string, metadata mcpr.Metadata, segment Segment) (*segmentWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	archive := zip.NewWriter(file)
+	recording, err := archive.Create(mcpr.RecordingEntry)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	metadata.Date += int64(segment.Start)
+	return &segmentWriter{file: file, archive: archive, recording: recording, metadata: metadata}, nil
+}
+
+//Writes to the recording, following the framing to find the time of the last packet.
+func (w *segmentWriter) Write(b []byte) (int, error) {
+	for rest := b; len(rest) > 0; {
+		if w.skip > 0 {
+			n := min(w.skip, len(rest))
+			w.skip -= n
+			rest = rest[n:]
+			continue
+		}
+		n := copy(w.header[w.headerLen:], rest)
+		w.headerLen += n
+		rest = rest[n:]
+		if w.headerLen == len(w.header) {
+			w.last = max(w.last, int(binary.BigEndian.Uint32(w.header[:])))
+			w.skip = int(binary.BigEndian.Uint32(w.header[4:]))
+			w.headerLen = 0
+		}
+	}
+	return w.recording.Write(b)
+}
+
+func (w *segmentWriter) Close() error {
+	w.metadata.Duration = w.last
+	entry, err := w.archive.Create(mcpr.MetadataEntry)
+	if err == nil {
+		err = json.NewEncoder(entry).Encode(&w.metadata)
+	}
+	if err == nil {
+		err = w.archive.Close()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
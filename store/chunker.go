@@ -0,0 +1,108 @@
+package store
+
+import (
+	"io"
+	"math/bits"
+)
+
+//ChunkOptions are the sizes the Chunker aims for. Chunks are never shorter than Min, except the last one,
+//and never longer than Max. Avg has to be a power of two.
+type ChunkOptions struct {
+	Min int
+	Avg int
+	Max int
+}
+
+//Chunk sizes that work well for replays, whose packets are small.
+var DefaultChunkOptions = ChunkOptions{Min: 2 << 10, Avg: 8 << 10, Max: 64 << 10}
+
+//gear is the table of the gear hash. It is generated from a fixed seed, since changing it would change every chunk boundary.
+var gear = func() (table [256]uint64) {
+	state := uint64(0x7265706c61792121)
+	for i := range table {
+		//splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return
+}()
+
+//Returns a mask of the highest n bits. The gear hash shifts left, so its highest bits depend on the most bytes.
+func highMask(n int) uint64 {
+	return ^uint64(0) << (64 - n)
+}
+
+//Chunker splits a stream into content-defined chunks with FastCDC.
+//The same data gives the same chunks wherever it is in the stream, so near-duplicate replays share most of their chunks.
+type Chunker struct {
+	reader  io.Reader
+	options ChunkOptions
+	small   uint64
+	large   uint64
+	buffer  []byte
+	start   int
+	end     int
+	eof     bool
+}
+
+//Returns a Chunker splitting r.
+func NewChunker(r io.Reader, options ChunkOptions) *Chunker {
+	avgBits := bits.Len(uint(options.Avg)) - 1
+	return &Chunker{
+		reader:  r,
+		options: options,
+		//Normalized chunking: a harder mask before Avg and an easier one after it keeps chunk sizes close to Avg
+		small:  highMask(avgBits + 2),
+		large:  highMask(avgBits - 2),
+		buffer: make([]byte, options.Max),
+	}
+}
+
+//Returns the length of the first chunk of data.
+func (c *Chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.options.Min {
+		return n
+	}
+	normal := min(c.options.Avg, n)
+	var fingerprint uint64
+	i := c.options.Min
+	for ; i < normal; i++ {
+		fingerprint = fingerprint<<1 + gear[data[i]]
+		if fingerprint&c.small == 0 {
+			return i
+		}
+	}
+	for ; i < n; i++ {
+		fingerprint = fingerprint<<1 + gear[data[i]]
+		if fingerprint&c.large == 0 {
+			return i
+		}
+	}
+	return n
+}
+
+//Returns the next chunk. It is only valid until the next call. At the end of the stream, it returns io.EOF.
+func (c *Chunker) Next() ([]byte, error) {
+	if !c.eof && c.end-c.start < c.options.Max {
+		c.end = copy(c.buffer, c.buffer[c.start:c.end])
+		c.start = 0
+		n, err := io.ReadFull(c.reader, c.buffer[c.end:])
+		c.end += n
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			c.eof = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+	n := c.cut(c.buffer[c.start:c.end])
+	chunk := c.buffer[c.start : c.start+n]
+	c.start += n
+	return chunk, nil
+}
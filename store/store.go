@@ -0,0 +1,287 @@
+//Package store is an experimental storage backend for large collections of replays.
+//It splits the entries of .mcpr archives into content-defined chunks stored by their SHA-256,
+//so replays that share data, like the same lobby or login sequence, only store it once.
+//Replays can be turned back into standard .mcpr archives with Materialize.
+//
+//A store is a directory with two subdirectories:
+//	chunks/ab/abcdef...	the chunks, named by the hex SHA-256 of their content
+//	replays/name.json	the Manifest of every replay
+package store
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Returned when a replay name can't be used as a file name.
+var ErrInvalidName = errors.New("invalid replay name")
+
+//Store is a content-addressed store in a directory.
+type Store struct {
+	Dir     string
+	Options ChunkOptions
+}
+
+//Manifest describes a stored replay. Every entry of the archive is listed in order with the chunks of its content.
+type Manifest struct {
+	Name    string  `json:"name"`
+	Entries []Entry `json:"entries"`
+}
+
+//Entry is an entry of a stored archive.
+type Entry struct {
+	Name     string    `json:"name"`
+	Modified time.Time `json:"modified"`
+	Size     int64     `json:"size"`
+	Chunks   []string  `json:"chunks"`
+}
+
+//Stats tells how much of a replay was already in the store.
+//Bytes and Chunks count the whole replay, NewBytes and NewChunks only what had to be written.
+type Stats struct {
+	Chunks    int
+	NewChunks int
+	Bytes     int64
+	NewBytes  int64
+}
+
+//Opens the store in dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	for _, sub := range []string{"chunks", "replays"} {
+		err := os.MkdirAll(filepath.Join(dir, sub), 0755)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Store{Dir: dir, Options: DefaultChunkOptions}, nil
+}
+
+func (s *Store) chunkPath(hash string) string {
+	return filepath.Join(s.Dir, "chunks", hash[:2], hash)
+}
+
+func (s *Store) manifestPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", ErrInvalidName
+	}
+	return filepath.Join(s.Dir, "replays", name+".json"), nil
+}
+
+//Writes data to path through a temporary file, so a crash never leaves a partial file behind.
+func writeAtomic(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+//Stores chunk if it isn't in the store yet and returns its hash.
+func (s *Store) putChunk(chunk []byte, stats *Stats) (string, error) {
+	sum := sha256.Sum256(chunk)
+	hash := hex.EncodeToString(sum[:])
+	stats.Chunks++
+	stats.Bytes += int64(len(chunk))
+	path := s.chunkPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", err
+	}
+	err = writeAtomic(path, chunk)
+	if err != nil {
+		return "", err
+	}
+	stats.NewChunks++
+	stats.NewBytes += int64(len(chunk))
+	return hash, nil
+}
+
+//Adds the .mcpr archive at path to the store as name, replacing any replay stored under that name.
+func (s *Store) Put(name string, path string) (Stats, error) {
+	var stats Stats
+	manifestPath, err := s.manifestPath(name)
+	if err != nil {
+		return stats, err
+	}
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return stats, err
+	}
+	defer archive.Close()
+	manifest := Manifest{Name: name}
+	for _, file := range archive.File {
+		entry := Entry{Name: file.Name, Modified: file.Modified, Chunks: []string{}}
+		content, err := file.Open()
+		if err != nil {
+			return stats, err
+		}
+		chunker := NewChunker(content, s.Options)
+		for {
+			chunk, err := chunker.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				content.Close()
+				return stats, err
+			}
+			hash, err := s.putChunk(chunk, &stats)
+			if err != nil {
+				content.Close()
+				return stats, err
+			}
+			entry.Chunks = append(entry.Chunks, hash)
+			entry.Size += int64(len(chunk))
+		}
+		content.Close()
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+	data, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return stats, err
+	}
+	return stats, writeAtomic(manifestPath, data)
+}
+
+//Returns the Manifest of the replay stored as name.
+func (s *Store) Manifest(name string) (*Manifest, error) {
+	path, err := s.manifestPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+//Returns the names of every stored replay, sorted.
+func (s *Store) List() ([]string, error) {
+	files, err := os.ReadDir(filepath.Join(s.Dir, "replays"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if name, ok := strings.CutSuffix(file.Name(), ".json"); ok && !file.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+//Writes the replay stored as name to dst as a standard .mcpr archive.
+//Every chunk is checked against its hash, a corrupted chunk fails with an error matching fs.ErrInvalid.
+func (s *Store) Materialize(name string, dst io.Writer) error {
+	manifest, err := s.Manifest(name)
+	if err != nil {
+		return err
+	}
+	archive := zip.NewWriter(dst)
+	for _, entry := range manifest.Entries {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: entry.Name, Modified: entry.Modified, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		for _, hash := range entry.Chunks {
+			chunk, err := s.readChunk(hash)
+			if err != nil {
+				return err
+			}
+			_, err = writer.Write(chunk)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return archive.Close()
+}
+
+//Reads the chunk with the given hash and checks its content.
+func (s *Store) readChunk(hash string) ([]byte, error) {
+	if len(hash) != sha256.Size*2 {
+		return nil, &fs.PathError{Op: "read chunk", Path: hash, Err: fs.ErrInvalid}
+	}
+	chunk, err := os.ReadFile(s.chunkPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(chunk)
+	if hex.EncodeToString(sum[:]) != hash {
+		return nil, &fs.PathError{Op: "read chunk", Path: s.chunkPath(hash), Err: fs.ErrInvalid}
+	}
+	return chunk, nil
+}
+
+//Deletes the replay stored as name. Its chunks stay in the store until Prune is called.
+func (s *Store) Delete(name string) error {
+	path, err := s.manifestPath(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+//Deletes every chunk that isn't used by any stored replay and returns the number of bytes freed.
+func (s *Store) Prune() (int64, error) {
+	names, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	used := map[string]bool{}
+	for _, name := range names {
+		manifest, err := s.Manifest(name)
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range manifest.Entries {
+			for _, hash := range entry.Chunks {
+				used[hash] = true
+			}
+		}
+	}
+	var freed int64
+	err = filepath.WalkDir(filepath.Join(s.Dir, "chunks"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || used[d.Name()] {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		err = os.Remove(path)
+		if err == nil {
+			freed += info.Size()
+		}
+		return err
+	})
+	return freed, err
+}
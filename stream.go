@@ -0,0 +1,35 @@
+package replayReader
+
+import "context"
+
+//Number of packets Stream reads ahead of the receiver.
+const streamBuffer = 64
+
+//Reads the remaining packets of the Replay on a new goroutine and sends them on the returned packet channel.
+//The goroutine reads at most a few packets ahead, so a slow receiver slows down reading instead of filling memory.
+//Packets are read with NextContext, so cancelling ctx also interrupts a read that is stuck.
+//When reading ends, the packet channel is closed. The error channel then gets the error of reading, or ctx.Err()
+//if ctx was cancelled, and is closed too; it is closed without a value at the end of the file.
+//The receiver should keep receiving until the packet channel is closed, or cancel ctx to stop the goroutine.
+//The Replay must not be used until then.
+func (r *Replay) Stream(ctx context.Context) (<-chan Packet, <-chan error) {
+	packets := make(chan Packet, streamBuffer)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(packets)
+		var p Packet
+		for r.NextContext(ctx, &p) {
+			select {
+			case packets <- p:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		if err := r.Error(); err != nil {
+			errs <- err
+		}
+	}()
+	return packets, errs
+}
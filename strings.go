@@ -0,0 +1,59 @@
+package replayReader
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//The longest string the protocol allows, in UTF-16 code units.
+const MaxStringLength = 32767
+
+//The longest string ReadString reads when the Replay has no MaxStringLen limit, in UTF-16 code units.
+//It is the longest string the vanilla client reads anywhere, the JSON chat components.
+const DefaultMaxStringLen = 262144
+
+//Returns the number of UTF-16 code units of the UTF-8 string b.
+func utf16Len(b []byte) int {
+	units := 0
+	for _, r := range string(b) {
+		units += utf16.RuneLen(r)
+	}
+	return units
+}
+
+//Checks that b is valid UTF-8 and no longer than MaxStringLength UTF-16 code units.
+func validateString(b []byte) error {
+	if !utf8.Valid(b) {
+		return &InvalidStringError{Len: len(b), Reason: "not valid UTF-8"}
+	}
+	if utf16Len(b) > MaxStringLength {
+		return &InvalidStringError{Len: len(b), TooLong: true, Reason: "more than 32767 UTF-16 code units"}
+	}
+	return nil
+}
+
+//Reads a string the way versions before 1.7 sent them: a Short number of UTF-16 code units, followed by the units
+//as big-endian UTF-16, or little-endian with WithLittleEndian. Legacy server list pings still use it. Len: len bytes
+//Unpaired surrogates are replaced with U+FFFD. The MaxStringLen limit and WithStrictStrings apply like for ReadString.
+func (p *Packet) ReadString16() (result string, len int, err error) {
+	units, err := p.ReadShort()
+	if err != nil {
+		return "", 0, err
+	}
+	config := p.getConfig()
+	if config.strictStrings && int(units) > MaxStringLength {
+		return "", 2, &InvalidStringError{Packet: p.Index, Offset: p.errorOffset(2), Len: int(units) * 2, TooLong: true, Reason: "too long"}
+	}
+	if max := p.maxStringLen(); max >= 0 && int(units) > max {
+		return "", 2, &LimitError{Packet: p.Index, Offset: p.errorOffset(2), Limit: "string length", Len: int(units), Max: max}
+	}
+	data, dataLen, err := p.readByteArray(int(units)*2, "string length")
+	if err != nil {
+		return "", 2 + dataLen, err
+	}
+	decoded := make([]uint16, units)
+	for i := range decoded {
+		decoded[i] = p.uint16(data[2*i:])
+	}
+	return string(utf16.Decode(decoded)), 2 + dataLen, nil
+}
@@ -0,0 +1,26 @@
+package replayReader
+
+import "time"
+
+//Length of a game tick in milliseconds, at the normal 20 ticks per second.
+const MillisPerTick = 50
+
+//Returns the game tick the replay time, in milliseconds, falls into.
+func TickAt(replayTime int) int {
+	return replayTime / MillisPerTick
+}
+
+//Returns the replay time, in milliseconds, at which tick starts.
+func TickTime(tick int) int {
+	return tick * MillisPerTick
+}
+
+//Returns the replay time, in milliseconds, as a time.Duration.
+func ReplayDuration(replayTime int) time.Duration {
+	return time.Duration(replayTime) * time.Millisecond
+}
+
+//Returns the replay time, in milliseconds, a time.Duration since the beginning of the replay stands for.
+func ReplayTime(d time.Duration) int {
+	return int(d / time.Millisecond)
+}
@@ -0,0 +1,133 @@
+package replayReader
+
+import (
+	"io"
+)
+
+//TimestampIssueKind tells what is wrong with the Time of a packet.
+type TimestampIssueKind int
+
+const (
+	//The packet's Time is lower than the one of the packet before it.
+	TimeRegression TimestampIssueKind = iota
+	//The packet's Time is more than the allowed jump after the one of the packet before it.
+	TimeJump
+)
+
+func (k TimestampIssueKind) String() string {
+	switch k {
+	case TimeRegression:
+		return "time regression"
+	case TimeJump:
+		return "time jump"
+	}
+	return "unknown timestamp issue"
+}
+
+//TimestampIssue is a packet whose Time doesn't follow the Time of the packet before it.
+//Packet is the number of the packet in the replay, starting at 0.
+type TimestampIssue struct {
+	Kind     TimestampIssueKind
+	Packet   int
+	Offset   int64
+	Time     int
+	Previous int
+}
+
+//Returns every packet in the Index whose Time goes backwards, or forwards by more than maxJump milliseconds.
+//If maxJump is 0, jumps aren't reported.
+func (index Index) ValidateTimestamps(maxJump int) []TimestampIssue {
+	var issues []TimestampIssue
+	for i := 1; i < len(index); i++ {
+		previous := index[i-1].Time
+		entry := index[i]
+		switch {
+		case entry.Time < previous:
+			issues = append(issues, TimestampIssue{Kind: TimeRegression, Packet: i, Offset: entry.Offset, Time: entry.Time, Previous: previous})
+		case maxJump > 0 && entry.Time-previous > maxJump:
+			issues = append(issues, TimestampIssue{Kind: TimeJump, Packet: i, Offset: entry.Offset, Time: entry.Time, Previous: previous})
+		}
+	}
+	return issues
+}
+
+//Makes times monotonic and returns the number of changed times.
+//Times that go backwards are clamped to the time before them.
+//A single time that jumps more than maxJump ahead while the next one doesn't is a spike,
+//so it is replaced by the time halfway between its neighbours.
+//Jumps that the times after them agree with are real gaps in the recording and are kept.
+//If maxJump is 0, spikes aren't repaired.
+func RepairTimestamps(times []int, maxJump int) int {
+	changed := 0
+	for i := 1; i < len(times); i++ {
+		previous := times[i-1]
+		var next *int
+		if i+1 < len(times) {
+			next = &times[i+1]
+		}
+		repaired := repairTime(previous, times[i], next, maxJump)
+		if repaired != times[i] {
+			times[i] = repaired
+			changed++
+		}
+	}
+	return changed
+}
+
+//Returns the repaired value of time, which comes after previous and before *next. next is nil for the last time.
+func repairTime(previous int, time int, next *int, maxJump int) int {
+	if time < previous {
+		return previous
+	}
+	if maxJump > 0 && time-previous > maxJump && next != nil && *next >= previous && *next-previous <= maxJump {
+		return previous + (*next-previous)/2
+	}
+	return time
+}
+
+//Copies the replay file in src to dst with the timestamps repaired as RepairTimestamps does.
+//It returns the number of packets whose Time was changed.
+func RepairReplayTimestamps(dst io.Writer, src io.Reader, maxJump int) (int, error) {
+	replay := NewReplayFromReader(src)
+	output := NewReplayWriter(dst)
+	changed := 0
+
+	var pending, lookahead Packet
+	if !replay.Next(&pending) {
+		if err := replay.Error(); err != nil {
+			return 0, err
+		}
+		return 0, output.Flush()
+	}
+	previous := pending.Time
+	write := func(p *Packet, time int) error {
+		return output.WritePacket(time, p.Bytes())
+	}
+	err := write(&pending, pending.Time)
+	if err != nil {
+		return changed, err
+	}
+
+	hasPending := replay.Next(&pending)
+	for hasPending {
+		hasLookahead := replay.Next(&lookahead)
+		var next *int
+		if hasLookahead {
+			next = &lookahead.Time
+		}
+		time := repairTime(previous, pending.Time, next, maxJump)
+		if time != pending.Time {
+			changed++
+		}
+		err = write(&pending, time)
+		if err != nil {
+			return changed, err
+		}
+		previous = time
+		pending, hasPending = lookahead, hasLookahead
+	}
+	if err := replay.Error(); err != nil {
+		return changed, err
+	}
+	return changed, output.Flush()
+}
@@ -0,0 +1,98 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader/events"
+)
+
+//BorderState is the world border from Time until the next state. While it moves, it goes from OldDiameter at LerpStart
+//to Diameter at LerpEnd, a border that doesn't move has the same diameters.
+//WarningTime is in seconds and WarningBlocks in blocks.
+type BorderState struct {
+	Time          int
+	X, Z          float64
+	OldDiameter   float64
+	Diameter      float64
+	LerpStart     int
+	LerpEnd       int
+	WarningTime   int
+	WarningBlocks int
+}
+
+//Returns the diameter of the border at time, which has to be in the state.
+func (s BorderState) DiameterAt(time int) float64 {
+	if time >= s.LerpEnd {
+		return s.Diameter
+	}
+	if time <= s.LerpStart {
+		return s.OldDiameter
+	}
+	progress := float64(time-s.LerpStart) / float64(s.LerpEnd-s.LerpStart)
+	return s.OldDiameter + (s.Diameter-s.OldDiameter)*progress
+}
+
+//BorderTracker keeps how the world border changed over the replay. States are ordered by Time.
+type BorderTracker struct {
+	States []BorderState
+}
+
+//Returns an empty BorderTracker.
+func NewBorderTracker() *BorderTracker {
+	return &BorderTracker{}
+}
+
+func (t *BorderTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.WorldBorderEvent) {
+		var state BorderState
+		if n := len(t.States); n > 0 {
+			state = t.States[n-1]
+		}
+		state.Time = event.Time
+		switch event.Action {
+		case events.BorderSize:
+			state.OldDiameter, state.Diameter = event.Diameter, event.Diameter
+			state.LerpStart, state.LerpEnd = event.Time, event.Time
+		case events.BorderLerp:
+			state.OldDiameter, state.Diameter = event.OldDiameter, event.Diameter
+			state.LerpStart, state.LerpEnd = event.Time, event.Time+int(event.Speed)
+		case events.BorderCenter:
+			state.X, state.Z = event.X, event.Z
+		case events.BorderInitialize:
+			state.X, state.Z = event.X, event.Z
+			state.OldDiameter, state.Diameter = event.OldDiameter, event.Diameter
+			state.LerpStart, state.LerpEnd = event.Time, event.Time+int(event.Speed)
+			state.WarningTime, state.WarningBlocks = event.WarningTime, event.WarningBlocks
+		case events.BorderWarningTime:
+			state.WarningTime = event.WarningTime
+		case events.BorderWarningBlocks:
+			state.WarningBlocks = event.WarningBlocks
+		}
+		//Packets at the same time only leave the last state
+		if n := len(t.States); n > 0 && t.States[n-1].Time == event.Time {
+			t.States[n-1] = state
+			return
+		}
+		t.States = append(t.States, state)
+	})
+}
+
+//Returns the world border at time, or false if the server hadn't sent it yet.
+func (t *BorderTracker) At(time int) (BorderState, bool) {
+	i := sort.Search(len(t.States), func(i int) bool {
+		return t.States[i].Time > time
+	})
+	if i == 0 {
+		return BorderState{}, false
+	}
+	return t.States[i-1], true
+}
+
+//Returns the diameter of the world border at time, or false if the server hadn't sent it yet.
+func (t *BorderTracker) DiameterAt(time int) (float64, bool) {
+	state, ok := t.At(time)
+	if !ok {
+		return 0, false
+	}
+	return state.DiameterAt(time), true
+}
@@ -0,0 +1,126 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//BossBarState is how a boss bar looked from Time until the next state. Title is a JSON chat component,
+//the other fields are the ones of events.BossBarEvent.
+type BossBarState struct {
+	Time     int
+	UUID     replayReader.UUID
+	Title    string
+	Health   float64
+	Color    int
+	Division int
+	Flags    byte
+}
+
+//BossBarTrack is a boss bar shown from Shown until Hidden. Hidden is -1 if it was still shown at the end of the replay.
+//States are ordered by Time, the first one is from the packet that added the bar.
+type BossBarTrack struct {
+	UUID   replayReader.UUID
+	Shown  int
+	Hidden int
+	States []BossBarState
+}
+
+//Returns how the boss bar looked at time, or false if it wasn't shown then.
+func (t *BossBarTrack) At(time int) (BossBarState, bool) {
+	if time < t.Shown || (t.Hidden >= 0 && time >= t.Hidden) {
+		return BossBarState{}, false
+	}
+	i := sort.Search(len(t.States), func(i int) bool {
+		return t.States[i].Time > time
+	})
+	return t.States[i-1], true
+}
+
+//Returns how the health of the boss bar changed, for exporting.
+func (t *BossBarTrack) Health() Series {
+	series := Series{Name: "boss bar health"}
+	for _, state := range t.States {
+		series.add(state.Time, state.Health)
+	}
+	return series
+}
+
+//BossBarTracker keeps the boss bars of the replay and how they changed over time.
+type BossBarTracker struct {
+	Bars []*BossBarTrack
+	//The track of every bar that is shown
+	shown map[replayReader.UUID]*BossBarTrack
+}
+
+//Returns an empty BossBarTracker.
+func NewBossBarTracker() *BossBarTracker {
+	return &BossBarTracker{shown: make(map[replayReader.UUID]*BossBarTrack)}
+}
+
+func (t *BossBarTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.BossBarEvent) {
+		if event.Action == events.BossBarAdd {
+			t.hide(event.UUID, event.Time)
+			track := &BossBarTrack{UUID: event.UUID, Shown: event.Time, Hidden: -1}
+			track.States = []BossBarState{{
+				Time:     event.Time,
+				UUID:     event.UUID,
+				Title:    event.Title,
+				Health:   event.Health,
+				Color:    event.Color,
+				Division: event.Division,
+				Flags:    event.Flags,
+			}}
+			t.shown[event.UUID] = track
+			t.Bars = append(t.Bars, track)
+			return
+		}
+		if event.Action == events.BossBarRemove {
+			t.hide(event.UUID, event.Time)
+			return
+		}
+		track, ok := t.shown[event.UUID]
+		if !ok {
+			return
+		}
+		state := track.States[len(track.States)-1]
+		switch event.Action {
+		case events.BossBarHealth:
+			state.Health = event.Health
+		case events.BossBarTitle:
+			state.Title = event.Title
+		case events.BossBarStyle:
+			state.Color, state.Division = event.Color, event.Division
+		case events.BossBarFlags:
+			state.Flags = event.Flags
+		}
+		//Packets at the same time only leave the last state
+		if state.Time == event.Time {
+			track.States[len(track.States)-1] = state
+			return
+		}
+		state.Time = event.Time
+		track.States = append(track.States, state)
+	})
+}
+
+func (t *BossBarTracker) hide(uuid replayReader.UUID, time int) {
+	if track, ok := t.shown[uuid]; ok {
+		track.Hidden = time
+		delete(t.shown, uuid)
+	}
+}
+
+//Returns the boss bars that were shown at time, from the top of the screen down.
+func (t *BossBarTracker) At(time int) []BossBarState {
+	var bars []BossBarState
+	for _, track := range t.Bars {
+		if state, ok := track.At(time); ok {
+			bars = append(bars, state)
+		}
+	}
+	return bars
+}
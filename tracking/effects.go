@@ -0,0 +1,92 @@
+package tracking
+
+import "github.com/bela333/replayReader/events"
+
+//EffectSpan is a potion effect an entity had from Start to End, in replay milliseconds.
+//End is -1 while the effect wasn't removed, replaced or lost with its entity.
+//Expires is when the effect runs out by itself, from its duration, or -1 if it doesn't.
+type EffectSpan struct {
+	Effect    int
+	Amplifier int
+	Duration  int
+	Start     int
+	End       int
+	Expires   int
+}
+
+//Reports whether the effect was active at time.
+func (s EffectSpan) ActiveAt(time int) bool {
+	return time >= s.Start && (s.End < 0 || time < s.End) && (s.Expires < 0 || time < s.Expires)
+}
+
+//Names of the effect IDs of the protocol until 1.20.1.
+var effectNames = map[int]string{
+	1: "speed", 2: "slowness", 3: "haste", 4: "mining_fatigue", 5: "strength", 6: "instant_health", 7: "instant_damage",
+	8: "jump_boost", 9: "nausea", 10: "regeneration", 11: "resistance", 12: "fire_resistance", 13: "water_breathing",
+	14: "invisibility", 15: "blindness", 16: "night_vision", 17: "hunger", 18: "weakness", 19: "poison", 20: "wither",
+	21: "health_boost", 22: "absorption", 23: "saturation", 24: "glowing", 25: "levitation", 26: "luck", 27: "unluck",
+	28: "slow_falling", 29: "conduit_power", 30: "dolphins_grace", 31: "bad_omen", 32: "hero_of_the_village", 33: "darkness",
+}
+
+//Returns the name of an effect ID, like speed, or an empty string if it is unknown.
+func EffectName(effect int) string {
+	return effectNames[effect]
+}
+
+//EffectTracker keeps the timeline of the potion effects of every entity.
+type EffectTracker struct {
+	Effects map[int][]EffectSpan
+}
+
+//Returns an empty EffectTracker.
+func NewEffectTracker() *EffectTracker {
+	return &EffectTracker{Effects: make(map[int][]EffectSpan)}
+}
+
+func (t *EffectTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.EntityEffectEvent) {
+		//A new effect of the same kind replaces the old one
+		t.end(event.EntityID, event.Effect, event.Time)
+		expires := -1
+		if event.Duration >= 0 && event.Duration < 32767 {
+			expires = event.Time + event.Duration*50
+		}
+		t.Effects[event.EntityID] = append(t.Effects[event.EntityID], EffectSpan{
+			Effect:    event.Effect,
+			Amplifier: event.Amplifier,
+			Duration:  event.Duration,
+			Start:     event.Time,
+			End:       -1,
+			Expires:   expires,
+		})
+	})
+	events.Subscribe(bus, func(event events.RemoveEffectEvent) {
+		t.end(event.EntityID, event.Effect, event.Time)
+	})
+	events.Subscribe(bus, func(event events.EntityDestroyEvent) {
+		for _, id := range event.EntityIDs {
+			t.end(id, -1, event.Time)
+		}
+	})
+}
+
+//Ends the ongoing spans of effect on entityID at time, or every ongoing span if effect is -1.
+func (t *EffectTracker) end(entityID int, effect int, time int) {
+	spans := t.Effects[entityID]
+	for i := range spans {
+		if spans[i].End < 0 && (effect < 0 || spans[i].Effect == effect) {
+			spans[i].End = time
+		}
+	}
+}
+
+//Returns the effects entityID had at time.
+func (t *EffectTracker) ActiveAt(entityID int, time int) []EffectSpan {
+	var active []EffectSpan
+	for _, span := range t.Effects[entityID] {
+		if span.ActiveAt(time) {
+			active = append(active, span)
+		}
+	}
+	return active
+}
@@ -0,0 +1,28 @@
+package tracking
+
+import "github.com/bela333/replayReader/events"
+
+//ExperienceTracker keeps the experience curve of the recording player from Set Experience packets.
+type ExperienceTracker struct {
+	Level Series
+	Total Series
+	Bar   Series
+}
+
+//Returns an empty ExperienceTracker.
+func NewExperienceTracker() *ExperienceTracker {
+	return &ExperienceTracker{Level: Series{Name: "level"}, Total: Series{Name: "experience"}, Bar: Series{Name: "experience bar"}}
+}
+
+func (t *ExperienceTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.ExperienceEvent) {
+		t.Level.add(event.Time, float64(event.Level))
+		t.Total.add(event.Time, float64(event.Total))
+		t.Bar.add(event.Time, event.Bar)
+	})
+}
+
+//Returns the level, total experience and bar series, for exporting.
+func (t *ExperienceTracker) Series() []Series {
+	return []Series{t.Level, t.Total, t.Bar}
+}
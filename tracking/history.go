@@ -0,0 +1,198 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/metadata"
+)
+
+//MetadataChange is a metadata entry an entity got at Time.
+type MetadataChange struct {
+	Time  int
+	Entry metadata.Entry
+}
+
+//EntityTrack is the life of an entity, from the packet that spawned it to the one that destroyed it,
+//or to the player joining or respawning, which leaves the entities of the old world behind.
+//Despawn is -1 if it was still in the world at the end of the replay.
+//Poses are where the entity was, in the dimension of the recording player. Metadata is ordered by Time.
+type EntityTrack struct {
+	ID       int
+	UUID     replayReader.UUID
+	Kind     events.EntityKind
+	Type     int
+	Spawn    int
+	Despawn  int
+	Poses    Timeline
+	Metadata []MetadataChange
+}
+
+//Reports whether the entity was in the world at time.
+func (t *EntityTrack) AliveAt(time int) bool {
+	return time >= t.Spawn && (t.Despawn < 0 || time < t.Despawn)
+}
+
+//Returns the state of the entity at time, or false if it wasn't in the world then.
+//The Vehicle of the returned Entity is -1, rides are kept by a MountTracker.
+func (t *EntityTrack) At(time int) (Entity, bool) {
+	if !t.AliveAt(time) {
+		return Entity{}, false
+	}
+	entity := Entity{ID: t.ID, UUID: t.UUID, Kind: t.Kind, Type: t.Type, SpawnTime: t.Spawn, Vehicle: -1}
+	if pose, ok := t.Poses.At(time); ok {
+		entity.X, entity.Y, entity.Z = pose.X, pose.Y, pose.Z
+		entity.Yaw, entity.Pitch = pose.Yaw, pose.Pitch
+	}
+	entity.Metadata = t.MetadataAt(time)
+	return entity, true
+}
+
+//Returns the last value of every metadata index the entity got until time, by index.
+func (t *EntityTrack) MetadataAt(time int) map[int]metadata.Entry {
+	entries := make(map[int]metadata.Entry)
+	for _, change := range t.Metadata {
+		if change.Time > time {
+			break
+		}
+		entries[change.Entry.Index] = change.Entry
+	}
+	return entries
+}
+
+//EntityHistory keeps the whole life of every entity of the replay, so their state can be asked for at any time.
+//An entity ID can be used again after its entity was destroyed, so every ID has a list of Tracks, ordered by Spawn.
+type EntityHistory struct {
+	Tracks    map[int][]*EntityTrack
+	dimension string
+	//The track of every entity that is in the world
+	alive map[int]*EntityTrack
+}
+
+//Returns an empty EntityHistory.
+func NewEntityHistory() *EntityHistory {
+	return &EntityHistory{Tracks: make(map[int][]*EntityTrack), alive: make(map[int]*EntityTrack)}
+}
+
+func (h *EntityHistory) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.DimensionEvent) {
+		//The client starts a new world when it joins or respawns, without the entities of the old one
+		for id := range h.alive {
+			h.despawn(id, event.Time)
+		}
+		h.dimension = event.Dimension
+	})
+	events.Subscribe(bus, func(event events.EntitySpawnEvent) {
+		//The same ID spawned again replaces the entity
+		h.despawn(event.EntityID, event.Time)
+		track := &EntityTrack{
+			ID:      event.EntityID,
+			UUID:    event.UUID,
+			Kind:    event.Kind,
+			Type:    event.Type,
+			Spawn:   event.Time,
+			Despawn: -1,
+		}
+		track.Poses = Timeline{{Time: event.Time, Dimension: h.dimension, X: event.X, Y: event.Y, Z: event.Z, Yaw: event.Yaw, Pitch: event.Pitch}}
+		h.alive[event.EntityID] = track
+		h.Tracks[event.EntityID] = append(h.Tracks[event.EntityID], track)
+	})
+	events.Subscribe(bus, func(event events.EntityMoveEvent) {
+		track, ok := h.alive[event.EntityID]
+		if !ok {
+			return
+		}
+		pose := track.Poses[len(track.Poses)-1]
+		if event.Moved {
+			pose.X, pose.Y, pose.Z = pose.X+event.DX, pose.Y+event.DY, pose.Z+event.DZ
+		}
+		if event.Rotated {
+			pose.Yaw, pose.Pitch = event.Yaw, event.Pitch
+		}
+		track.record(pose, event.Time)
+	})
+	events.Subscribe(bus, func(event events.EntityTeleportEvent) {
+		track, ok := h.alive[event.EntityID]
+		if !ok {
+			return
+		}
+		pose := Pose{Dimension: h.dimension, X: event.X, Y: event.Y, Z: event.Z, Yaw: event.Yaw, Pitch: event.Pitch}
+		track.record(pose, event.Time)
+	})
+	events.Subscribe(bus, func(event events.EntityMetadataEvent) {
+		track, ok := h.alive[event.EntityID]
+		if !ok {
+			return
+		}
+		for _, entry := range event.Entries {
+			track.Metadata = append(track.Metadata, MetadataChange{Time: event.Time, Entry: entry})
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityDestroyEvent) {
+		for _, id := range event.EntityIDs {
+			h.despawn(id, event.Time)
+		}
+	})
+}
+
+func (h *EntityHistory) despawn(id int, time int) {
+	if track, ok := h.alive[id]; ok {
+		track.Despawn = time
+		delete(h.alive, id)
+	}
+}
+
+func (t *EntityTrack) record(pose Pose, time int) {
+	pose.Time = time
+	//Packets at the same time only leave the last pose
+	if n := len(t.Poses); n > 0 && t.Poses[n-1].Time == time {
+		t.Poses[n-1] = pose
+		return
+	}
+	t.Poses = append(t.Poses, pose)
+}
+
+//Returns the track of the entity with id that was in the world at time, or false if there was none.
+func (h *EntityHistory) Track(id int, time int) (*EntityTrack, bool) {
+	for _, track := range h.Tracks[id] {
+		if track.AliveAt(time) {
+			return track, true
+		}
+	}
+	return nil, false
+}
+
+//Returns the state of the entity with id at time, like where it was, or false if it wasn't in the world then.
+func (h *EntityHistory) At(id int, time int) (Entity, bool) {
+	track, ok := h.Track(id, time)
+	if !ok {
+		return Entity{}, false
+	}
+	return track.At(time)
+}
+
+//Returns every entity that was in the world at time, ordered by ID.
+func (h *EntityHistory) AliveAt(time int) []Entity {
+	var entities []Entity
+	for _, tracks := range h.Tracks {
+		for _, track := range tracks {
+			if entity, ok := track.At(time); ok {
+				entities = append(entities, entity)
+			}
+		}
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].ID < entities[j].ID
+	})
+	return entities
+}
+
+//Reads every remaining packet of replay, recorded with protocol, and returns the history of its entities.
+func EntityHistoryOf(replay *replayReader.Replay, protocol int) (*EntityHistory, error) {
+	bus := events.NewBus(protocol)
+	history := NewEntityHistory()
+	history.Attach(bus)
+	err := bus.Run(replay)
+	return history, err
+}
@@ -0,0 +1,194 @@
+package tracking
+
+import (
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/item"
+)
+
+//Slots of the inventory window of the player: the crafting result and grid, the armor, the main inventory and the hotbar.
+//The offhand comes after the hotbar since 1.9.
+const (
+	inventoryMain   = 9
+	inventoryHotbar = 36
+	//Slots of the player inventory shown under a container: the main inventory and the hotbar
+	inventoryShown = 36
+)
+
+//Window is a container the player had open from Opened until Closed, with its Slots as they were last.
+//Closed is -1 if it wasn't closed by the server, which is how the replay knows about it, see events.WindowCloseEvent.
+//Type, TypeID, Title and EntityID are the ones of events.WindowOpenEvent. Size is the number of slots of the container,
+//the slots after them are the inventory of the player. It is -1 until the server tells it.
+type Window struct {
+	ID       int
+	Type     string
+	TypeID   int
+	Title    string
+	EntityID int
+	Size     int
+	Slots    []item.Slot
+	Opened   int
+	Closed   int
+}
+
+//Inventory is the state of the inventory of the player. Slots are the slots of its inventory window,
+//Held is the slot of the hotbar it holds and Cursor is the item it moves with the mouse.
+//Container is the window the player has open, or nil.
+type Inventory struct {
+	Slots     []item.Slot
+	Cursor    item.Slot
+	Held      int
+	Container *Window
+}
+
+//Returns the slots of the hotbar.
+func (inv *Inventory) Hotbar() []item.Slot {
+	if len(inv.Slots) < inventoryHotbar+9 {
+		return nil
+	}
+	return inv.Slots[inventoryHotbar : inventoryHotbar+9]
+}
+
+//Returns the item the player holds in its main hand.
+func (inv *Inventory) HeldItem() item.Slot {
+	hotbar := inv.Hotbar()
+	if inv.Held < 0 || inv.Held >= len(hotbar) {
+		return item.Slot{}
+	}
+	return hotbar[inv.Held]
+}
+
+func (inv *Inventory) open(event events.WindowOpenEvent) {
+	inv.close(event.Time)
+	size := -1
+	if event.Slots > 0 {
+		size = event.Slots
+	}
+	inv.Container = &Window{
+		ID:       event.WindowID,
+		Type:     event.Type,
+		TypeID:   event.TypeID,
+		Title:    event.Title,
+		EntityID: event.EntityID,
+		Size:     size,
+		Opened:   event.Time,
+		Closed:   -1,
+	}
+}
+
+func (inv *Inventory) close(time int) {
+	if inv.Container != nil {
+		inv.Container.Closed = time
+		inv.Container = nil
+	}
+}
+
+func (inv *Inventory) items(event events.WindowItemsEvent) {
+	slots := append([]item.Slot(nil), event.Slots...)
+	if event.Carried != nil {
+		inv.Cursor = *event.Carried
+	}
+	if event.WindowID == 0 {
+		inv.Slots = slots
+		return
+	}
+	window := inv.Container
+	if window == nil || window.ID != event.WindowID {
+		return
+	}
+	window.Slots = slots
+	if window.Size < 0 && len(slots) >= inventoryShown {
+		window.Size = len(slots) - inventoryShown
+	}
+	for i := window.Size; i >= 0 && i < len(slots); i++ {
+		inv.setInventory(i-window.Size+inventoryMain, slots[i])
+	}
+}
+
+func (inv *Inventory) slot(event events.SetSlotEvent) {
+	switch {
+	case event.WindowID == -1 && event.Slot == -1:
+		inv.Cursor = event.Item
+	case event.WindowID == 0 || event.WindowID == -2:
+		inv.setInventory(event.Slot, event.Item)
+	case inv.Container != nil && inv.Container.ID == event.WindowID:
+		window := inv.Container
+		if event.Slot < 0 {
+			return
+		}
+		for len(window.Slots) <= event.Slot {
+			window.Slots = append(window.Slots, item.Slot{})
+		}
+		window.Slots[event.Slot] = event.Item
+		if window.Size >= 0 && event.Slot >= window.Size {
+			inv.setInventory(event.Slot-window.Size+inventoryMain, event.Item)
+		}
+	}
+}
+
+func (inv *Inventory) setInventory(slot int, stack item.Slot) {
+	if slot < 0 {
+		return
+	}
+	for len(inv.Slots) <= slot {
+		inv.Slots = append(inv.Slots, item.Slot{})
+	}
+	inv.Slots[slot] = stack
+}
+
+//InventoryTracker keeps the inventory of the recording player, the containers it opened, and the changes that led to them,
+//so the inventory of any earlier time can be rebuilt.
+type InventoryTracker struct {
+	Inventory *Inventory
+	Windows   []*Window
+	changes   []inventoryChange
+}
+
+type inventoryChange struct {
+	time  int
+	apply func(inv *Inventory)
+}
+
+//Returns an InventoryTracker with an empty Inventory.
+func NewInventoryTracker() *InventoryTracker {
+	return &InventoryTracker{Inventory: &Inventory{}}
+}
+
+func (t *InventoryTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.WindowOpenEvent) {
+		t.change(event.Time, func(inv *Inventory) { inv.open(event) })
+		t.Windows = append(t.Windows, t.Inventory.Container)
+	})
+	events.Subscribe(bus, func(event events.WindowCloseEvent) {
+		t.change(event.Time, func(inv *Inventory) {
+			if inv.Container != nil && inv.Container.ID == event.WindowID {
+				inv.close(event.Time)
+			}
+		})
+	})
+	events.Subscribe(bus, func(event events.WindowItemsEvent) {
+		t.change(event.Time, func(inv *Inventory) { inv.items(event) })
+	})
+	events.Subscribe(bus, func(event events.SetSlotEvent) {
+		t.change(event.Time, func(inv *Inventory) { inv.slot(event) })
+	})
+	events.Subscribe(bus, func(event events.HeldItemEvent) {
+		t.change(event.Time, func(inv *Inventory) { inv.Held = event.Slot })
+	})
+}
+
+func (t *InventoryTracker) change(time int, apply func(inv *Inventory)) {
+	apply(t.Inventory)
+	t.changes = append(t.changes, inventoryChange{time: time, apply: apply})
+}
+
+//Returns the inventory as it was at time, after the changes of that time.
+func (t *InventoryTracker) At(time int) *Inventory {
+	inventory := &Inventory{}
+	for _, change := range t.changes {
+		if change.time > time {
+			break
+		}
+		change.apply(inventory)
+	}
+	return inventory
+}
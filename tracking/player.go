@@ -0,0 +1,123 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//Pose is where the recording player was at Time, in Dimension, and where it was looking. Yaw and Pitch are in degrees.
+type Pose struct {
+	Time       int
+	Dimension  string
+	X, Y, Z    float64
+	Yaw, Pitch float64
+}
+
+//Timeline is a list of Poses ordered by Time. A pose holds until the next one.
+type Timeline []Pose
+
+//Returns the pose at time, or false if time is before the first one.
+func (timeline Timeline) At(time int) (Pose, bool) {
+	i := sort.Search(len(timeline), func(i int) bool {
+		return timeline[i].Time > time
+	})
+	if i == 0 {
+		return Pose{}, false
+	}
+	return timeline[i-1], true
+}
+
+//Returns the positions of the timeline, for exporting and rendering.
+func (timeline Timeline) Path() Path {
+	path := make(Path, len(timeline))
+	for i, pose := range timeline {
+		path[i] = PathPoint{Time: pose.Time, Dimension: pose.Dimension, X: pose.X, Y: pose.Y, Z: pose.Z}
+	}
+	return path
+}
+
+//PlayerTracker records the Timeline of the recording player.
+//The server places the player with Player Position And Look packets, and the Replay Mod records the movement
+//of the player as movement packets of its entity, whose ID comes from Join Game.
+type PlayerTracker struct {
+	Entity   int
+	Timeline Timeline
+	pose     Pose
+	placed   bool
+}
+
+//Returns an empty PlayerTracker.
+func NewPlayerTracker() *PlayerTracker {
+	return &PlayerTracker{Entity: -1}
+}
+
+func (t *PlayerTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.DimensionEvent) {
+		if !event.Respawn {
+			t.Entity = event.EntityID
+		}
+		t.pose.Dimension = event.Dimension
+	})
+	events.Subscribe(bus, func(event events.PlayerPositionEvent) {
+		//The flags are in the order of the values
+		values := [...]float64{event.X, event.Y, event.Z, event.Yaw, event.Pitch}
+		pose := &t.pose
+		for i, target := range [...]*float64{&pose.X, &pose.Y, &pose.Z, &pose.Yaw, &pose.Pitch} {
+			if event.Flags&(1<<i) != 0 {
+				*target += values[i]
+			} else {
+				*target = values[i]
+			}
+		}
+		t.record(event.Time)
+	})
+	events.Subscribe(bus, func(event events.EntitySpawnEvent) {
+		if event.EntityID == t.Entity {
+			t.pose.X, t.pose.Y, t.pose.Z = event.X, event.Y, event.Z
+			t.pose.Yaw, t.pose.Pitch = event.Yaw, event.Pitch
+			t.record(event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityTeleportEvent) {
+		if event.EntityID == t.Entity {
+			t.pose.X, t.pose.Y, t.pose.Z = event.X, event.Y, event.Z
+			t.pose.Yaw, t.pose.Pitch = event.Yaw, event.Pitch
+			t.record(event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityMoveEvent) {
+		//Movement is relative, so it means nothing until the player was placed
+		if event.EntityID != t.Entity || !t.placed {
+			return
+		}
+		if event.Moved {
+			t.pose.X, t.pose.Y, t.pose.Z = t.pose.X+event.DX, t.pose.Y+event.DY, t.pose.Z+event.DZ
+		}
+		if event.Rotated {
+			t.pose.Yaw, t.pose.Pitch = event.Yaw, event.Pitch
+		}
+		t.record(event.Time)
+	})
+}
+
+func (t *PlayerTracker) record(time int) {
+	t.placed = true
+	t.pose.Time = time
+	//Packets at the same time only leave the last pose
+	if n := len(t.Timeline); n > 0 && t.Timeline[n-1].Time == time {
+		t.Timeline[n-1] = t.pose
+		return
+	}
+	t.Timeline = append(t.Timeline, t.pose)
+}
+
+//Reads every remaining packet of replay, recorded with protocol, and returns the Timeline of the recording player.
+func PlayerTimeline(replay *replayReader.Replay, protocol int) (Timeline, error) {
+	bus := events.NewBus(protocol)
+	player := NewPlayerTracker()
+	player.Attach(bus)
+	err := bus.Run(replay)
+	return player.Timeline, err
+}
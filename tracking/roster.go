@@ -0,0 +1,140 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//PlayerState is what the tab list showed about a player from Time until the next state.
+type PlayerState struct {
+	Time int
+	events.PlayerInfo
+}
+
+//PlayerSession is a player being in the tab list from Joined until Left. Left is -1 if it was still there at the end of the replay.
+//States are ordered by Time, the first one is from the packet that added the player.
+type PlayerSession struct {
+	UUID   replayReader.UUID
+	Joined int
+	Left   int
+	States []PlayerState
+}
+
+//Reports whether the player was in the tab list at time.
+func (s *PlayerSession) OnlineAt(time int) bool {
+	return time >= s.Joined && (s.Left < 0 || time < s.Left)
+}
+
+//Returns what the tab list showed about the player at time, or false if it wasn't in the tab list then.
+func (s *PlayerSession) At(time int) (events.PlayerInfo, bool) {
+	if !s.OnlineAt(time) {
+		return events.PlayerInfo{}, false
+	}
+	i := sort.Search(len(s.States), func(i int) bool {
+		return s.States[i].Time > time
+	})
+	return s.States[i-1].PlayerInfo, true
+}
+
+//RosterTracker keeps the tab list, the players the server tells the client are online, and how it changed over time.
+//Unlisted players, which 1.19.3 and later don't show in the tab list, are kept too, with Listed set to false.
+type RosterTracker struct {
+	Sessions []*PlayerSession
+	//The session of every player that is in the tab list
+	online map[replayReader.UUID]*PlayerSession
+}
+
+//Returns an empty RosterTracker.
+func NewRosterTracker() *RosterTracker {
+	return &RosterTracker{online: make(map[replayReader.UUID]*PlayerSession)}
+}
+
+func (t *RosterTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.PlayerJoinEvent) {
+		for _, player := range event.Players {
+			//Adding a player again replaces it
+			t.leave(player.UUID, event.Time)
+			session := &PlayerSession{UUID: player.UUID, Joined: event.Time, Left: -1}
+			session.States = []PlayerState{{Time: event.Time, PlayerInfo: player}}
+			t.online[player.UUID] = session
+			t.Sessions = append(t.Sessions, session)
+		}
+	})
+	events.Subscribe(bus, func(event events.PlayerUpdateEvent) {
+		for _, player := range event.Players {
+			session, ok := t.online[player.UUID]
+			if !ok {
+				continue
+			}
+			state := session.States[len(session.States)-1]
+			if event.GameMode {
+				state.GameMode = player.GameMode
+			}
+			if event.Listed {
+				state.Listed = player.Listed
+			}
+			if event.Ping {
+				state.Ping = player.Ping
+			}
+			if event.DisplayName {
+				state.DisplayName = player.DisplayName
+			}
+			//Packets at the same time only leave the last state
+			if state.Time == event.Time {
+				session.States[len(session.States)-1] = state
+				continue
+			}
+			state.Time = event.Time
+			session.States = append(session.States, state)
+		}
+	})
+	events.Subscribe(bus, func(event events.PlayerLeaveEvent) {
+		for _, uuid := range event.UUIDs {
+			t.leave(uuid, event.Time)
+		}
+	})
+}
+
+func (t *RosterTracker) leave(uuid replayReader.UUID, time int) {
+	if session, ok := t.online[uuid]; ok {
+		session.Left = time
+		delete(t.online, uuid)
+	}
+}
+
+//Returns what the tab list showed about the player with uuid at time, or false if it wasn't in the tab list then.
+func (t *RosterTracker) PlayerAt(uuid replayReader.UUID, time int) (events.PlayerInfo, bool) {
+	for _, session := range t.Sessions {
+		if session.UUID == uuid {
+			if player, ok := session.At(time); ok {
+				return player, true
+			}
+		}
+	}
+	return events.PlayerInfo{}, false
+}
+
+//Returns the players that were in the tab list at time, ordered by Name.
+func (t *RosterTracker) OnlineAt(time int) []events.PlayerInfo {
+	var players []events.PlayerInfo
+	for _, session := range t.Sessions {
+		if player, ok := session.At(time); ok {
+			players = append(players, player)
+		}
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+	return players
+}
+
+//Reads every remaining packet of replay, recorded with protocol, and returns how its tab list changed.
+func Roster(replay *replayReader.Replay, protocol int) (*RosterTracker, error) {
+	bus := events.NewBus(protocol)
+	roster := NewRosterTracker()
+	roster.Attach(bus)
+	err := bus.Run(replay)
+	return roster, err
+}
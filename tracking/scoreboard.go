@@ -0,0 +1,225 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//Number of lines the sidebar shows at most.
+const sidebarLines = 15
+
+//Objective is a scoreboard objective and the scores of its entries, which are player names or entity UUIDs.
+//DisplayName is a JSON chat component.
+type Objective struct {
+	Name        string
+	DisplayName string
+	Hearts      bool
+	Scores      map[string]int
+}
+
+//Team is a scoreboard team. Members are the player names and entity UUIDs in it.
+//DisplayName, Prefix and Suffix are JSON chat components.
+type Team struct {
+	Name              string
+	DisplayName       string
+	Prefix            string
+	Suffix            string
+	FriendlyFlags     byte
+	NameTagVisibility string
+	CollisionRule     string
+	Color             int
+	Members           map[string]bool
+}
+
+//Score is a line of the sidebar: Entry has the score Value.
+type Score struct {
+	Entry string
+	Value int
+}
+
+//Scoreboard is the state of the scoreboard. Display is the name of the objective shown at every position that shows one,
+//by the positions of events.DisplayObjectiveEvent.
+type Scoreboard struct {
+	Objectives map[string]*Objective
+	Teams      map[string]*Team
+	Display    map[int]string
+	//The team of every entry
+	teamOf map[string]string
+}
+
+//Returns an empty Scoreboard.
+func NewScoreboard() *Scoreboard {
+	return &Scoreboard{
+		Objectives: make(map[string]*Objective),
+		Teams:      make(map[string]*Team),
+		Display:    make(map[int]string),
+		teamOf:     make(map[string]string),
+	}
+}
+
+//Returns the team entry is in, or false if it isn't in one.
+func (s *Scoreboard) TeamOf(entry string) (*Team, bool) {
+	team, ok := s.Teams[s.teamOf[entry]]
+	return team, ok
+}
+
+//Returns the objective shown in the sidebar and its lines, the way the client shows them:
+//the 15 highest scores, highest first, and by name when they are equal. It returns false if the sidebar is hidden.
+func (s *Scoreboard) Sidebar() (*Objective, []Score, bool) {
+	objective, ok := s.Objectives[s.Display[events.DisplaySidebar]]
+	if !ok {
+		return nil, nil, false
+	}
+	scores := make([]Score, 0, len(objective.Scores))
+	for entry, value := range objective.Scores {
+		scores = append(scores, Score{Entry: entry, Value: value})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Value != scores[j].Value {
+			return scores[i].Value > scores[j].Value
+		}
+		return scores[i].Entry < scores[j].Entry
+	})
+	if len(scores) > sidebarLines {
+		scores = scores[:sidebarLines]
+	}
+	return objective, scores, true
+}
+
+func (s *Scoreboard) objective(event events.ObjectiveEvent) {
+	switch event.Mode {
+	case events.ObjectiveCreate:
+		s.Objectives[event.Name] = &Objective{Name: event.Name, DisplayName: event.DisplayName, Hearts: event.Hearts, Scores: make(map[string]int)}
+	case events.ObjectiveRemove:
+		delete(s.Objectives, event.Name)
+	case events.ObjectiveUpdate:
+		if objective, ok := s.Objectives[event.Name]; ok {
+			objective.DisplayName, objective.Hearts = event.DisplayName, event.Hearts
+		}
+	}
+}
+
+func (s *Scoreboard) score(event events.ScoreEvent) {
+	if !event.Remove {
+		if objective, ok := s.Objectives[event.Objective]; ok {
+			objective.Scores[event.Entity] = event.Value
+		}
+		return
+	}
+	for name, objective := range s.Objectives {
+		if event.Objective == "" || event.Objective == name {
+			delete(objective.Scores, event.Entity)
+		}
+	}
+}
+
+func (s *Scoreboard) display(event events.DisplayObjectiveEvent) {
+	if event.Objective == "" {
+		delete(s.Display, event.Position)
+		return
+	}
+	s.Display[event.Position] = event.Objective
+}
+
+func (s *Scoreboard) team(event events.TeamEvent) {
+	team, ok := s.Teams[event.Name]
+	switch event.Mode {
+	case events.TeamCreate:
+		team = &Team{Name: event.Name, Members: make(map[string]bool)}
+		s.Teams[event.Name] = team
+	case events.TeamRemove:
+		if ok {
+			for member := range team.Members {
+				delete(s.teamOf, member)
+			}
+			delete(s.Teams, event.Name)
+		}
+		return
+	}
+	if team == nil {
+		return
+	}
+	if event.Mode == events.TeamCreate || event.Mode == events.TeamUpdate {
+		team.DisplayName, team.Prefix, team.Suffix = event.DisplayName, event.Prefix, event.Suffix
+		team.FriendlyFlags, team.NameTagVisibility, team.CollisionRule = event.FriendlyFlags, event.NameTagVisibility, event.CollisionRule
+		team.Color = event.Color
+	}
+	for _, player := range event.Players {
+		if event.Mode == events.TeamRemovePlayers {
+			delete(team.Members, player)
+			if s.teamOf[player] == team.Name {
+				delete(s.teamOf, player)
+			}
+			continue
+		}
+		//An entry is only in one team at a time
+		if previous, ok := s.TeamOf(player); ok {
+			delete(previous.Members, player)
+		}
+		team.Members[player] = true
+		s.teamOf[player] = team.Name
+	}
+}
+
+//ScoreboardTracker keeps the scoreboard, with its objectives, scores and teams, and the changes that led to it,
+//so the scoreboard of any earlier time can be rebuilt.
+type ScoreboardTracker struct {
+	Scoreboard *Scoreboard
+	changes    []scoreboardChange
+}
+
+type scoreboardChange struct {
+	time  int
+	apply func(s *Scoreboard)
+}
+
+//Returns a ScoreboardTracker with an empty Scoreboard.
+func NewScoreboardTracker() *ScoreboardTracker {
+	return &ScoreboardTracker{Scoreboard: NewScoreboard()}
+}
+
+func (t *ScoreboardTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.ObjectiveEvent) {
+		t.change(event.Time, func(s *Scoreboard) { s.objective(event) })
+	})
+	events.Subscribe(bus, func(event events.ScoreEvent) {
+		t.change(event.Time, func(s *Scoreboard) { s.score(event) })
+	})
+	events.Subscribe(bus, func(event events.DisplayObjectiveEvent) {
+		t.change(event.Time, func(s *Scoreboard) { s.display(event) })
+	})
+	events.Subscribe(bus, func(event events.TeamEvent) {
+		t.change(event.Time, func(s *Scoreboard) { s.team(event) })
+	})
+}
+
+func (t *ScoreboardTracker) change(time int, apply func(s *Scoreboard)) {
+	apply(t.Scoreboard)
+	t.changes = append(t.changes, scoreboardChange{time: time, apply: apply})
+}
+
+//Returns the scoreboard as it was at time, after the changes of that time.
+func (t *ScoreboardTracker) At(time int) *Scoreboard {
+	scoreboard := NewScoreboard()
+	for _, change := range t.changes {
+		if change.time > time {
+			break
+		}
+		change.apply(scoreboard)
+	}
+	return scoreboard
+}
+
+//Reads replay, recorded with protocol, up to time and returns the scoreboard it had at that moment.
+func ScoreboardAt(replay *replayReader.Replay, protocol int, time int) (*Scoreboard, error) {
+	bus := events.NewBus(protocol)
+	tracker := NewScoreboardTracker()
+	tracker.Attach(bus)
+	err := bus.RunUntil(replay, time)
+	if err != nil {
+		return nil, err
+	}
+	return tracker.Scoreboard, nil
+}
@@ -0,0 +1,34 @@
+package tracking
+
+//Sample is the Value of a Series at Time.
+type Sample struct {
+	Time  int
+	Value float64
+}
+
+//Series is a value that changes over the replay, like the level of the recording player.
+//Samples are ordered by Time, and a value holds until the next sample.
+type Series struct {
+	Name    string
+	Samples []Sample
+}
+
+//Returns the value of the series at time, or false if time is before its first sample.
+func (s Series) At(time int) (float64, bool) {
+	value, ok := 0.0, false
+	for _, sample := range s.Samples {
+		if sample.Time > time {
+			break
+		}
+		value, ok = sample.Value, true
+	}
+	return value, ok
+}
+
+//Adds a sample, unless the value didn't change.
+func (s *Series) add(time int, value float64) {
+	if n := len(s.Samples); n > 0 && s.Samples[n-1].Value == value {
+		return
+	}
+	s.Samples = append(s.Samples, Sample{Time: time, Value: value})
+}
@@ -0,0 +1,157 @@
+package tracking
+
+import (
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+)
+
+//TextKind tells where a ScreenText was shown.
+type TextKind int
+
+const (
+	TextTitle TextKind = iota
+	TextSubtitle
+	TextActionBar
+)
+
+func (k TextKind) String() string {
+	switch k {
+	case TextTitle:
+		return "title"
+	case TextSubtitle:
+		return "subtitle"
+	case TextActionBar:
+		return "action bar"
+	}
+	return "unknown"
+}
+
+//ScreenText is a text shown on the screen from Start until End, in replay milliseconds. Text is a JSON chat component.
+type ScreenText struct {
+	Kind  TextKind
+	Start int
+	End   int
+	Text  string
+}
+
+//Parses the JSON chat component of the text.
+func (s ScreenText) Component() (replayReader.ChatComponent, error) {
+	return replayReader.ParseChat(s.Text)
+}
+
+//How long the client shows titles by default, in ticks, and how long it shows action bar texts, in milliseconds.
+const (
+	defaultFadeIn    = 10
+	defaultStay      = 70
+	defaultFadeOut   = 20
+	actionBarShowing = 3000
+)
+
+//TitleTracker keeps the titles, subtitles and action bar texts shown during the replay, with when the client showed them.
+//Like the client, a subtitle is only shown with a title, and titles are shown for their fade in, stay and fade out times.
+type TitleTracker struct {
+	Texts []ScreenText
+	//Position in Texts of what is shown of every kind, or -1
+	shown                 [3]int
+	subtitle              string
+	fadeIn, stay, fadeOut int
+}
+
+//Returns an empty TitleTracker.
+func NewTitleTracker() *TitleTracker {
+	return &TitleTracker{shown: [3]int{-1, -1, -1}, fadeIn: defaultFadeIn, stay: defaultStay, fadeOut: defaultFadeOut}
+}
+
+func (t *TitleTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.TitleEvent) {
+		switch event.Action {
+		case events.TitleText:
+			end := event.Time + t.duration()
+			t.show(TextTitle, event.Text, event.Time, end)
+			t.hide(TextSubtitle, event.Time)
+			if t.subtitle != "" {
+				t.show(TextSubtitle, t.subtitle, event.Time, end)
+			}
+		case events.TitleSubtitle:
+			t.subtitle = event.Text
+			if title, ok := t.showing(TextTitle, event.Time); ok {
+				t.show(TextSubtitle, event.Text, event.Time, title.End)
+			}
+		case events.TitleActionBar:
+			t.show(TextActionBar, event.Text, event.Time, event.Time+actionBarShowing)
+		case events.TitleTimes:
+			t.fadeIn, t.stay, t.fadeOut = event.FadeIn, event.Stay, event.FadeOut
+			//The title that is shown starts over with the new times
+			for _, kind := range []TextKind{TextTitle, TextSubtitle} {
+				if text, ok := t.showing(kind, event.Time); ok {
+					text.End = event.Time + t.duration()
+				}
+			}
+		case events.TitleClear, events.TitleReset:
+			t.hide(TextTitle, event.Time)
+			t.hide(TextSubtitle, event.Time)
+			t.subtitle = ""
+			if event.Action == events.TitleReset {
+				t.fadeIn, t.stay, t.fadeOut = defaultFadeIn, defaultStay, defaultFadeOut
+			}
+		}
+	})
+	events.Subscribe(bus, func(event events.ChatEvent) {
+		if event.Position == 2 {
+			t.show(TextActionBar, event.Message, event.Time, event.Time+actionBarShowing)
+		}
+	})
+}
+
+//Returns how long a title is shown with the current times, in milliseconds.
+func (t *TitleTracker) duration() int {
+	return (t.fadeIn + t.stay + t.fadeOut) * 50
+}
+
+//Returns the text of kind that is shown at time, or false if there is none.
+func (t *TitleTracker) showing(kind TextKind, time int) (*ScreenText, bool) {
+	i := t.shown[kind]
+	if i < 0 || t.Texts[i].End <= time {
+		return nil, false
+	}
+	return &t.Texts[i], true
+}
+
+func (t *TitleTracker) show(kind TextKind, text string, start int, end int) {
+	//Packets at the same time only leave the last text
+	if shown, ok := t.showing(kind, start); ok && shown.Start == start {
+		shown.Text, shown.End = text, end
+		return
+	}
+	t.hide(kind, start)
+	t.shown[kind] = len(t.Texts)
+	t.Texts = append(t.Texts, ScreenText{Kind: kind, Start: start, End: end, Text: text})
+}
+
+//Ends the text of kind that is shown at time.
+func (t *TitleTracker) hide(kind TextKind, time int) {
+	if text, ok := t.showing(kind, time); ok {
+		text.End = time
+	}
+	t.shown[kind] = -1
+}
+
+//Returns the texts that were shown at time.
+func (t *TitleTracker) At(time int) []ScreenText {
+	var texts []ScreenText
+	for _, text := range t.Texts {
+		if time >= text.Start && time < text.End {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+//Reads every remaining packet of replay, recorded with protocol, and returns the texts it showed on the screen, ordered by Start.
+func Titles(replay *replayReader.Replay, protocol int) ([]ScreenText, error) {
+	bus := events.NewBus(protocol)
+	titles := NewTitleTracker()
+	titles.Attach(bus)
+	err := bus.Run(replay)
+	return titles.Texts, err
+}
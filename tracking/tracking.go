@@ -0,0 +1,218 @@
+//Package tracking rebuilds the state of the world from the events of a replay.
+package tracking
+
+import (
+	"github.com/bela333/replayReader"
+	"github.com/bela333/replayReader/events"
+	"github.com/bela333/replayReader/metadata"
+)
+
+//Tracker keeps some part of the state up to date with the events of a Bus.
+type Tracker interface {
+	Attach(bus *events.Bus)
+}
+
+//BlockPos is the position of a block.
+type BlockPos struct {
+	X, Y, Z int
+}
+
+//WorldTracker keeps the blocks of the world in World, from the chunks the server sends and the changes to their blocks.
+//Joining or respawning starts a new World, like the client does. The sections of ChunkEvents are kept without being copied.
+//Dimension is the dimension World is in, like minecraft:the_nether.
+type WorldTracker struct {
+	World     *World
+	Dimension string
+	//Dimension types sent by Join Game, for the height of the world
+	types      map[string]events.DimensionType
+	minSection int
+}
+
+//Returns an empty WorldTracker.
+func NewWorldTracker() *WorldTracker {
+	return &WorldTracker{World: NewWorld()}
+}
+
+func (t *WorldTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.DimensionEvent) {
+		t.World = NewWorld()
+		t.Dimension = event.Dimension
+		if event.DimensionTypes != nil {
+			t.types = event.DimensionTypes
+		}
+		dimensionType, ok := t.types[event.Type]
+		if !ok {
+			dimensionType.MinY = vanillaMinY(event.Type)
+		}
+		t.minSection = dimensionType.MinY >> 4
+	})
+	events.Subscribe(bus, func(event events.ChunkEvent) {
+		t.World.load(event, t.minSection)
+	})
+	events.Subscribe(bus, func(event events.ChunkBulkEvent) {
+		for _, chunk := range event.Chunks {
+			t.World.load(chunk, t.minSection)
+		}
+	})
+	events.Subscribe(bus, func(event events.UnloadChunkEvent) {
+		if chunk, ok := t.World.Chunks[ChunkPos{event.X, event.Z}]; ok {
+			chunk.Loaded = false
+		}
+	})
+	events.Subscribe(bus, func(event events.BlockChangeEvent) {
+		t.World.SetBlock(BlockPos{event.X, event.Y, event.Z}, event.BlockState)
+	})
+	events.Subscribe(bus, func(event events.MultiBlockChangeEvent) {
+		for _, change := range event.Changes {
+			t.World.SetBlock(BlockPos{change.X, change.Y, change.Z}, change.BlockState)
+		}
+	})
+}
+
+//Returns the lowest block Y of the vanilla dimension type called name, for a Join Game without it.
+func vanillaMinY(name string) int {
+	if name == "minecraft:overworld" || name == "minecraft:overworld_caves" {
+		return -64
+	}
+	return 0
+}
+
+//Entity is the state of an entity. SpawnTime is the Time of the packet that spawned it.
+//Vehicle is the ID of the entity it rides, or -1. It is only kept up to date by a MountTracker.
+//Metadata holds the last value of every metadata index the server sent, by index.
+type Entity struct {
+	ID         int
+	UUID       replayReader.UUID
+	Kind       events.EntityKind
+	Type       int
+	X, Y, Z    float64
+	Yaw, Pitch float64
+	SpawnTime  int
+	Vehicle    int
+	Metadata   map[int]metadata.Entry
+}
+
+//EntityTracker keeps the entities that are in the world. Joining or respawning empties it.
+type EntityTracker struct {
+	Entities map[int]*Entity
+}
+
+//Returns an empty EntityTracker.
+func NewEntityTracker() *EntityTracker {
+	return &EntityTracker{Entities: make(map[int]*Entity)}
+}
+
+func (t *EntityTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.EntitySpawnEvent) {
+		t.Entities[event.EntityID] = &Entity{
+			ID:        event.EntityID,
+			UUID:      event.UUID,
+			Kind:      event.Kind,
+			Type:      event.Type,
+			X:         event.X,
+			Y:         event.Y,
+			Z:         event.Z,
+			Yaw:       event.Yaw,
+			Pitch:     event.Pitch,
+			SpawnTime: event.Time,
+			Vehicle:   -1,
+			Metadata:  make(map[int]metadata.Entry),
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityMoveEvent) {
+		entity, ok := t.Entities[event.EntityID]
+		if !ok {
+			return
+		}
+		if event.Moved {
+			entity.X, entity.Y, entity.Z = entity.X+event.DX, entity.Y+event.DY, entity.Z+event.DZ
+		}
+		if event.Rotated {
+			entity.Yaw, entity.Pitch = event.Yaw, event.Pitch
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityTeleportEvent) {
+		entity, ok := t.Entities[event.EntityID]
+		if !ok {
+			return
+		}
+		entity.X, entity.Y, entity.Z = event.X, event.Y, event.Z
+		entity.Yaw, entity.Pitch = event.Yaw, event.Pitch
+	})
+	events.Subscribe(bus, func(event events.EntityMetadataEvent) {
+		entity, ok := t.Entities[event.EntityID]
+		if !ok {
+			return
+		}
+		for _, entry := range event.Entries {
+			entity.Metadata[entry.Index] = entry
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityDestroyEvent) {
+		for _, id := range event.EntityIDs {
+			delete(t.Entities, id)
+		}
+	})
+	//The client starts a new world when it joins or respawns, without the entities of the old one
+	events.Subscribe(bus, func(event events.DimensionEvent) {
+		clear(t.Entities)
+	})
+}
+
+//Snapshot is the state of a replay at Time. Players are the players in the tab list, see RosterTracker.OnlineAt.
+//World holds the blocks of the chunks the client loaded in Dimension since it joined or respawned there, see WorldTracker.
+type Snapshot struct {
+	Time       int
+	Dimension  string
+	World      *World
+	Entities   map[int]Entity
+	Players    []events.PlayerInfo
+	Scoreboard *Scoreboard
+}
+
+//Reads replay, recorded with protocol, up to time and returns the state it was in at that moment.
+func SnapshotAt(replay *replayReader.Replay, protocol int, time int) (*Snapshot, error) {
+	bus := events.NewBus(protocol)
+	world := NewWorldTracker()
+	entities := NewEntityTracker()
+	roster := NewRosterTracker()
+	scoreboard := NewScoreboardTracker()
+	for _, tracker := range []Tracker{world, entities, NewMountTracker(entities), roster, scoreboard} {
+		tracker.Attach(bus)
+	}
+	err := bus.RunUntil(replay, time)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		Time:       time,
+		Dimension:  world.Dimension,
+		World:      world.World,
+		Entities:   make(map[int]Entity, len(entities.Entities)),
+		Players:    roster.OnlineAt(time),
+		Scoreboard: scoreboard.Scoreboard,
+	}
+	for id, entity := range entities.Entities {
+		snapshot.Entities[id] = *entity
+	}
+	return snapshot, nil
+}
+
+//PathPoint is where something was at Time, in Dimension.
+type PathPoint struct {
+	Time      int
+	Dimension string
+	X, Y, Z   float64
+}
+
+//Path is a list of PathPoints ordered by Time.
+type Path []PathPoint
+
+//Splits path into one Path per dimension it goes through.
+func (path Path) ByDimension() map[string]Path {
+	dimensions := make(map[string]Path)
+	for _, point := range path {
+		dimensions[point.Dimension] = append(dimensions[point.Dimension], point)
+	}
+	return dimensions
+}
@@ -0,0 +1,191 @@
+package tracking
+
+import "github.com/bela333/replayReader/events"
+
+//Ride is an entity riding another one from Start to End. End is -1 while the ride goes on.
+//VehicleType is the entity type of the vehicle, or -1 if it wasn't spawned in the replay.
+type Ride struct {
+	Passenger   int
+	Vehicle     int
+	VehicleType int
+	Start       int
+	End         int
+}
+
+//MountTracker keeps who rides what, from Set Passengers packets. It needs an EntityTracker attached before it.
+type MountTracker struct {
+	Entities *EntityTracker
+	Rides    []Ride
+	//Position in Rides of the ongoing ride of every passenger
+	active map[int]int
+}
+
+//Returns a MountTracker keeping the Vehicle of the entities of entities up to date.
+func NewMountTracker(entities *EntityTracker) *MountTracker {
+	return &MountTracker{Entities: entities, active: make(map[int]int)}
+}
+
+func (t *MountTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.PassengersEvent) {
+		if event.Vehicle < 0 {
+			for _, passenger := range event.Passengers {
+				t.end(passenger, event.Time)
+			}
+			return
+		}
+		riding := make(map[int]bool, len(event.Passengers))
+		for _, passenger := range event.Passengers {
+			riding[passenger] = true
+		}
+		for passenger, ride := range t.active {
+			if t.Rides[ride].Vehicle == event.Vehicle && !riding[passenger] {
+				t.end(passenger, event.Time)
+			}
+		}
+		for _, passenger := range event.Passengers {
+			if ride, ok := t.active[passenger]; ok && t.Rides[ride].Vehicle == event.Vehicle {
+				continue
+			}
+			t.start(passenger, event.Vehicle, event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.DimensionEvent) {
+		for passenger := range t.active {
+			t.end(passenger, event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityDestroyEvent) {
+		for _, id := range event.EntityIDs {
+			t.end(id, event.Time)
+			for passenger, ride := range t.active {
+				if t.Rides[ride].Vehicle == id {
+					t.end(passenger, event.Time)
+				}
+			}
+		}
+	})
+}
+
+func (t *MountTracker) start(passenger int, vehicle int, time int) {
+	t.end(passenger, time)
+	vehicleType := -1
+	if entity, ok := t.Entities.Entities[vehicle]; ok {
+		vehicleType = entity.Type
+	}
+	t.active[passenger] = len(t.Rides)
+	t.Rides = append(t.Rides, Ride{Passenger: passenger, Vehicle: vehicle, VehicleType: vehicleType, Start: time, End: -1})
+	if entity, ok := t.Entities.Entities[passenger]; ok {
+		entity.Vehicle = vehicle
+	}
+}
+
+func (t *MountTracker) end(passenger int, time int) {
+	ride, ok := t.active[passenger]
+	if !ok {
+		return
+	}
+	t.Rides[ride].End = time
+	delete(t.active, passenger)
+	if entity, ok := t.Entities.Entities[passenger]; ok {
+		entity.Vehicle = -1
+	}
+}
+
+//Returns the ID of the vehicle entityID rides, or false if it doesn't ride anything.
+func (t *MountTracker) Vehicle(entityID int) (int, bool) {
+	ride, ok := t.active[entityID]
+	if !ok {
+		return -1, false
+	}
+	return t.Rides[ride].Vehicle, true
+}
+
+//Returns where entityID really is. A riding entity doesn't get movement packets of its own,
+//it is where the bottom of its stack of vehicles is.
+func (t *MountTracker) Position(entityID int) (x, y, z float64, ok bool) {
+	//Stacks of vehicles are short, the limit only guards against loops
+	for range 16 {
+		vehicle, riding := t.Vehicle(entityID)
+		if !riding {
+			break
+		}
+		if _, known := t.Entities.Entities[vehicle]; !known {
+			break
+		}
+		entityID = vehicle
+	}
+	entity, ok := t.Entities.Entities[entityID]
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return entity.X, entity.Y, entity.Z, true
+}
+
+//PathTracker records the Path of an entity, usually the recording player, following it onto vehicles.
+//Vehicle Move packets are applied to the vehicle of the entity, since the server only sends them to the player riding it.
+//It needs an EntityTracker and a MountTracker attached before it.
+type PathTracker struct {
+	Entity    int
+	Mounts    *MountTracker
+	Path      Path
+	dimension string
+}
+
+//Returns a PathTracker recording the Path of entityID.
+func NewPathTracker(mounts *MountTracker, entityID int) *PathTracker {
+	return &PathTracker{Entity: entityID, Mounts: mounts}
+}
+
+func (t *PathTracker) Attach(bus *events.Bus) {
+	events.Subscribe(bus, func(event events.DimensionEvent) {
+		t.dimension = event.Dimension
+	})
+	events.Subscribe(bus, func(event events.VehicleMoveEvent) {
+		vehicle, ok := t.Mounts.Vehicle(t.Entity)
+		if !ok {
+			return
+		}
+		if entity, ok := t.Mounts.Entities.Entities[vehicle]; ok {
+			entity.X, entity.Y, entity.Z = event.X, event.Y, event.Z
+			t.record(event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityMoveEvent) {
+		if event.Moved && t.follows(event.EntityID) {
+			t.record(event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.EntityTeleportEvent) {
+		if t.follows(event.EntityID) {
+			t.record(event.Time)
+		}
+	})
+	events.Subscribe(bus, func(event events.EntitySpawnEvent) {
+		if event.EntityID == t.Entity {
+			t.record(event.Time)
+		}
+	})
+}
+
+//Reports whether the movement of entityID moves the tracked entity.
+func (t *PathTracker) follows(entityID int) bool {
+	id := t.Entity
+	for range 16 {
+		if id == entityID {
+			return true
+		}
+		vehicle, ok := t.Mounts.Vehicle(id)
+		if !ok {
+			return false
+		}
+		id = vehicle
+	}
+	return false
+}
+
+func (t *PathTracker) record(time int) {
+	x, y, z, ok := t.Mounts.Position(t.Entity)
+	if ok {
+		t.Path = append(t.Path, PathPoint{Time: time, Dimension: t.dimension, X: x, Y: y, Z: z})
+	}
+}
@@ -0,0 +1,115 @@
+package tracking
+
+import (
+	"sort"
+
+	"github.com/bela333/replayReader/events"
+)
+
+//ChunkPos is the position of a chunk, in block coordinates divided by 16.
+type ChunkPos struct {
+	X, Z int
+}
+
+//Chunk is a column of 16×16 blocks. Sections holds its sections of 16×16×16 blocks by their block Y divided by 16,
+//with their block states at y<<8 | z<<4 | x. Sections that aren't there are air.
+//Loaded is false once the server unloaded the chunk; its blocks are kept, as they were then.
+type Chunk struct {
+	Sections map[int]*[4096]uint16
+	Loaded   bool
+}
+
+//World holds the blocks of the chunks the client loaded.
+type World struct {
+	Chunks map[ChunkPos]*Chunk
+}
+
+//Returns an empty World.
+func NewWorld() *World {
+	return &World{Chunks: make(map[ChunkPos]*Chunk)}
+}
+
+//Returns the block state at pos, or 0, air, if it isn't in a chunk of w.
+func (w *World) Block(pos BlockPos) int {
+	chunk, ok := w.Chunks[ChunkPos{pos.X >> 4, pos.Z >> 4}]
+	if !ok {
+		return 0
+	}
+	section := chunk.Sections[pos.Y>>4]
+	if section == nil {
+		return 0
+	}
+	return int(section[blockIndex(pos)])
+}
+
+//Sets the block state at pos, adding the chunk if w doesn't have it yet. Block states are kept in 16 bits.
+func (w *World) SetBlock(pos BlockPos, state int) {
+	key := ChunkPos{pos.X >> 4, pos.Z >> 4}
+	chunk, ok := w.Chunks[key]
+	if !ok {
+		chunk = &Chunk{Sections: make(map[int]*[4096]uint16), Loaded: true}
+		w.Chunks[key] = chunk
+	}
+	section := chunk.Sections[pos.Y>>4]
+	if section == nil {
+		if state == 0 {
+			return
+		}
+		section = new([4096]uint16)
+		chunk.Sections[pos.Y>>4] = section
+	}
+	section[blockIndex(pos)] = uint16(state)
+}
+
+//Calls fn with every block of w that isn't air, chunk by chunk, from the top of every chunk down.
+func (w *World) Each(fn func(pos BlockPos, state int)) {
+	for key, chunk := range w.Chunks {
+		for _, y := range chunk.sectionsDown() {
+			section := chunk.Sections[y]
+			for i := len(section) - 1; i >= 0; i-- {
+				if section[i] != 0 {
+					fn(BlockPos{key.X<<4 | i&15, y<<4 | i>>8, key.Z<<4 | i>>4&15}, int(section[i]))
+				}
+			}
+		}
+	}
+}
+
+//Returns the Y of the sections of c that aren't nil, from the highest.
+func (c *Chunk) sectionsDown() []int {
+	var ys []int
+	for y, section := range c.Sections {
+		if section != nil {
+			ys = append(ys, y)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ys)))
+	return ys
+}
+
+//Returns the index of pos in its section.
+func blockIndex(pos BlockPos) int {
+	return (pos.Y&15)<<8 | (pos.Z&15)<<4 | pos.X&15
+}
+
+//Stores the chunk of event in w. minSection is the lowest section of the world, added to the Y of Relative sections.
+func (w *World) load(event events.ChunkEvent, minSection int) {
+	key := ChunkPos{event.X, event.Z}
+	chunk, ok := w.Chunks[key]
+	if !ok || event.Full {
+		chunk = &Chunk{Sections: make(map[int]*[4096]uint16)}
+		w.Chunks[key] = chunk
+	}
+	chunk.Loaded = true
+	for _, section := range event.Sections {
+		y := section.Y
+		if event.Relative {
+			y += minSection
+		}
+		if section.Blocks == nil {
+			delete(chunk.Sections, y)
+			continue
+		}
+		chunk.Sections[y] = section.Blocks
+	}
+}
@@ -0,0 +1,55 @@
+package replayReader
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+)
+
+//UUID identifies players and entities. The protocol sends it as two big-endian Longs.
+type UUID [16]byte
+
+//Returned by ParseUUID for strings that aren't UUIDs.
+var ErrInvalidUUID = errors.New("invalid UUID")
+
+//Returns the UUID in its canonical hyphenated form, like 069a79f4-44e9-4726-a5be-fca90e38aaf5.
+func (u UUID) String() string {
+	const digits = "0123456789abcdef"
+	var builder strings.Builder
+	builder.Grow(36)
+	for i, b := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			builder.WriteByte('-')
+		}
+		builder.WriteByte(digits[b>>4])
+		builder.WriteByte(digits[b&15])
+	}
+	return builder.String()
+}
+
+//Parses a UUID in its hyphenated form, or without hyphens like the ones some versions and web APIs use.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, ErrInvalidUUID
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 32 {
+		return u, ErrInvalidUUID
+	}
+	_, err := hex.Decode(u[:], []byte(s))
+	if err != nil {
+		return u, ErrInvalidUUID
+	}
+	return u, nil
+}
+
+//Reads a UUID from the packet. Len: 16 bytes
+func (p *Packet) ReadUUID() (UUID, error) {
+	var u UUID
+	_, err := io.ReadFull(p.Data, u[:])
+	return u, err
+}
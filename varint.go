@@ -0,0 +1,82 @@
+package replayReader
+
+//Reads a Variable-length Integer from the packet with the exact semantics of the protocol. Len: len bytes
+//The value is built as a 32-bit two's complement number, so 5 byte VarInts with the highest bit set are negative,
+//and bits that don't fit into 32 bits are dropped, just like the Java implementation does.
+func (p *Packet) ReadVarInt32() (n int32, len int, err error) {
+	count := 0
+	result := uint32(0)
+	last := byte(128)
+
+	for (last & 128) != 0 {
+
+		if count >= 5 {
+			return int32(result), count, p.varIntError(count)
+		}
+
+		last, err = p.ReaduByte()
+		if err != nil {
+			return int32(result), count, err
+		}
+
+		value := last & 127
+
+		result = result | uint32(value)<<uint(7*count)
+		count++
+	}
+	return int32(result), count, nil
+}
+
+//Reads a Variable-length Long from the packet with the exact semantics of the protocol. Len: len bytes
+//The value is built as a 64-bit two's complement number, bits that don't fit into 64 bits are dropped.
+func (p *Packet) ReadVarLong64() (n int64, len int, err error) {
+	count := 0
+	result := uint64(0)
+	last := byte(128)
+
+	for (last & 128) != 0 {
+
+		if count >= 10 {
+			return int64(result), count, p.varIntError(count)
+		}
+
+		last, err = p.ReaduByte()
+		if err != nil {
+			return int64(result), count, err
+		}
+
+		value := last & 127
+
+		result = result | uint64(value)<<uint(7*count)
+		count++
+	}
+	return int64(result), count, nil
+}
+
+//Reads a ZigZag-encoded Variable-length Integer, where small negative numbers take as few bytes as small positive ones,
+//like Bedrock Edition and some data component formats send them. Len: len bytes
+func (p *Packet) ReadZigZagVarInt() (n int32, len int, err error) {
+	value, len, err := p.ReadVarInt32()
+	u := uint32(value)
+	return int32(u>>1) ^ -int32(u&1), len, err
+}
+
+//Reads a ZigZag-encoded Variable-length Long, see ReadZigZagVarInt. Len: len bytes
+func (p *Packet) ReadZigZagVarLong() (n int64, len int, err error) {
+	value, len, err := p.ReadVarLong64()
+	u := uint64(value)
+	return int64(u>>1) ^ -int64(u&1), len, err
+}
+
+//Reads an unsigned Variable-length Integer, the "unsigned varint" of Bedrock Edition. Len: len bytes
+//It is encoded like a VarInt, with the least significant group of 7 bits first.
+func (p *Packet) ReaduVarInt() (n uint32, len int, err error) {
+	value, len, err := p.ReadVarInt32()
+	return uint32(value), len, err
+}
+
+//Reads an unsigned Variable-length Long, see ReaduVarInt. Len: len bytes
+func (p *Packet) ReaduVarLong() (n uint64, len int, err error) {
+	value, len, err := p.ReadVarLong64()
+	return uint64(value), len, err
+}
@@ -0,0 +1,117 @@
+package replayReader
+
+import (
+	"errors"
+	"fmt"
+)
+
+//VerifyOptions controls the checks done by Verify.
+//MaxTimeJump is the most milliseconds allowed between two packets. Zero means no limit.
+//Duration is the length of the recording in milliseconds, as written in its metadata. Zero skips the check.
+//DurationTolerance is how many milliseconds the last packet may be away from Duration.
+//ValidPacketID reports whether id is allowed as the ID of p. If it is nil, every ID that fits in a byte is.
+type VerifyOptions struct {
+	MaxTimeJump       int
+	Duration          int
+	DurationTolerance int
+	ValidPacketID     func(p *Packet, id int) bool
+}
+
+//DefaultVerifyOptions are the options used by Verify.
+var DefaultVerifyOptions = VerifyOptions{
+	MaxTimeJump:       10 * 60 * 1000,
+	DurationTolerance: 5000,
+}
+
+//Names of the checks done by Verify, as they appear in Problem.Check.
+const (
+	CheckFraming   = "framing"
+	CheckTime      = "time"
+	CheckPacketID  = "packet_id"
+	CheckDuration  = "duration"
+	CheckContainer = "container"
+)
+
+//Problem is a single thing Verify found wrong with a replay.
+//Packet and Offset tell where it is, they are -1 for problems of the whole replay.
+type Problem struct {
+	Check   string `json:"check"`
+	Packet  int    `json:"packet"`
+	Offset  int64  `json:"offset"`
+	Message string `json:"message"`
+}
+
+//VerifyReport is the result of Verify. It can be marshalled to JSON as it is.
+type VerifyReport struct {
+	Packets   int       `json:"packets"`
+	Bytes     int64     `json:"bytes"`
+	FirstTime int       `json:"first_time"`
+	LastTime  int       `json:"last_time"`
+	Problems  []Problem `json:"problems"`
+}
+
+//Reports whether Verify found no problems.
+func (report *VerifyReport) OK() bool {
+	return len(report.Problems) == 0
+}
+
+//Adds a problem to the report.
+func (report *VerifyReport) add(check string, packet int, offset int64, format string, args ...any) {
+	report.Problems = append(report.Problems, Problem{Check: check, Packet: packet, Offset: offset, Message: fmt.Sprintf(format, args...)})
+}
+
+//Reads every remaining packet of replay with DefaultVerifyOptions and reports everything that is wrong with them.
+func Verify(replay *Replay) *VerifyReport {
+	return DefaultVerifyOptions.Verify(replay)
+}
+
+//Reads every remaining packet of replay and reports everything that is wrong with them:
+//broken framing, times that go backwards or jump, packet IDs that can't be read or aren't valid,
+//and a last packet that doesn't match the Duration of the recording.
+func (options VerifyOptions) Verify(replay *Replay) *VerifyReport {
+	report := &VerifyReport{Problems: []Problem{}}
+	validID := options.ValidPacketID
+	if validID == nil {
+		validID = func(p *Packet, id int) bool {
+			return id >= 0 && id < 256
+		}
+	}
+	var p Packet
+	for replay.Next(&p) {
+		if report.Packets == 0 {
+			report.FirstTime = p.Time
+		} else if p.Time < report.LastTime {
+			report.add(CheckTime, p.Index, p.Offset, "time goes backwards from %dms to %dms", report.LastTime, p.Time)
+		} else if options.MaxTimeJump > 0 && p.Time-report.LastTime > options.MaxTimeJump {
+			report.add(CheckTime, p.Index, p.Offset, "time jumps from %dms to %dms", report.LastTime, p.Time)
+		}
+		report.Packets++
+		report.Bytes = replay.offset
+		report.LastTime = p.Time
+
+		if p.Truncated {
+			report.add(CheckFraming, p.Index, p.Offset, "packet is truncated")
+		}
+		id, _, err := p.ReadVarInt()
+		if err != nil {
+			report.add(CheckPacketID, p.Index, p.Offset, "packet ID can't be read: %v", err)
+		} else if !validID(&p, id) {
+			report.add(CheckPacketID, p.Index, p.Offset, "invalid packet ID 0x%02x", id)
+		}
+	}
+	if err := replay.Error(); err != nil {
+		packet, offset := replay.packets, replay.offset
+		var framingErr *FramingError
+		if errors.As(err, &framingErr) {
+			packet, offset = framingErr.Packet, framingErr.Offset
+		}
+		report.add(CheckFraming, packet, offset, "%v", err)
+	}
+	if options.Duration > 0 {
+		difference := options.Duration - report.LastTime
+		if difference < -options.DurationTolerance || difference > options.DurationTolerance {
+			report.add(CheckDuration, -1, -1, "last packet is at %dms, but the duration is %dms", report.LastTime, options.Duration)
+		}
+	}
+	return report
+}
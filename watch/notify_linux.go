@@ -0,0 +1,60 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"syscall"
+)
+
+//Changes of the folder that can mean a recording appeared or was written to
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO |
+	syscall.IN_MOVED_FROM | syscall.IN_DELETE | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+//inotifyNotifier follows a folder with inotify.
+type inotifyNotifier struct {
+	file    *os.File
+	changes chan struct{}
+}
+
+//Returns a notifier of the changes of dir.
+func newNotifier(dir string) (notifier, error) {
+	//The file descriptor is non-blocking so the runtime poller reads it, and Close stops the read
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	_, err = syscall.InotifyAddWatch(fd, dir, inotifyMask)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	n := &inotifyNotifier{file: os.NewFile(uintptr(fd), "inotify"), changes: make(chan struct{}, 1)}
+	go n.read()
+	return n, nil
+}
+
+//Reads the events until the notifier is closed. Only the fact that something changed is kept,
+//the watcher looks at the folder again to know what.
+func (n *inotifyNotifier) read() {
+	defer close(n.changes)
+	buffer := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		_, err := n.file.Read(buffer)
+		if err != nil {
+			return
+		}
+		select {
+		case n.changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *inotifyNotifier) Changes() <-chan struct{} {
+	return n.changes
+}
+
+func (n *inotifyNotifier) Close() error {
+	return n.file.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+//Returns an error, so the watcher polls: notifications are only used on Linux.
+func newNotifier(dir string) (notifier, error) {
+	return nil, errors.New("watch: file system notifications aren't supported on this platform")
+}
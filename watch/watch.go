@@ -0,0 +1,190 @@
+//Package watch notices new recordings in a folder, like the replay_recordings folder of the Replay Mod,
+//and hands them to a callback once they are finished.
+//
+//On Linux it is told about changes of the folder with inotify. Elsewhere, or if the notifications can't be set up,
+//it polls the folder every Interval. Either way a recording is only handed over once it stopped changing for Settle.
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bela333/replayReader"
+)
+
+//Watcher calls OnReplay with the path of every .mcpr file that appears in Dir, once it is finished.
+//A file is finished when its size and modification time haven't changed for Settle.
+//The Replay Mod writes to .tmp files while recording and saving, so those are ignored.
+//Interval is how often Dir is polled when there are no notifications. With them, Dir is still looked at
+//every Interval, in case a notification was lost.
+type Watcher struct {
+	Dir      string
+	Interval time.Duration
+	Settle   time.Duration
+	//If IncludeExisting is true, files that are already in Dir when Run starts are handed to OnReplay too.
+	IncludeExisting bool
+	OnReplay        func(path string) error
+	//OnError is called with errors of OnReplay and of reading Dir. If it is nil, they stop Run.
+	OnError func(err error)
+	//Clock tells the time and wakes the Watcher up. If it is nil, the wall clock is used.
+	Clock replayReader.Clock
+
+	files map[string]*fileState
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+	handled bool
+}
+
+//Returns a Watcher of dir that polls every second without notifications, and considers files finished
+//after 5 seconds without changes.
+func NewWatcher(dir string, onReplay func(path string) error) *Watcher {
+	return &Watcher{
+		Dir:      dir,
+		Interval: time.Second,
+		Settle:   5 * time.Second,
+		OnReplay: onReplay,
+		Clock:    replayReader.RealClock,
+	}
+}
+
+//Reports whether name is a finished recording of the Replay Mod.
+func isRecording(name string) bool {
+	return strings.HasSuffix(name, ".mcpr") && !strings.HasSuffix(name, ".tmp")
+}
+
+//notifier tells when the folder changed. Changes is closed if the notifications stop.
+type notifier interface {
+	Changes() <-chan struct{}
+	Close() error
+}
+
+//Watches Dir until ctx is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	w.files = make(map[string]*fileState)
+	//Without notifications changes stays nil, and only the ticker wakes the loop
+	var changes <-chan struct{}
+	if n, err := newNotifier(w.Dir); err == nil {
+		defer n.Close()
+		changes = n.Changes()
+	}
+	clock := w.Clock
+	if clock == nil {
+		clock = replayReader.RealClock
+	}
+	if !w.IncludeExisting {
+		err := w.poll(clock.Now(), true)
+		if err != nil {
+			return err
+		}
+	}
+	//Timers fire once, so the ticker is a new timer every Interval
+	ticker := clock.NewTimer(w.Interval)
+	defer func() { ticker.Stop() }()
+	var settled replayReader.Timer
+	defer func() {
+		if settled != nil {
+			settled.Stop()
+		}
+	}()
+	for {
+		now := clock.Now()
+		err := w.poll(now, false)
+		if err != nil {
+			return err
+		}
+		//Files finish Settle after their last change, which no notification tells
+		if settled != nil {
+			settled.Stop()
+			settled = nil
+		}
+		var settledC <-chan time.Time
+		if wait, ok := w.nextSettle(now); ok {
+			settled = clock.NewTimer(wait)
+			settledC = settled.C()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-changes:
+			if !ok {
+				changes = nil
+			}
+		case <-settledC:
+		case <-ticker.C():
+			ticker = clock.NewTimer(w.Interval)
+		}
+	}
+}
+
+//Returns how long until the next file that is being written is finished, or false if there is none.
+func (w *Watcher) nextSettle(now time.Time) (time.Duration, bool) {
+	var next time.Duration
+	found := false
+	for _, state := range w.files {
+		if state.handled {
+			continue
+		}
+		wait := max(state.since.Add(w.Settle).Sub(now), 0)
+		if !found || wait < next {
+			next, found = wait, true
+		}
+	}
+	return next, found
+}
+
+//Looks at every file in Dir once. If skip is true, the files are only remembered as handled.
+func (w *Watcher) poll(now time.Time, skip bool) error {
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		return w.fail(err)
+	}
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !isRecording(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(w.Dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		seen[path] = true
+		state, ok := w.files[path]
+		if !ok || state.size != info.Size() || !state.modTime.Equal(info.ModTime()) {
+			w.files[path] = &fileState{size: info.Size(), modTime: info.ModTime(), since: now, handled: skip}
+			continue
+		}
+		if state.handled || now.Sub(state.since) < w.Settle {
+			continue
+		}
+		state.handled = true
+		err = w.OnReplay(path)
+		if err != nil {
+			if err := w.fail(err); err != nil {
+				return err
+			}
+		}
+	}
+	for path := range w.files {
+		if !seen[path] {
+			delete(w.files, path)
+		}
+	}
+	return nil
+}
+
+//Passes err to OnError, or returns it if there is no OnError.
+func (w *Watcher) fail(err error) error {
+	if w.OnError == nil {
+		return err
+	}
+	w.OnError(err)
+	return nil
+}
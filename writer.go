@@ -0,0 +1,65 @@
+package replayReader
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+//Returned by WritePacket for a time that can't be written to a replay file.
+var ErrInvalidTime = errors.New("invalid packet time")
+
+//ReplayWriter writes packets to a replay file in the framing Replay reads: time, length, data, big-endian.
+//Writes are buffered, so Flush or Close has to be called once every packet was written.
+type ReplayWriter struct {
+	writer *bufio.Writer
+	closer io.Closer
+	header [headerLen]byte
+}
+
+//Returns a ReplayWriter writing to w. If w is an io.Closer, Close closes it.
+func NewReplayWriter(w io.Writer) *ReplayWriter {
+	writer := &ReplayWriter{writer: bufio.NewWriter(w)}
+	writer.closer, _ = w.(io.Closer)
+	return writer
+}
+
+//Writes a packet with time in milliseconds and data, starting with its packet ID.
+func (w *ReplayWriter) WritePacket(time int, data []byte) error {
+	if time < 0 || uint64(time) > 0xFFFFFFFF {
+		return ErrInvalidTime
+	}
+	if uint64(len(data)) > 0xFFFFFFFF {
+		return ErrInvalidLength
+	}
+	binary.BigEndian.PutUint32(w.header[:], uint32(time))
+	binary.BigEndian.PutUint32(w.header[4:], uint32(len(data)))
+	_, err := w.writer.Write(w.header[:])
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(data)
+	return err
+}
+
+//Writes packet p with its Time and all of its data, no matter how much of it was read.
+func (w *ReplayWriter) WriteFrom(p *Packet) error {
+	return w.WritePacket(p.Time, p.Bytes())
+}
+
+//Writes the buffered packets to the underlying writer.
+func (w *ReplayWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+//Flushes the buffered packets and closes the underlying writer if it is an io.Closer.
+func (w *ReplayWriter) Close() error {
+	err := w.Flush()
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}